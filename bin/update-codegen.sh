@@ -20,7 +20,7 @@ git clone --depth 1 --branch "$GEN_VER" https://github.com/kubernetes/code-gener
 rm -rf "${SCRIPT_ROOT}/controller/gen/client/clientset/*"
 rm -rf "${SCRIPT_ROOT}/controller/gen/client/listeners/*"
 rm -rf "${SCRIPT_ROOT}/controller/gen/client/informers/*"
-crds=(serviceprofile server serverauthorization link policy policy externalworkload)
+crds=(serviceprofile server serverauthorization link policy policy externalworkload proxyconfig)
 for crd in "${crds[@]}"
 do
   rm -f "${SCRIPT_ROOT}"/controller/gen/apis/"${crd}"/*/zz_generated.deepcopy.go
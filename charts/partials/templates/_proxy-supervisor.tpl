@@ -0,0 +1,38 @@
+{{- define "partials.proxy-supervisor" -}}
+name: linkerd-proxy-supervisor
+{{- /*
+The supervisor ships in the controller image, so that it's available
+regardless of the proxy version the pod is pinned to.
+*/}}
+image: {{.Values.controllerImage}}:{{.Values.linkerdVersion}}
+imagePullPolicy: {{.Values.imagePullPolicy}}
+command:
+- /proxy-supervisor
+env:
+- name: LINKERD2_PROXY_SUPERVISOR_ADMIN_PORT
+  value: {{.Values.proxy.ports.admin | quote}}
+{{- if .Values.debugContainer }}
+- name: LINKERD2_PROXY_SUPERVISOR_IGNORE
+  value: tshark,dumpcap
+{{- end }}
+resources:
+  limits:
+    memory: 32Mi
+  requests:
+    cpu: 10m
+    memory: 16Mi
+securityContext:
+  allowPrivilegeEscalation: false
+  capabilities:
+    drop:
+    - ALL
+  readOnlyRootFilesystem: true
+  runAsNonRoot: true
+  runAsUser: {{.Values.proxy.uid}}
+{{- if ge (int .Values.proxy.gid) 0 }}
+  runAsGroup: {{.Values.proxy.gid}}
+{{- end }}
+  seccompProfile:
+    type: RuntimeDefault
+terminationMessagePolicy: FallbackToLogsOnError
+{{- end -}}
@@ -32,6 +32,14 @@ type checkOptions struct {
 	cniEnabled         bool
 	output             string
 	cliVersionOverride string
+	fix                bool
+	dryRun             bool
+	yes                bool
+	connectivity       bool
+	connectivityOpts   healthcheck.ConnectivityOptions
+	save               string
+	compare            []string
+	checkConfig        string
 }
 
 func newCheckOptions() *checkOptions {
@@ -45,6 +53,16 @@ func newCheckOptions() *checkOptions {
 		cniEnabled:         false,
 		output:             tableOutput,
 		cliVersionOverride: "",
+		fix:                false,
+		dryRun:             false,
+		yes:                false,
+		connectivity:       false,
+		connectivityOpts: healthcheck.ConnectivityOptions{
+			Namespace: "linkerd-connectivity",
+		},
+		save:        "",
+		compare:     []string{},
+		checkConfig: "",
 	}
 }
 
@@ -57,6 +75,17 @@ func (options *checkOptions) nonConfigFlagSet() *pflag.FlagSet {
 	flags.BoolVar(&options.preInstallOnly, "pre", options.preInstallOnly, "Only run pre-installation checks, to determine if the control plane can be installed")
 	flags.BoolVar(&options.crdsOnly, "crds", options.crdsOnly, "Only run checks which determine if the Linkerd CRDs have been installed")
 	flags.BoolVar(&options.dataPlaneOnly, "proxy", options.dataPlaneOnly, "Only run data-plane checks, to determine if the data plane is healthy")
+	flags.BoolVar(&options.fix, "fix", options.fix, "Apply the known fixes of the failed checks, after confirmation")
+	flags.BoolVar(&options.dryRun, "dry-run", options.dryRun, "With --fix, print the fixes along with their patches, without applying them")
+	flags.BoolVar(&options.yes, "yes", options.yes, "With --fix, apply the fixes without asking for confirmation")
+	flags.BoolVar(&options.connectivity, "connectivity", options.connectivity, "Deploy a probe server and clients to check the mTLS, opaque ports and policy paths of the data plane; the probe is removed afterwards")
+	flags.StringVar(&options.connectivityOpts.Namespace, "connectivity-namespace", options.connectivityOpts.Namespace, "Namespace of the connectivity probe server; it's created, and deleted afterwards, if it doesn't exist")
+	flags.StringVar(&options.connectivityOpts.ClientNamespace, "connectivity-client-namespace", options.connectivityOpts.ClientNamespace, "Namespace of the connectivity probe clients (default: the namespace of the server)")
+	flags.StringVar(&options.connectivityOpts.ServerNode, "connectivity-server-node", options.connectivityOpts.ServerNode, "Node on which to schedule the connectivity probe server (default: any node)")
+	flags.StringVar(&options.connectivityOpts.ClientNode, "connectivity-client-node", options.connectivityOpts.ClientNode, "Node on which to schedule the connectivity probe clients (default: any node)")
+	flags.StringVar(&options.connectivityOpts.Image, "connectivity-image", options.connectivityOpts.Image, "Image of the connectivity probe pods (default: the controller image of the control plane)")
+	flags.StringVar(&options.save, "save", options.save, "Save the check results as JSON to a file, or to a ConfigMap in the control plane namespace with configmap/<name>")
+	flags.StringArrayVar(&options.compare, "compare", options.compare, "Compare the check results with those saved by previous runs (file or configmap/<name>), from the oldest to the most recent one, listing the newly failing, newly passing and flapping checks")
 
 	return flags
 }
@@ -67,8 +96,9 @@ func (options *checkOptions) checkFlagSet() *pflag.FlagSet {
 
 	flags.StringVar(&options.versionOverride, "expected-version", options.versionOverride, "Overrides the version used when checking if Linkerd is running the latest version (mostly for testing)")
 	flags.StringVar(&options.cliVersionOverride, "cli-version-override", "", "Used to override the version of the cli (mostly for testing)")
-	flags.StringVarP(&options.output, "output", "o", options.output, "Output format. One of: table, json, short")
+	flags.StringVarP(&options.output, "output", "o", options.output, "Output format. One of: table, json, short, junit, sarif")
 	flags.DurationVar(&options.wait, "wait", options.wait, "Maximum allowed time for all tests to pass")
+	flags.StringVar(&options.checkConfig, "check-config", options.checkConfig, fmt.Sprintf("Path to a YAML file enabling or disabling categories and checks, overriding their thresholds, and turning warnings into failures; it's passed on to the extension checks (default: $%s)", healthcheck.CheckConfigEnvVar))
 
 	return flags
 }
@@ -83,8 +113,26 @@ func (options *checkOptions) validate() error {
 	if !options.preInstallOnly && options.cniEnabled {
 		return errors.New("--linkerd-cni-enabled can only be used with --pre")
 	}
-	if options.output != tableOutput && options.output != jsonOutput && options.output != shortOutput {
-		return fmt.Errorf("Invalid output type '%s'. Supported output types are: %s, %s, %s", options.output, jsonOutput, tableOutput, shortOutput)
+	if options.output != tableOutput && options.output != jsonOutput && options.output != shortOutput && !healthcheck.IsReportOutput(options.output) {
+		return fmt.Errorf("Invalid output type '%s'. Supported output types are: %s, %s, %s, %s, %s", options.output, jsonOutput, tableOutput, shortOutput, healthcheck.JUnitOutput, healthcheck.SARIFOutput)
+	}
+	if !options.fix && (options.dryRun || options.yes) {
+		return errors.New("--dry-run and --yes can only be used with --fix")
+	}
+	if options.fix && (options.preInstallOnly || options.crdsOnly) {
+		return errors.New("--fix can't be used with --pre or --crds")
+	}
+	if options.fix && options.output != tableOutput && options.output != shortOutput {
+		return fmt.Errorf("--fix is only supported with the %s and %s output types", tableOutput, shortOutput)
+	}
+	if err := validateCheckHistoryOptions(options); err != nil {
+		return err
+	}
+	if options.connectivity && (options.preInstallOnly || options.crdsOnly) {
+		return errors.New("--connectivity can't be used with --pre or --crds")
+	}
+	if options.connectivity && options.connectivityOpts.Namespace == "" {
+		return errors.New("--connectivity-namespace can't be empty")
 	}
 	return nil
 }
@@ -111,7 +159,25 @@ non-zero exit code.`,
   linkerd check --pre --linkerd-namespace test
 
   # Check that the Linkerd data plane proxies in the "app" namespace are up and running
-  linkerd check --proxy --namespace app`,
+  linkerd check --proxy --namespace app
+
+  # Report the results of the pre-installation checks as JUnit XML, e.g. in a CI pipeline
+  linkerd check --pre -o junit > linkerd-check.xml
+
+  # Print the fixes of the failed checks that have a known fix, without applying them
+  linkerd check --fix --dry-run
+
+  # Warn about certificates expiring within 120 days, with a check-config file containing:
+  #   thresholds:
+  #     certExpiryWarningDays: 120
+  linkerd check --check-config check-config.yaml
+
+  # Save the check results, and compare them with those of a later run, e.g. after an upgrade
+  linkerd check --save before.json
+  linkerd check --compare before.json
+
+  # Check the data plane paths between two nodes, with a probe server and clients
+  linkerd check --connectivity --connectivity-server-node node-1 --connectivity-client-node node-2`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return configureAndRunChecks(cmd, stdout, stderr, options)
 		},
@@ -136,6 +202,11 @@ func configureAndRunChecks(cmd *cobra.Command, wout io.Writer, werr io.Writer, o
 		version.Version = options.cliVersionOverride
 	}
 
+	checkConfig, err := loadCheckConfig(options.checkConfig)
+	if err != nil {
+		return err
+	}
+
 	checks := []healthcheck.CategoryID{
 		healthcheck.KubernetesAPIChecks,
 		healthcheck.KubernetesVersionChecks,
@@ -184,6 +255,9 @@ func configureAndRunChecks(cmd *cobra.Command, wout io.Writer, werr io.Writer, o
 		}
 		checks = append(checks, healthcheck.LinkerdCNIPluginChecks)
 		checks = append(checks, healthcheck.LinkerdHAChecks)
+		if options.connectivity {
+			checks = append(checks, healthcheck.LinkerdConnectivityChecks)
+		}
 	}
 
 	hc := healthcheck.NewHealthChecker(checks, &healthcheck.Options{
@@ -202,23 +276,73 @@ func configureAndRunChecks(cmd *cobra.Command, wout io.Writer, werr io.Writer, o
 		InstallManifest:       installManifest,
 		CRDManifest:           crdManifest.String(),
 		ChartValues:           values,
+		Remediate:             options.fix,
+		Connectivity:          options.connectivityOpts,
+		CheckConfig:           checkConfig,
 	})
+	fixes := &fixCollector{Runner: hc}
 
-	success, warning := healthcheck.RunChecks(wout, werr, hc, options.output)
-
-	if !options.preInstallOnly && !options.crdsOnly {
-		extensionSuccess, extensionWarning, err := runExtensionChecks(cmd, wout, werr, options)
+	var previous []*healthcheck.CheckOutput
+	var recorder *healthcheck.Recorder
+	if options.save != "" || len(options.compare) > 0 {
+		recorder = &healthcheck.Recorder{}
+		previous, err = loadPreviousCheckOutputs(cmd.Context(), options.compare)
 		if err != nil {
-			fmt.Fprintf(werr, "Failed to run extensions checks: %s\n", err)
+			fmt.Fprintf(werr, "Failed to load the previous check results: %s\n", err)
 			os.Exit(1)
 		}
+	}
 
-		success = success && extensionSuccess
-		warning = warning || extensionWarning
+	var success, warning bool
+	if healthcheck.IsReportOutput(options.output) {
+		// Reports are a single document, so the results of the extensions
+		// are collected first and rendered along with the core checks
+		runners := healthcheck.Runners{hc}
+		if !options.preInstallOnly && !options.crdsOnly {
+			extensionRunners, err := collectExtensionChecks(cmd, wout)
+			if err != nil {
+				fmt.Fprintf(werr, "Failed to run extensions checks: %s\n", err)
+				os.Exit(1)
+			}
+			runners = append(runners, extensionRunners...)
+		}
+		success, warning = healthcheck.RunChecks(wout, werr, recorder.Record(runners), options.output)
+	} else {
+		success, warning = healthcheck.RunChecks(wout, werr, recorder.Record(fixes), options.output)
+
+		if !options.preInstallOnly && !options.crdsOnly {
+			extensionSuccess, extensionWarning, err := runExtensionChecks(cmd, wout, werr, options, recorder)
+			if err != nil {
+				fmt.Fprintf(werr, "Failed to run extensions checks: %s\n", err)
+				os.Exit(1)
+			}
+
+			success = success && extensionSuccess
+			warning = warning || extensionWarning
+		}
 	}
 
 	healthcheck.PrintChecksResult(wout, options.output, success, warning)
 
+	if len(options.compare) > 0 {
+		printCheckComparison(wout, healthcheck.CompareCheckOutputs(previous, recorder.Output()))
+	}
+
+	if options.save != "" {
+		if err := saveCheckResults(cmd.Context(), options.save, recorder.Output()); err != nil {
+			fmt.Fprintf(werr, "Failed to save the check results: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if options.fix {
+		err := applyFixes(cmd.Context(), hc.KubeAPIClient(), cmd.InOrStdin(), wout, fixes.fixes, options.dryRun, options.yes)
+		if err != nil {
+			fmt.Fprintf(werr, "Failed to apply fixes: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
 	if !success {
 		os.Exit(1)
 	}
@@ -226,15 +350,42 @@ func configureAndRunChecks(cmd *cobra.Command, wout io.Writer, werr io.Writer, o
 	return nil
 }
 
-func runExtensionChecks(cmd *cobra.Command, wout io.Writer, werr io.Writer, opts *checkOptions) (bool, bool, error) {
-	kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup, 0)
+func runExtensionChecks(cmd *cobra.Command, wout io.Writer, werr io.Writer, opts *checkOptions, recorder *healthcheck.Recorder) (bool, bool, error) {
+	extensions, missing, err := findClusterExtensions(cmd)
 	if err != nil {
 		return false, false, err
 	}
 
+	// no extensions to check
+	if len(extensions) == 0 && len(missing) == 0 {
+		return true, false, nil
+	}
+
+	extensionSuccess, extensionWarning := runExtensionsChecks(
+		wout, werr, extensions, missing, utilsexec.New(), getExtensionCheckFlags(cmd.Flags()), opts.output, recorder,
+	)
+	return extensionSuccess, extensionWarning, nil
+}
+
+func collectExtensionChecks(cmd *cobra.Command, wout io.Writer) (healthcheck.Runners, error) {
+	extensions, missing, err := findClusterExtensions(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return collectExtensionsChecks(wout, extensions, missing, utilsexec.New(), getExtensionCheckFlags(cmd.Flags())), nil
+}
+
+// findClusterExtensions returns the extensions to check, along with the
+// missing executables of the extensions installed on the cluster.
+func findClusterExtensions(cmd *cobra.Command) ([]extension, []string, error) {
+	kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	namespaces, err := kubeAPI.GetAllNamespacesWithExtensionLabel(cmd.Context())
 	if err != nil {
-		return false, false, err
+		return nil, nil, err
 	}
 	nsLabels := []string{}
 	for _, ns := range namespaces {
@@ -242,19 +393,23 @@ func runExtensionChecks(cmd *cobra.Command, wout io.Writer, werr io.Writer, opts
 		nsLabels = append(nsLabels, ext)
 	}
 
-	exec := utilsexec.New()
-
-	extensions, missing := findExtensions(os.Getenv("PATH"), filepath.Glob, exec, nsLabels)
+	extensions, missing := findExtensions(os.Getenv("PATH"), filepath.Glob, utilsexec.New(), nsLabels)
+	return extensions, missing, nil
+}
 
-	// no extensions to check
-	if len(extensions) == 0 && len(missing) == 0 {
-		return true, false, nil
+// loadCheckConfig loads the check-config file at path, or set in the
+// environment, and passes it on to the extension checks through the
+// environment.
+func loadCheckConfig(path string) (*healthcheck.CheckConfig, error) {
+	config, err := healthcheck.LoadCheckConfigOrEnv(path)
+	if err != nil || path == "" {
+		return config, err
 	}
-
-	extensionSuccess, extensionWarning := runExtensionsChecks(
-		wout, werr, extensions, missing, exec, getExtensionCheckFlags(cmd.Flags()), opts.output,
-	)
-	return extensionSuccess, extensionWarning, nil
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	return config, os.Setenv(healthcheck.CheckConfigEnvVar, absPath)
 }
 
 func getExtensionCheckFlags(lf *pflag.FlagSet) []string {
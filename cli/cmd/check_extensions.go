@@ -183,14 +183,46 @@ func parseJSONMetadataOutput(data []byte) (healthcheck.ExtensionMetadataOutput,
 // runExtensionsChecks runs checks for each extension name passed into the
 // `extensions` parameter and handles formatting the output for each extension's
 // check. This function also prints check warnings for missing extensions.
+// The results are recorded by recorder, if set.
 func runExtensionsChecks(
-	wout io.Writer, werr io.Writer, extensions []extension, missing []string, utilsexec utilsexec.Interface, flags []string, output string,
+	wout io.Writer, werr io.Writer, extensions []extension, missing []string, utilsexec utilsexec.Interface, flags []string, output string, recorder *healthcheck.Recorder,
 ) (bool, bool) {
+	success := true
+	warning := false
+	checkExtensions(wout, extensions, missing, utilsexec, flags, func(results healthcheck.CheckResults) {
+		extensionSuccess, extensionWarning := healthcheck.RunChecks(wout, werr, recorder.Record(results), output)
+		if !extensionSuccess {
+			success = false
+		}
+		if extensionWarning {
+			warning = true
+		}
+	})
+	return success, warning
+}
+
+// collectExtensionsChecks runs checks for each extension like
+// runExtensionsChecks, but returns their results instead of printing them, so
+// that they can be rendered along with the core checks.
+func collectExtensionsChecks(
+	wout io.Writer, extensions []extension, missing []string, utilsexec utilsexec.Interface, flags []string,
+) healthcheck.Runners {
+	runners := healthcheck.Runners{}
+	checkExtensions(wout, extensions, missing, utilsexec, flags, func(results healthcheck.CheckResults) {
+		runners = append(runners, results)
+	})
+	return runners
+}
+
+// checkExtensions runs the check command of every extension, and passes the
+// results of each one to observe, followed by a warning for every missing
+// extension.
+func checkExtensions(
+	wout io.Writer, extensions []extension, missing []string, utilsexec utilsexec.Interface, flags []string, observe func(healthcheck.CheckResults),
+) {
 	spin := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
 	spin.Writer = wout
 
-	success := true
-	warning := false
 	for _, extension := range extensions {
 		args := append([]string{"check"}, flags...)
 		if extension.builtin != "" {
@@ -215,8 +247,6 @@ func runExtensionsChecks(
 		results, err := parseJSONCheckOutput(stdout.Bytes())
 		spin.Stop()
 		if err != nil {
-			success = false
-
 			command := fmt.Sprintf("%s %s", extension.path, strings.Join(args, " "))
 			if len(stderr.String()) > 0 {
 				err = errors.New(stderr.String())
@@ -236,17 +266,11 @@ func runExtensionsChecks(
 			}
 		}
 
-		extensionSuccess, extensionWarning := healthcheck.RunChecks(wout, werr, results, output)
-		if !extensionSuccess {
-			success = false
-		}
-		if extensionWarning {
-			warning = true
-		}
+		observe(results)
 	}
 
 	for _, m := range missing {
-		results := healthcheck.CheckResults{
+		observe(healthcheck.CheckResults{
 			Results: []healthcheck.CheckResult{
 				{
 					Category:    healthcheck.CategoryID(m),
@@ -256,18 +280,8 @@ func runExtensionsChecks(
 					Warning:     true,
 				},
 			},
-		}
-
-		extensionSuccess, extensionWarning := healthcheck.RunChecks(wout, werr, results, output)
-		if !extensionSuccess {
-			success = false
-		}
-		if extensionWarning {
-			warning = true
-		}
+		})
 	}
-
-	return success, warning
 }
 
 // parseJSONCheckOutput parses the output of a check command run with json
@@ -289,6 +303,7 @@ func parseJSONCheckOutput(data []byte) (healthcheck.CheckResults, error) {
 			}
 			results = append(results, healthcheck.CheckResult{
 				Category:    category.Name,
+				ID:          check.ID,
 				Description: check.Description,
 				Err:         err,
 				HintURL:     check.Hint,
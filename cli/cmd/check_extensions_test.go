@@ -334,7 +334,7 @@ missing2
 			}
 
 			var stdout, stderr bytes.Buffer
-			success, warning := runExtensionsChecks(&stdout, &stderr, tc.extensions, tc.missing, fexec, nil, "")
+			success, warning := runExtensionsChecks(&stdout, &stderr, tc.extensions, tc.missing, fexec, nil, "", nil)
 			if tc.expSuccess != success {
 				t.Errorf("Expected success to be %t, got %t", tc.expSuccess, success)
 			}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+)
+
+// fixCollector is a healthcheck.Runner collecting the fixes of the failed
+// checks it runs.
+type fixCollector struct {
+	healthcheck.Runner
+	fixes []*healthcheck.Fix
+}
+
+// RunChecks implements the healthcheck.Runner interface
+func (c *fixCollector) RunChecks(observer healthcheck.CheckObserver) (bool, bool) {
+	return c.Runner.RunChecks(func(result *healthcheck.CheckResult) {
+		if !result.Retry {
+			c.fixes = append(c.fixes, result.Fixes...)
+		}
+		observer(result)
+	})
+}
+
+// applyFixes prints fixes and, unless dryRun is set, applies them once
+// confirmed through in, or right away if yes is set. The applied fixes are
+// printed along with their patch, as a record of the changes made.
+func applyFixes(ctx context.Context, kubeAPI *k8s.KubernetesAPI, in io.Reader, wout io.Writer, fixes []*healthcheck.Fix, dryRun, yes bool) error {
+	fmt.Fprintln(wout)
+	if len(fixes) == 0 {
+		fmt.Fprintln(wout, "No fixes available for the failed checks")
+		return nil
+	}
+
+	fmt.Fprintln(wout, "fixes")
+	fmt.Fprintln(wout, "-----")
+	for _, fix := range fixes {
+		fmt.Fprintf(wout, "* %s\n", fix.Description)
+		if dryRun {
+			fmt.Fprintf(wout, "    %s: %s\n", fix.Target(), fix.PrintablePatch())
+		}
+	}
+	if dryRun {
+		return nil
+	}
+
+	if !yes {
+		fmt.Fprintf(wout, "\nApply %d fixes? [y/N] ", len(fixes))
+		answer, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if answer := strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Fprintln(wout, "No fixes applied")
+			return nil
+		}
+	}
+
+	fmt.Fprintln(wout)
+	failed := 0
+	for _, fix := range fixes {
+		if err := fix.Apply(ctx, kubeAPI); err != nil {
+			fmt.Fprintf(wout, "%s %s: %s\n", failStatus, fix.Description, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(wout, "%s %s\n    %s: %s\n", okStatus, fix.Description, fix.Target(), fix.PrintablePatch())
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d fixes failed to apply", failed, len(fixes))
+	}
+	fmt.Fprintln(wout, "\nRun 'linkerd check' again to verify the fixes")
+	return nil
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestApplyFixes(t *testing.T) {
+	fixes := []*healthcheck.Fix{{
+		Description: "add the linkerd.io/extension=viz label to namespace linkerd-viz",
+		Resource:    corev1.SchemeGroupVersion.WithResource("namespaces"),
+		Name:        "linkerd-viz",
+		PatchType:   types.MergePatchType,
+		Patch:       []byte(`{"metadata":{"labels":{"linkerd.io/extension":"viz"}}}`),
+	}}
+	newKubeAPI := func(t *testing.T) *k8s.KubernetesAPI {
+		kubeAPI, err := k8s.NewFakeAPI(`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: linkerd-viz
+`)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		return kubeAPI
+	}
+	label := func(t *testing.T, kubeAPI *k8s.KubernetesAPI) string {
+		ns, err := kubeAPI.DynamicClient.Resource(fixes[0].Resource).Get(context.Background(), "linkerd-viz", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		return ns.GetLabels()[k8s.LinkerdExtensionLabel]
+	}
+
+	testCases := []struct {
+		description string
+		fixes       []*healthcheck.Fix
+		input       string
+		dryRun      bool
+		yes         bool
+		expected    string
+		applied     bool
+	}{
+		{
+			description: "no fixes",
+			expected:    "\nNo fixes available for the failed checks\n",
+		},
+		{
+			description: "dry run",
+			fixes:       fixes,
+			dryRun:      true,
+			expected: `
+fixes
+-----
+* add the linkerd.io/extension=viz label to namespace linkerd-viz
+    namespaces linkerd-viz: {"metadata":{"labels":{"linkerd.io/extension":"viz"}}}
+`,
+		},
+		{
+			description: "declined",
+			fixes:       fixes,
+			input:       "n\n",
+			expected: `
+fixes
+-----
+* add the linkerd.io/extension=viz label to namespace linkerd-viz
+
+Apply 1 fixes? [y/N] No fixes applied
+`,
+		},
+		{
+			description: "confirmed",
+			fixes:       fixes,
+			input:       "y\n",
+			expected: `
+fixes
+-----
+* add the linkerd.io/extension=viz label to namespace linkerd-viz
+
+Apply 1 fixes? [y/N] ` + `
+` + okStatus + ` add the linkerd.io/extension=viz label to namespace linkerd-viz
+    namespaces linkerd-viz: {"metadata":{"labels":{"linkerd.io/extension":"viz"}}}
+
+Run 'linkerd check' again to verify the fixes
+`,
+			applied: true,
+		},
+		{
+			description: "without confirmation",
+			fixes:       fixes,
+			yes:         true,
+			expected: `
+fixes
+-----
+* add the linkerd.io/extension=viz label to namespace linkerd-viz
+
+` + okStatus + ` add the linkerd.io/extension=viz label to namespace linkerd-viz
+    namespaces linkerd-viz: {"metadata":{"labels":{"linkerd.io/extension":"viz"}}}
+
+Run 'linkerd check' again to verify the fixes
+`,
+			applied: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.description, func(t *testing.T) {
+			kubeAPI := newKubeAPI(t)
+			var out bytes.Buffer
+			err := applyFixes(context.Background(), kubeAPI, strings.NewReader(tc.input), &out, tc.fixes, tc.dryRun, tc.yes)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("Expected:\n%q\nGot:\n%q", tc.expected, out.String())
+			}
+			applied := label(t, kubeAPI) == "viz"
+			if applied != tc.applied {
+				t.Errorf("Expected the fix to be applied: %t", tc.applied)
+			}
+		})
+	}
+}
+
+func TestCheckFixValidation(t *testing.T) {
+	testCases := []struct {
+		options  func(*checkOptions)
+		expected string
+	}{
+		{
+			options:  func(o *checkOptions) { o.dryRun = true },
+			expected: "--dry-run and --yes can only be used with --fix",
+		},
+		{
+			options:  func(o *checkOptions) { o.fix = true; o.preInstallOnly = true },
+			expected: "--fix can't be used with --pre or --crds",
+		},
+		{
+			options:  func(o *checkOptions) { o.fix = true; o.output = jsonOutput },
+			expected: "--fix is only supported with the table and short output types",
+		},
+	}
+
+	for _, tc := range testCases {
+		options := newCheckOptions()
+		tc.options(options)
+		err := options.validate()
+		if err == nil || err.Error() != tc.expected {
+			t.Errorf("Expected error %q, got %v", tc.expected, err)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// configMapPrefix prefixes the --save and --compare locations referring to
+	// a ConfigMap in the control plane namespace, instead of a file
+	configMapPrefix = "configmap/"
+
+	// checkResultsKey is the key of the check results in their ConfigMap
+	checkResultsKey = "check.json"
+)
+
+// saveCheckResults saves output to location, connecting to the cluster if
+// it's a ConfigMap.
+func saveCheckResults(ctx context.Context, location string, output *healthcheck.CheckOutput) error {
+	var kubeAPI *k8s.KubernetesAPI
+	if usesConfigMap(location) {
+		var err error
+		kubeAPI, err = k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup, 0)
+		if err != nil {
+			return err
+		}
+	}
+	return saveCheckOutput(ctx, kubeAPI, location, output)
+}
+
+// loadPreviousCheckOutputs loads the check results saved to locations,
+// connecting to the cluster if any of them is a ConfigMap.
+func loadPreviousCheckOutputs(ctx context.Context, locations []string) ([]*healthcheck.CheckOutput, error) {
+	var kubeAPI *k8s.KubernetesAPI
+	if usesConfigMap(locations...) {
+		var err error
+		kubeAPI, err = k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	outputs := []*healthcheck.CheckOutput{}
+	for _, location := range locations {
+		output, err := loadCheckOutput(ctx, kubeAPI, location)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
+
+// saveCheckOutput saves output as JSON to location, which is either a file
+// path or configmap/<name>.
+func saveCheckOutput(ctx context.Context, kubeAPI *k8s.KubernetesAPI, location string, output *healthcheck.CheckOutput) error {
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	name, ok := configMapName(location)
+	if !ok {
+		return os.WriteFile(location, append(data, '\n'), 0600)
+	}
+
+	configMaps := kubeAPI.CoreV1().ConfigMaps(controlPlaneNamespace)
+	cm, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   controlPlaneNamespace,
+				Annotations: map[string]string{k8s.CreatedByAnnotation: k8s.CreatedByAnnotationValue()},
+			},
+			Data: map[string]string{checkResultsKey: string(data)},
+		}
+		_, err = configMaps.Create(ctx, cm, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[checkResultsKey] = string(data)
+	_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
+
+// loadCheckOutput loads the check results saved to location, which is either
+// a file path or configmap/<name>.
+func loadCheckOutput(ctx context.Context, kubeAPI *k8s.KubernetesAPI, location string) (*healthcheck.CheckOutput, error) {
+	var data []byte
+	if name, ok := configMapName(location); ok {
+		cm, err := kubeAPI.CoreV1().ConfigMaps(controlPlaneNamespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		saved, ok := cm.Data[checkResultsKey]
+		if !ok {
+			return nil, fmt.Errorf("ConfigMap %s/%s has no %s key", controlPlaneNamespace, name, checkResultsKey)
+		}
+		data = []byte(saved)
+	} else {
+		var err error
+		data, err = os.ReadFile(location)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var output healthcheck.CheckOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("invalid check results in %s: %w", location, err)
+	}
+	if output.Categories == nil {
+		return nil, fmt.Errorf("invalid check results in %s: no categories", location)
+	}
+	return &output, nil
+}
+
+func configMapName(location string) (string, bool) {
+	if !strings.HasPrefix(location, configMapPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(location, configMapPrefix), true
+}
+
+func usesConfigMap(locations ...string) bool {
+	for _, location := range locations {
+		if _, ok := configMapName(location); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// printCheckComparison prints the checks whose result changed compared to the
+// previous runs.
+func printCheckComparison(wout io.Writer, comparison *healthcheck.CheckComparison) {
+	fmt.Fprintln(wout)
+	if comparison.Empty() {
+		fmt.Fprintln(wout, "No check results changed since the previous runs")
+		return
+	}
+
+	fmt.Fprintln(wout, "changes")
+	fmt.Fprintln(wout, "-------")
+	for _, section := range []struct {
+		title   string
+		changes []*healthcheck.CheckChange
+	}{
+		{"newly failing", comparison.NewlyFailing},
+		{"newly passing", comparison.NewlyPassing},
+		{"flapping", comparison.Flapping},
+	} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(wout, "%s:\n", section.title)
+		for _, change := range section.changes {
+			results := make([]string, len(change.Results))
+			for i, result := range change.Results {
+				results[i] = string(result)
+			}
+			fmt.Fprintf(wout, "* [%s] %s (%s)\n", change.Category, change.Description, strings.Join(results, " -> "))
+			if change.Error != "" {
+				fmt.Fprintf(wout, "    %s\n", strings.ReplaceAll(change.Error, "\n", "\n    "))
+			}
+		}
+	}
+}
+
+// validateCheckHistoryOptions validates the --save and --compare flags.
+func validateCheckHistoryOptions(options *checkOptions) error {
+	for _, location := range append([]string{options.save}, options.compare...) {
+		if name, ok := configMapName(location); ok && name == "" {
+			return errors.New("the ConfigMap name is missing in \"configmap/\"")
+		}
+	}
+	if len(options.compare) > 0 && options.output != tableOutput && options.output != shortOutput {
+		return fmt.Errorf("--compare is only supported with the %s and %s output types", tableOutput, shortOutput)
+	}
+	return nil
+}
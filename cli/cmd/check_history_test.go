@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+)
+
+func TestSaveAndLoadCheckOutput(t *testing.T) {
+	output := &healthcheck.CheckOutput{
+		Success: true,
+		Categories: []*healthcheck.CheckCategory{{
+			Name: "linkerd-version",
+			Checks: []*healthcheck.Check{
+				{Description: "can determine the latest version", Result: healthcheck.CheckSuccess},
+				{Description: "cli is up-to-date", Result: healthcheck.CheckWarn, Error: "is running version 1.2.3 but the latest stable version is 1.2.4"},
+			},
+		}},
+	}
+
+	kubeAPI, err := k8s.NewFakeAPI()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, location := range []string{filepath.Join(t.TempDir(), "check.json"), "configmap/linkerd-check-results"} {
+		location := location // pin
+		t.Run(location, func(t *testing.T) {
+			ctx := context.Background()
+			// Saving twice updates the saved results
+			for i := 0; i < 2; i++ {
+				if err := saveCheckOutput(ctx, kubeAPI, location, output); err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+			}
+			loaded, err := loadCheckOutput(ctx, kubeAPI, location)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if diff := deep.Equal(loaded, output); diff != nil {
+				t.Errorf("%+v", diff)
+			}
+		})
+	}
+
+	_, err = loadCheckOutput(context.Background(), kubeAPI, "configmap/missing")
+	if err == nil {
+		t.Error("Expected an error loading a missing ConfigMap")
+	}
+}
+
+func TestPrintCheckComparison(t *testing.T) {
+	comparison := &healthcheck.CheckComparison{
+		NewlyFailing: []*healthcheck.CheckChange{{
+			Category:    "linkerd-version",
+			Description: "cli is up-to-date",
+			Results:     []healthcheck.CheckResultStr{healthcheck.CheckSuccess, healthcheck.CheckWarn},
+			Error:       "is running version 1.2.3 but the latest stable version is 1.2.4",
+		}},
+		Flapping: []*healthcheck.CheckChange{{
+			Category:    "linkerd-data-plane",
+			Description: "data plane pods are ready",
+			Results:     []healthcheck.CheckResultStr{healthcheck.CheckSuccess, healthcheck.CheckErr, healthcheck.CheckSuccess},
+		}},
+	}
+	expected := `
+changes
+-------
+newly failing:
+* [linkerd-version] cli is up-to-date (success -> warning)
+    is running version 1.2.3 but the latest stable version is 1.2.4
+flapping:
+* [linkerd-data-plane] data plane pods are ready (success -> error -> success)
+`
+
+	var out bytes.Buffer
+	printCheckComparison(&out, comparison)
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	printCheckComparison(&out, &healthcheck.CheckComparison{})
+	if expected := "\nNo check results changed since the previous runs\n"; out.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, out.String())
+	}
+}
+
+func TestCheckHistoryValidation(t *testing.T) {
+	testCases := []struct {
+		options  func(*checkOptions)
+		expected string
+	}{
+		{
+			options:  func(o *checkOptions) { o.save = "configmap/" },
+			expected: "the ConfigMap name is missing in \"configmap/\"",
+		},
+		{
+			options:  func(o *checkOptions) { o.compare = []string{"previous.json"}; o.output = jsonOutput },
+			expected: "--compare is only supported with the table and short output types",
+		},
+	}
+
+	for _, tc := range testCases {
+		options := newCheckOptions()
+		tc.options(options)
+		err := options.validate()
+		if err == nil || err.Error() != tc.expected {
+			t.Errorf("Expected error %q, got %v", tc.expected, err)
+		}
+	}
+}
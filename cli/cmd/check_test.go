@@ -78,4 +78,53 @@ func TestCheckStatus(t *testing.T) {
 			t.Fatalf("Expected function to render:\n%s\bbut got:\n%s", expectedContent, output)
 		}
 	})
+
+	for _, tc := range []struct {
+		output     string
+		goldenFile string
+	}{
+		{healthcheck.JUnitOutput, "check_output_junit.golden"},
+		{healthcheck.SARIFOutput, "check_output_sarif.golden"},
+	} {
+		tc := tc // pin
+		t.Run(fmt.Sprintf("Prints expected output in %s", tc.output), func(t *testing.T) {
+			hc := healthcheck.NewHealthChecker(
+				[]healthcheck.CategoryID{},
+				&healthcheck.Options{},
+			)
+			hc.AppendCategories(healthcheck.NewCategory("category", []healthcheck.Checker{
+				*healthcheck.NewChecker("check1").
+					WithCheck(func(context.Context) error {
+						return nil
+					}),
+				*healthcheck.NewChecker("check2").
+					WithHintAnchor("hint-anchor").
+					WithCheck(func(context.Context) error {
+						return fmt.Errorf("This should contain instructions for fail")
+					}),
+			},
+				true,
+			))
+			// the results of an extension, rendered in the same report
+			extension := healthcheck.CheckResults{
+				Results: []healthcheck.CheckResult{
+					{
+						Category:    "linkerd-extension",
+						Description: "extension <check>",
+						HintURL:     "https://linkerd.io/2/checks/#extension",
+						Warning:     true,
+						Err:         fmt.Errorf("This should be a warning & not a failure"),
+					},
+				},
+			}
+
+			output := bytes.NewBufferString("")
+			success, warning := healthcheck.RunChecks(output, stderr, healthcheck.Runners{hc, extension}, tc.output)
+			if success || !warning {
+				t.Fatalf("Expected the checks to fail with warnings, got success=%t warning=%t", success, warning)
+			}
+
+			testDataDiffer.DiffTestdata(t, tc.goldenFile, output.String())
+		})
+	}
 }
@@ -23,6 +23,9 @@ This command provides subcommands to diagnose the functionality of Linkerd.`,
 
   # Get the endpoints for authorities in Linkerd's control-plane itself
   linkerd diagnostics endpoints web.linkerd-viz.svc.cluster.local:8084
+
+  # Collect the diagnostics of Linkerd into a tarball to attach to a bug report
+  linkerd diagnostics bundle
   `,
 	}
 
@@ -31,6 +34,7 @@ This command provides subcommands to diagnose the functionality of Linkerd.`,
 	diagnosticsCmd.AddCommand(newCmdMetrics())
 	diagnosticsCmd.AddCommand(newCmdPolicy())
 	diagnosticsCmd.AddCommand(newCmdDiagnosticsProfile())
+	diagnosticsCmd.AddCommand(newCmdDiagnosticsBundle())
 
 	return diagnosticsCmd
 }
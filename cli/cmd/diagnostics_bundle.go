@@ -0,0 +1,526 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/api/destination"
+	"github.com/linkerd/linkerd2/pkg/config"
+	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/version"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	bundleManifestPath = "manifest.json"
+	redacted           = "<redacted>"
+)
+
+var (
+	// redactedKeyRegex matches the keys of the linkerd-config values holding
+	// secrets
+	redactedKeyRegex = regexp.MustCompile(`(?i)(keypem|privatekey|token|password|secret)$`)
+
+	// redactedTextRegexes match secrets found in free-form text, such as the
+	// logs of the control plane, along with their replacement
+	redactedTextRegexes = []struct {
+		regex       *regexp.Regexp
+		replacement string
+	}{
+		{
+			regex:       regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+			replacement: redacted,
+		},
+		{
+			regex:       regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+			replacement: "${1}" + redacted,
+		},
+		{
+			// JSON Web Tokens, such as ServiceAccount tokens
+			regex:       regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+			replacement: redacted,
+		},
+	}
+)
+
+type (
+	diagBundleOptions struct {
+		outputFile  string
+		since       time.Duration
+		wait        time.Duration
+		namespaces  []string
+		authorities []string
+	}
+
+	// bundleManifest describes the files of a bundle, and the failures to
+	// collect some of them
+	bundleManifest struct {
+		CreatedAt             time.Time    `json:"createdAt"`
+		CLIVersion            string       `json:"cliVersion"`
+		ControlPlaneNamespace string       `json:"controlPlaneNamespace"`
+		LogsSince             string       `json:"logsSince"`
+		Files                 []bundleFile `json:"files"`
+	}
+
+	bundleFile struct {
+		Path        string `json:"path"`
+		Description string `json:"description"`
+		Size        int    `json:"size,omitempty"`
+		Error       string `json:"error,omitempty"`
+	}
+
+	// bundle writes the collected files into a tarball, and records them in
+	// its manifest
+	bundle struct {
+		tw       *tar.Writer
+		manifest bundleManifest
+	}
+
+	// bundleCollector collects some diagnostics into a bundle. It returns an
+	// error only if the bundle can't be written.
+	bundleCollector func(ctx context.Context, k8sAPI *k8s.KubernetesAPI, b *bundle, options *diagBundleOptions) error
+)
+
+func newDiagBundleOptions() *diagBundleOptions {
+	return &diagBundleOptions{
+		outputFile: fmt.Sprintf("linkerd-diagnostics-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z")),
+		since:      time.Hour,
+		wait:       30 * time.Second,
+	}
+}
+
+func (o *diagBundleOptions) validate() error {
+	if o.outputFile == "" {
+		return errors.New("--output-file can't be empty")
+	}
+	if o.since <= 0 {
+		return errors.New("--since must be positive")
+	}
+	if o.wait <= 0 {
+		return errors.New("--wait must be positive")
+	}
+	return nil
+}
+
+func newCmdDiagnosticsBundle() *cobra.Command {
+	options := newDiagBundleOptions()
+
+	example := `  # Collect the diagnostics of the control plane into a tarball
+  linkerd diagnostics bundle
+
+  # Also collect the proxy metrics of the emojivoto namespace, and the logs of the last 10 minutes
+  linkerd diagnostics bundle --namespace linkerd --namespace emojivoto --since 10m -f bundle.tar.gz`
+
+	cmd := &cobra.Command{
+		Use:   "bundle [flags]",
+		Short: "Collect Linkerd diagnostics into a tarball for support",
+		Long: `Collect Linkerd diagnostics into a tarball for support.
+
+This command gathers the results of 'linkerd check', the logs and metrics of the
+control plane, the proxy metrics of the given namespaces, the linkerd-config
+values, the versions of the Linkerd CRDs, the statuses of the multicluster Links
+and the endpoints of the control plane services into a single tarball.
+
+Secrets and tokens are redacted, and the proxy metrics labels identifying
+workloads are obfuscated. The manifest.json file of the tarball describes each
+collected file, along with the errors met collecting them.`,
+		Example: example,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.validate(); err != nil {
+				return err
+			}
+
+			k8sAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup, 0)
+			if err != nil {
+				return err
+			}
+
+			file, err := os.Create(options.outputFile)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			manifest, err := writeBundle(cmd.Context(), k8sAPI, file, options, bundleCollectors())
+			if err != nil {
+				return fmt.Errorf("failed to write %s: %w", options.outputFile, err)
+			}
+
+			for _, f := range manifest.Files {
+				if f.Error != "" {
+					fmt.Fprintf(os.Stderr, "Failed to collect %s: %s\n", f.Path, f.Error)
+				}
+			}
+			fmt.Printf("Diagnostics written to %s\n", options.outputFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.outputFile, "output-file", "f", options.outputFile, "Path of the tarball to write")
+	cmd.Flags().DurationVar(&options.since, "since", options.since, "Collect the logs more recent than this duration")
+	cmd.Flags().DurationVar(&options.wait, "wait", options.wait, "Time allowed for each collection")
+	cmd.Flags().StringSliceVarP(&options.namespaces, "namespace", "n", options.namespaces, "Namespaces to collect proxy metrics from (default: the control plane namespace)")
+	cmd.Flags().StringSliceVar(&options.authorities, "endpoints", options.authorities, "Authorities to dump the endpoints of (default: the control plane services)")
+
+	return cmd
+}
+
+// bundleCollectors returns the collectors of the diagnostics of a bundle.
+func bundleCollectors() []bundleCollector {
+	return []bundleCollector{
+		collectCheckResults,
+		collectLinkerdConfig,
+		collectCRDs,
+		collectLinks,
+		collectControlPlaneLogs,
+		collectControllerMetrics,
+		collectProxyMetrics,
+		collectEndpoints,
+	}
+}
+
+// writeBundle runs the collectors, each within options.wait, and writes the
+// files they collect as a gzipped tarball into w, followed by the manifest.
+func writeBundle(ctx context.Context, k8sAPI *k8s.KubernetesAPI, w io.Writer, options *diagBundleOptions, collectors []bundleCollector) (*bundleManifest, error) {
+	gw := gzip.NewWriter(w)
+	b := &bundle{
+		tw: tar.NewWriter(gw),
+		manifest: bundleManifest{
+			CreatedAt:             time.Now().UTC(),
+			CLIVersion:            version.Version,
+			ControlPlaneNamespace: controlPlaneNamespace,
+			LogsSince:             options.since.String(),
+		},
+	}
+
+	for _, collect := range collectors {
+		collectCtx, cancel := context.WithTimeout(ctx, options.wait)
+		err := collect(collectCtx, k8sAPI, b, options)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	manifest, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := b.write(bundleManifestPath, manifest); err != nil {
+		return nil, err
+	}
+	if err := b.tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return &b.manifest, nil
+}
+
+// add records the file at path in the manifest, along with the error met
+// collecting it, and writes data into the bundle unless err is set. Only the
+// errors writing the bundle are returned.
+func (b *bundle) add(path, description string, data []byte, err error) error {
+	file := bundleFile{Path: path, Description: description}
+	if err != nil {
+		file.Error = err.Error()
+		b.manifest.Files = append(b.manifest.Files, file)
+		return nil
+	}
+	file.Size = len(data)
+	b.manifest.Files = append(b.manifest.Files, file)
+	return b.write(path, data)
+}
+
+func (b *bundle) write(path string, data []byte) error {
+	header := &tar.Header{
+		Name:    path,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: b.manifest.CreatedAt,
+	}
+	if err := b.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := b.tw.Write(data)
+	return err
+}
+
+func collectCheckResults(ctx context.Context, _ *k8s.KubernetesAPI, b *bundle, options *diagBundleOptions) error {
+	checks := []healthcheck.CategoryID{
+		healthcheck.KubernetesAPIChecks,
+		healthcheck.KubernetesVersionChecks,
+		healthcheck.LinkerdVersionChecks,
+		healthcheck.LinkerdConfigChecks,
+		healthcheck.LinkerdControlPlaneExistenceChecks,
+		healthcheck.LinkerdIdentity,
+		healthcheck.LinkerdWebhooksAndAPISvcTLS,
+		healthcheck.LinkerdControlPlaneProxyChecks,
+		healthcheck.LinkerdControlPlaneVersionChecks,
+		healthcheck.LinkerdCNIPluginChecks,
+		healthcheck.LinkerdHAChecks,
+	}
+	deadline, _ := ctx.Deadline()
+	hc := healthcheck.NewHealthChecker(checks, &healthcheck.Options{
+		ControlPlaneNamespace: controlPlaneNamespace,
+		KubeConfig:            kubeconfigPath,
+		KubeContext:           kubeContext,
+		Impersonate:           impersonate,
+		ImpersonateGroup:      impersonateGroup,
+		APIAddr:               apiAddr,
+		RetryDeadline:         deadline,
+	})
+
+	recorder := &healthcheck.Recorder{}
+	recorder.Record(hc).RunChecks(func(*healthcheck.CheckResult) {})
+	data, err := json.MarshalIndent(recorder.Output(), "", "  ")
+	if err == nil {
+		data = redactText(data)
+	}
+	return b.add("check.json", "results of 'linkerd check'", data, err)
+}
+
+func collectLinkerdConfig(ctx context.Context, k8sAPI *k8s.KubernetesAPI, b *bundle, _ *diagBundleOptions) error {
+	data, err := linkerdConfigValues(ctx, k8sAPI)
+	return b.add("linkerd-config.yaml", "values of the linkerd-config ConfigMap, with its secrets redacted", data, err)
+}
+
+// linkerdConfigValues returns the values of the linkerd-config ConfigMap, with
+// the values of the keys holding secrets redacted.
+func linkerdConfigValues(ctx context.Context, k8sAPI *k8s.KubernetesAPI) ([]byte, error) {
+	cm, err := config.FetchLinkerdConfigMap(ctx, k8sAPI, controlPlaneNamespace)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal([]byte(cm.Data["values"]), &values); err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(redactValues(values))
+	if err != nil {
+		return nil, err
+	}
+	return redactText(data), nil
+}
+
+// redactValues replaces the values of the keys holding secrets, at any depth.
+func redactValues(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if _, isMap := child.(map[string]interface{}); !isMap && child != nil && redactedKeyRegex.MatchString(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValues(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValues(child)
+		}
+	}
+	return value
+}
+
+// redactText replaces the private keys and tokens found in data.
+func redactText(data []byte) []byte {
+	for _, r := range redactedTextRegexes {
+		data = r.regex.ReplaceAll(data, []byte(r.replacement))
+	}
+	return data
+}
+
+func collectCRDs(ctx context.Context, k8sAPI *k8s.KubernetesAPI, b *bundle, _ *diagBundleOptions) error {
+	type crdVersion struct {
+		Name    string `json:"name"`
+		Served  bool   `json:"served"`
+		Storage bool   `json:"storage"`
+	}
+	type crd struct {
+		Name     string       `json:"name"`
+		Version  string       `json:"createdBy,omitempty"`
+		Versions []crdVersion `json:"versions"`
+	}
+
+	var crds []crd
+	list, err := k8sAPI.Apiextensions.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	if err == nil {
+		for _, item := range list.Items {
+			if !strings.HasSuffix(item.Spec.Group, "linkerd.io") {
+				continue
+			}
+			c := crd{Name: item.Name, Version: item.Annotations[k8s.CreatedByAnnotation]}
+			for _, v := range item.Spec.Versions {
+				c.Versions = append(c.Versions, crdVersion{Name: v.Name, Served: v.Served, Storage: v.Storage})
+			}
+			crds = append(crds, c)
+		}
+	}
+	var data []byte
+	if err == nil {
+		sort.Slice(crds, func(i, j int) bool { return crds[i].Name < crds[j].Name })
+		data, err = yaml.Marshal(crds)
+	}
+	return b.add("crds.yaml", "versions of the Linkerd CRDs", data, err)
+}
+
+func collectLinks(ctx context.Context, k8sAPI *k8s.KubernetesAPI, b *bundle, _ *diagBundleOptions) error {
+	type link struct {
+		Name                string      `json:"name"`
+		Namespace           string      `json:"namespace"`
+		TargetClusterName   string      `json:"targetClusterName"`
+		TargetClusterDomain string      `json:"targetClusterDomain"`
+		Status              interface{} `json:"status"`
+	}
+
+	var data []byte
+	list, err := k8sAPI.L5dCrdClient.LinkV1alpha3().Links("").List(ctx, metav1.ListOptions{})
+	if err == nil {
+		links := make([]link, 0, len(list.Items))
+		for _, l := range list.Items {
+			links = append(links, link{
+				Name:                l.Name,
+				Namespace:           l.Namespace,
+				TargetClusterName:   l.Spec.TargetClusterName,
+				TargetClusterDomain: l.Spec.TargetClusterDomain,
+				Status:              l.Status,
+			})
+		}
+		data, err = yaml.Marshal(links)
+	}
+	return b.add("links.yaml", "statuses of the multicluster Links", data, err)
+}
+
+func collectControlPlaneLogs(ctx context.Context, k8sAPI *k8s.KubernetesAPI, b *bundle, options *diagBundleOptions) error {
+	pods, err := k8sAPI.CoreV1().Pods(controlPlaneNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return b.add("logs", "logs of the control plane", nil, err)
+	}
+
+	since := int64(options.since.Seconds())
+	for _, pod := range pods.Items {
+		for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			logs, err := k8sAPI.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+				Container:    container.Name,
+				SinceSeconds: &since,
+			}).DoRaw(ctx)
+			if err == nil {
+				logs = redactText(logs)
+			}
+			description := fmt.Sprintf("logs of the %s container of the %s pod", container.Name, pod.Name)
+			if err := b.add(path.Join("logs", pod.Name, container.Name+".log"), description, logs, err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func collectControllerMetrics(ctx context.Context, k8sAPI *k8s.KubernetesAPI, b *bundle, options *diagBundleOptions) error {
+	pods, err := k8sAPI.CoreV1().Pods(controlPlaneNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return b.add(path.Join("metrics", "controller"), "metrics of the control plane", nil, err)
+	}
+	results := getMetrics(k8sAPI, pods.Items, k8s.AdminHTTPPortNameSuffix, options.wait, false)
+	return addMetrics(b, path.Join("metrics", "controller"), results)
+}
+
+func collectProxyMetrics(ctx context.Context, k8sAPI *k8s.KubernetesAPI, b *bundle, options *diagBundleOptions) error {
+	namespaces := options.namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{controlPlaneNamespace}
+	}
+	for _, ns := range namespaces {
+		dir := path.Join("metrics", "proxy", ns)
+		pods, err := k8sAPI.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			if err := b.add(dir, "metrics of the proxies", nil, err); err != nil {
+				return err
+			}
+			continue
+		}
+		results := getMetrics(k8sAPI, pods.Items, k8s.ProxyAdminPortName, options.wait, false)
+		if err := addMetrics(b, dir, results); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addMetrics adds the metrics of each container to the bundle, with the
+// labels identifying workloads obfuscated.
+func addMetrics(b *bundle, dir string, results []metricsResult) error {
+	sort.Sort(byResult(results))
+	for _, result := range results {
+		data, err := result.metrics, result.err
+		if err == nil {
+			data, err = obfuscateMetrics(data)
+		}
+		file := path.Join(dir, result.pod, result.container+".txt")
+		if result.container == "" {
+			file = path.Join(dir, result.pod)
+		}
+		description := fmt.Sprintf("metrics of the %s container of the %s pod", result.container, result.pod)
+		if err := b.add(file, description, data, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func collectEndpoints(ctx context.Context, k8sAPI *k8s.KubernetesAPI, b *bundle, options *diagBundleOptions) error {
+	data, err := dumpEndpoints(ctx, k8sAPI, options.authorities)
+	return b.add("endpoints.json", "endpoints of the control plane services, as seen by the destination controller", data, err)
+}
+
+// dumpEndpoints returns the endpoints of authorities, or of the ports of the
+// control plane services if there are none, in JSON.
+func dumpEndpoints(ctx context.Context, k8sAPI *k8s.KubernetesAPI, authorities []string) ([]byte, error) {
+	if len(authorities) == 0 {
+		services, err := k8sAPI.CoreV1().Services(controlPlaneNamespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		clusterDomain := "cluster.local"
+		if _, values, err := healthcheck.FetchCurrentConfiguration(ctx, k8sAPI, controlPlaneNamespace); err == nil && values.ClusterDomain != "" {
+			clusterDomain = values.ClusterDomain
+		}
+		for _, svc := range services.Items {
+			for _, port := range svc.Spec.Ports {
+				authorities = append(authorities, fmt.Sprintf("%s.%s.svc.%s:%d", svc.Name, svc.Namespace, clusterDomain, port.Port))
+			}
+		}
+		if len(authorities) == 0 {
+			return nil, fmt.Errorf("no services found in the %s namespace", controlPlaneNamespace)
+		}
+	}
+
+	client, conn, err := destination.NewExternalClient(ctx, controlPlaneNamespace, k8sAPI, "")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	endpoints, err := requestEndpointsFromAPI(client, "", authorities)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(renderEndpoints(endpoints, &endpointsOptions{outputFormat: jsonOutput})), nil
+}
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	pkgcmd "github.com/linkerd/linkerd2/pkg/cmd"
+	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/tls"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type identityInspectOptions struct {
+	*identityOptions
+	secret               string
+	file                 string
+	trustAnchorsFile     string
+	expectedIdentity     string
+	minRemainingLifetime time.Duration
+	output               string
+}
+
+type inspectedChain struct {
+	Source   string            `json:"source"`
+	Identity string            `json:"identity,omitempty"`
+	Findings []tls.LintFinding `json:"findings"`
+	Error    string            `json:"error,omitempty"`
+}
+
+func newCmdIdentityInspect(parent *identityOptions) *cobra.Command {
+	options := &identityInspectOptions{
+		identityOptions:      parent,
+		minRemainingLifetime: time.Hour,
+		output:               tableOutput,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "inspect [flags] (PODS)",
+		Short: "Lint the certificate chain of pods, a Secret or a PEM file",
+		Long: `Lint the certificate chain of pods, a Secret or a PEM file.
+
+Checks chain completeness against the trust anchors, SAN format against the
+expected identity, key usages, path length constraints, clock skew, remaining
+lifetime, signature algorithms and public keys.
+
+By default the trust anchors are read from the linkerd-identity-trust-roots
+ConfigMap in the control plane namespace.`,
+		Example: `  # Lint the certificate served by the proxy of pod foo-bar in the default namespace.
+  linkerd identity inspect foo-bar
+
+  # Lint the issuer certificate stored in the linkerd-identity-issuer Secret.
+  linkerd identity inspect -n linkerd --secret linkerd-identity-issuer
+
+  # Lint a PEM file offline.
+  linkerd identity inspect --file crt.pem --trust-anchors-file ca.crt -o json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.validate(args); err != nil {
+				return err
+			}
+			if options.namespace == "" {
+				options.namespace = pkgcmd.GetDefaultNamespace(kubeconfigPath, kubeContext)
+			}
+			chains, err := options.inspect(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+			if err := renderInspectedChains(os.Stdout, chains, options.output); err != nil {
+				return err
+			}
+			for _, c := range chains {
+				if c.Error != "" || tls.LintWorstSeverity(c.Findings) == tls.LintError {
+					return errors.New("certificate inspection found errors")
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.secret, "secret", "", "Name of a Secret holding a certificate chain (tls.crt or crt.pem)")
+	cmd.Flags().StringVar(&options.file, "file", "", "Path to a file holding a PEM-encoded certificate chain, from leaf to root")
+	cmd.Flags().StringVar(&options.trustAnchorsFile, "trust-anchors-file", "", "Path to a PEM file holding the trust anchors; read from the cluster when unset")
+	cmd.Flags().StringVar(&options.expectedIdentity, "identity", "", "Expected identity of the end-entity certificate; derived from the pod when unset")
+	cmd.Flags().DurationVar(&options.minRemainingLifetime, "min-remaining-lifetime", options.minRemainingLifetime, "Warn about certificates that expire within this duration")
+	cmd.Flags().StringVarP(&options.output, "output", "o", options.output, fmt.Sprintf("Output format; one of: \"%s\" or \"%s\"", tableOutput, jsonOutput))
+
+	return cmd
+}
+
+func (o *identityInspectOptions) validate(args []string) error {
+	sources := 0
+	if len(args) > 0 || o.selector != "" {
+		sources++
+	}
+	if o.secret != "" {
+		sources++
+	}
+	if o.file != "" {
+		sources++
+	}
+	if sources != 1 {
+		return errors.New("provide exactly one of: pod names or a selector, --secret, or --file")
+	}
+	if o.output != tableOutput && o.output != jsonOutput {
+		return fmt.Errorf("--output currently only supports %s and %s", tableOutput, jsonOutput)
+	}
+	return nil
+}
+
+func (o *identityInspectOptions) inspect(ctx context.Context, args []string) ([]inspectedChain, error) {
+	lintOpts := tls.LintOptions{
+		ExpectedIdentity:     o.expectedIdentity,
+		MinRemainingLifetime: o.minRemainingLifetime,
+	}
+
+	if o.file != "" && o.trustAnchorsFile != "" {
+		// Fully offline inspection.
+		anchors, err := readTrustAnchorsFile(o.trustAnchorsFile)
+		if err != nil {
+			return nil, err
+		}
+		lintOpts.TrustAnchors = anchors
+		return []inspectedChain{inspectFile(o.file, lintOpts)}, nil
+	}
+
+	k8sAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup, 0)
+	if err != nil {
+		return nil, err
+	}
+	if o.trustAnchorsFile != "" {
+		lintOpts.TrustAnchors, err = readTrustAnchorsFile(o.trustAnchorsFile)
+	} else {
+		lintOpts.TrustAnchors, err = fetchTrustAnchors(ctx, k8sAPI)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if o.file != "" {
+		return []inspectedChain{inspectFile(o.file, lintOpts)}, nil
+	}
+	if o.secret != "" {
+		return []inspectedChain{inspectSecret(ctx, k8sAPI, o.namespace, o.secret, lintOpts)}, nil
+	}
+
+	pods, err := getPods(ctx, k8sAPI, o.namespace, o.selector, args)
+	if err != nil {
+		return nil, err
+	}
+	var chains []inspectedChain
+	for _, c := range getCertificate(k8sAPI, pods, k8s.ProxyAdminPortName, emitLog) {
+		chain := inspectedChain{Source: fmt.Sprintf("pod/%s", c.pod)}
+		if c.err != nil {
+			chain.Error = c.err.Error()
+			chains = append(chains, chain)
+			continue
+		}
+		podOpts := lintOpts
+		if podOpts.ExpectedIdentity == "" {
+			for i := range pods {
+				if pods[i].GetName() == c.pod {
+					podOpts.ExpectedIdentity, _ = k8s.PodIdentity(&pods[i])
+				}
+			}
+		}
+		chain.Identity = podOpts.ExpectedIdentity
+		chain.Findings = tls.LintChain(c.Certificate, podOpts)
+		chains = append(chains, chain)
+	}
+	return chains, nil
+}
+
+func inspectFile(path string, opts tls.LintOptions) inspectedChain {
+	chain := inspectedChain{Source: path, Identity: opts.ExpectedIdentity}
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		chain.Error = err.Error()
+		return chain
+	}
+	return lintPEM(chain, string(b), opts)
+}
+
+func inspectSecret(ctx context.Context, k8sAPI *k8s.KubernetesAPI, namespace, name string, opts tls.LintOptions) inspectedChain {
+	chain := inspectedChain{Source: fmt.Sprintf("secret/%s", name), Identity: opts.ExpectedIdentity}
+	secret, err := k8sAPI.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		chain.Error = err.Error()
+		return chain
+	}
+	for _, key := range []string{corev1.TLSCertKey, k8s.IdentityIssuerCrtName} {
+		if data, ok := secret.Data[key]; ok {
+			return lintPEM(chain, string(data), opts)
+		}
+	}
+	chain.Error = fmt.Sprintf("secret %s/%s has no %s or %s entry", namespace, name, corev1.TLSCertKey, k8s.IdentityIssuerCrtName)
+	return chain
+}
+
+func lintPEM(chain inspectedChain, pem string, opts tls.LintOptions) inspectedChain {
+	crts, err := tls.DecodePEMCertificates(pem)
+	if err != nil {
+		chain.Error = err.Error()
+		return chain
+	}
+	chain.Findings = tls.LintChain(crts, opts)
+	return chain
+}
+
+func readTrustAnchorsFile(path string) (*x509.CertPool, error) {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	return tls.DecodePEMCertPool(string(b))
+}
+
+func fetchTrustAnchors(ctx context.Context, k8sAPI *k8s.KubernetesAPI) (*x509.CertPool, error) {
+	bundle, err := healthcheck.FetchTrustBundle(ctx, *k8sAPI, controlPlaneNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the trust anchors from the cluster (use --trust-anchors-file instead): %w", err)
+	}
+	return tls.DecodePEMCertPool(bundle)
+}
+
+func renderInspectedChains(w io.Writer, chains []inspectedChain, format string) error {
+	if format == jsonOutput {
+		b, err := json.MarshalIndent(chains, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+
+	for i, c := range chains {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s", c.Source)
+		if c.Identity != "" {
+			fmt.Fprintf(w, " (%s)", c.Identity)
+		}
+		fmt.Fprintln(w)
+		if c.Error != "" {
+			fmt.Fprintf(w, "%s %s\n", failStatus, c.Error)
+			continue
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, padding, ' ', 0)
+		fmt.Fprintln(tw, "\tCHECK\tCERTIFICATE\tMESSAGE")
+		for _, f := range c.Findings {
+			status := okStatus
+			switch f.Severity {
+			case tls.LintWarning:
+				status = warnStatus
+			case tls.LintError:
+				status = failStatus
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", status, f.Check, f.Subject, f.Message)
+		}
+		tw.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/tls"
+)
+
+func TestInspectFile(t *testing.T) {
+	root, err := tls.GenerateRootCAWithDefaults("root.linkerd.cluster.local")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	issuer, err := root.GenerateCA("identity.linkerd.cluster.local", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	const id = "default.emojivoto.serviceaccount.identity.linkerd.cluster.local"
+	leaf, err := issuer.GenerateEndEntityCred(id)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	dir := t.TempDir()
+	crtPath := filepath.Join(dir, "crt.pem")
+	if err := os.WriteFile(crtPath, []byte(leaf.Crt.EncodePEM()), 0600); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	testCases := []struct {
+		desc     string
+		identity string
+		expected tls.LintSeverity
+	}{
+		{"matching identity", id, tls.LintOK},
+		{"mismatched identity", "web.emojivoto.serviceaccount.identity.linkerd.cluster.local", tls.LintError},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			chain := inspectFile(crtPath, tls.LintOptions{
+				TrustAnchors:     root.Cred.CertPool(),
+				ExpectedIdentity: tc.identity,
+			})
+			if chain.Error != "" {
+				t.Fatalf("Unexpected error: %s", chain.Error)
+			}
+			if severity := tls.LintWorstSeverity(chain.Findings); severity != tc.expected {
+				t.Fatalf("Expected %s, got %s: %+v", tc.expected, severity, chain.Findings)
+			}
+
+			var buf bytes.Buffer
+			if err := renderInspectedChains(&buf, []inspectedChain{chain}, jsonOutput); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			var rendered []inspectedChain
+			if err := json.Unmarshal(buf.Bytes(), &rendered); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if len(rendered) != 1 || rendered[0].Source != crtPath || len(rendered[0].Findings) != len(chain.Findings) {
+				t.Fatalf("Unexpected JSON output: %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestInspectFileMissing(t *testing.T) {
+	chain := inspectFile(filepath.Join(t.TempDir(), "missing.pem"), tls.LintOptions{})
+	if chain.Error == "" {
+		t.Fatal("Expected an error for a missing file")
+	}
+}
@@ -3,16 +3,19 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/linkerd/linkerd2/cli/flag"
+	"github.com/linkerd/linkerd2/controller/webhook"
 	"github.com/linkerd/linkerd2/pkg/charts/linkerd2"
 	"github.com/linkerd/linkerd2/pkg/healthcheck"
 	"github.com/linkerd/linkerd2/pkg/inject"
@@ -29,6 +32,7 @@ const (
 	injectDisabledDesc               = "pods are not annotated to disable injection"
 	unsupportedDesc                  = "at least one resource can be injected or annotated"
 	udpDesc                          = "pod specs do not include UDP ports"
+	portsDesc                        = "pod specs do not include port or protocol misconfigurations"
 	automountServiceAccountTokenDesc = "pods do not have automountServiceAccountToken set to \"false\" or service account token projection is enabled"
 	slash                            = "/"
 )
@@ -41,6 +45,16 @@ type resourceTransformerInject struct {
 	enableDebugSidecar  bool
 	closeWaitTimeout    time.Duration
 	overrider           inject.ValueOverrider
+
+	// explain, defaults and configValues are used to report the provenance
+	// of every effective proxy setting
+	explain      bool
+	defaults     *linkerd2.Values
+	configValues *linkerd2.Values
+
+	// serverSide, when set, injects the proxy through the proxy injector's
+	// dry-run endpoint instead of rendering it locally
+	serverSide *dryRunClient
 }
 
 func runInjectCmd(inputs []io.Reader, errWriter, outWriter io.Writer, transformer *resourceTransformerInject, output string) int {
@@ -55,7 +69,7 @@ func NewCmdInject(overrider inject.ValueOverrider) *cobra.Command {
 	}
 	flags, proxyFlagSet := makeProxyFlags(defaults)
 	injectFlags, injectFlagSet := makeInjectFlags(defaults)
-	var manualOption, enableDebugSidecar bool
+	var manualOption, enableDebugSidecar, explain, serverSide, postRenderer, krmFunction, diff bool
 	var closeWaitTimeout time.Duration
 	var output string
 
@@ -73,11 +87,40 @@ sub-folders, or coming from stdin.`,
   linkerd inject https://url.to/yml | kubectl apply -f -
 
   # Inject all the resources inside a folder and its sub-folders.
-  linkerd inject <folder> | kubectl apply -f -`,
+  linkerd inject <folder> | kubectl apply -f -
+
+  # Show where every proxy setting of a deployment comes from.
+  linkerd inject --explain deployment.yml > /dev/null
+
+  # Inject the proxy exactly as the cluster's proxy injector would.
+  linkerd inject --server-side deployment.yml
+
+  # Review the changes injection makes to a deployment.
+  linkerd inject --diff deployment.yml
+
+  # Inject the proxy into the manifests of a Helm release.
+  helm install emojivoto ./emojivoto --post-renderer linkerd --post-renderer-args inject --post-renderer-args --post-renderer
+
+  # Inject the proxy as a Kustomize KRM function, declared in kustomization.yaml
+  # as an exec function running "linkerd inject --krm-function".
+  kustomize build --enable-alpha-plugins --enable-exec .`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) < 1 {
+			if postRenderer && krmFunction {
+				return errors.New("--post-renderer and --krm-function are mutually exclusive")
+			}
+			streaming := postRenderer || krmFunction
+			if len(args) < 1 && !streaming {
 				return fmt.Errorf("please specify a kubernetes resource file")
 			}
+			if streaming && output != yamlOutput {
+				return errors.New("--post-renderer and --krm-function only support YAML output")
+			}
+			if diff && (streaming || output != yamlOutput) {
+				return errors.New("--diff can't be used with --post-renderer, --krm-function or a non-YAML output")
+			}
+			if serverSide && (manualOption || ignoreCluster) {
+				return errors.New("--server-side can't be used with --manual or --ignore-cluster")
+			}
 
 			values := defaults
 			if !ignoreCluster {
@@ -96,7 +139,7 @@ sub-folders, or coming from stdin.`,
 				return err
 			}
 
-			in, err := read(args[0])
+			in, err := readStreamingInput(args)
 			if err != nil {
 				return err
 			}
@@ -111,8 +154,34 @@ sub-folders, or coming from stdin.`,
 				enableDebugSidecar:  enableDebugSidecar,
 				closeWaitTimeout:    closeWaitTimeout,
 				overrider:           overrider,
+				explain:             explain,
+				defaults:            defaults,
+				configValues:        baseValues,
+			}
+			if serverSide {
+				k8sAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup, 0)
+				if err != nil {
+					return err
+				}
+				transformer.serverSide, err = newDryRunClient(cmd.Context(), k8sAPI, controlPlaneNamespace)
+				if err != nil {
+					return fmt.Errorf("failed to reach the proxy injector: %w", err)
+				}
+			}
+			var exitCode int
+			switch {
+			case postRenderer:
+				exitCode = runPostRenderer(in, stderr, stdout, resourceTransformerUninjectAndInject{transformer})
+			case krmFunction:
+				exitCode = runKRMFunction(io.MultiReader(in...), stderr, stdout, resourceTransformerUninjectAndInject{transformer})
+			case diff:
+				exitCode = runInjectDiff(in, stderr, stdout, transformer, args[0])
+			default:
+				exitCode = uninjectAndInject(in, stderr, stdout, transformer, output)
+			}
+			if transformer.serverSide != nil {
+				transformer.serverSide.close()
 			}
-			exitCode := uninjectAndInject(in, stderr, stdout, transformer, output)
 			os.Exit(exitCode)
 			return nil
 		},
@@ -137,6 +206,21 @@ sub-folders, or coming from stdin.`,
 
 	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format, one of: json|yaml")
 
+	cmd.Flags().BoolVar(&serverSide, "server-side", serverSide,
+		"Inject the proxy through the cluster's proxy injector, so that the output matches what the webhook would produce (requires proxyInjector.dryRun.enabled=true)")
+
+	cmd.Flags().BoolVar(&explain, "explain", explain,
+		"Report the effective value of every proxy setting, the layer it comes from, and the config annotations that are ignored")
+
+	cmd.Flags().BoolVar(&diff, "diff", diff,
+		"Print a unified diff between the input and the injected resources instead of the injected resources")
+
+	cmd.Flags().BoolVar(&postRenderer, "post-renderer", postRenderer,
+		"Run as a Helm post-renderer: read the manifests from stdin unless CONFIG-FILE is given, and output every document in order with its comments")
+
+	cmd.Flags().BoolVar(&krmFunction, "krm-function", krmFunction,
+		"Run as a Kustomize KRM function: read a ResourceList from stdin unless CONFIG-FILE is given, and output the transformed ResourceList")
+
 	cmd.Flags().AddFlagSet(proxyFlagSet)
 	cmd.Flags().AddFlagSet(injectFlagSet)
 
@@ -207,25 +291,69 @@ func (rt resourceTransformerInject) transform(bytes []byte) ([]byte, []inject.Re
 		conf.AppendPodAnnotation(k8s.ProxyInjectAnnotation, k8s.ProxyInjectEnabled)
 	}
 
+	report.PortFindings, err = conf.AnalyzePorts(rt.overrider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if rt.serverSide != nil {
+		return rt.transformServerSide(bytes, conf, report)
+	}
+
 	patchJSON, err := conf.GetPodPatch(rt.injectProxy, rt.overrider)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if rt.explain {
+		report.Explanation, err = conf.Explain(rt.overrider, rt.defaults, rt.configValues)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	if len(patchJSON) == 0 {
 		return bytes, []inject.Report{*report}, nil
 	}
 	log.Infof("patch generated for: %s", report.ResName())
 	log.Debugf("patch: %s", patchJSON)
-	patch, err := jsonpatch.DecodePatch(patchJSON)
+	injectedJSON, err := applyPatch(bytes, patchJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+	injectedYAML, err := conf.JSONToYAML(injectedJSON)
 	if err != nil {
 		return nil, nil, err
 	}
-	origJSON, err := yaml.YAMLToJSON(bytes)
+	return injectedYAML, []inject.Report{*report}, nil
+}
+
+// transformServerSide adds the annotations the proxy injector relies on, and
+// then applies the patch the proxy injector returns for the annotated
+// workload.
+func (rt resourceTransformerInject) transformServerSide(bytes []byte, conf *inject.ResourceConfig, report *inject.Report) ([]byte, []inject.Report, error) {
+	annotationPatch, err := conf.GetPodPatch(false, rt.overrider)
 	if err != nil {
 		return nil, nil, err
 	}
-	injectedJSON, err := patch.Apply(origJSON)
+	annotatedJSON, err := applyPatch(bytes, annotationPatch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response, err := rt.serverSide.inject(context.Background(), annotatedJSON, rt.explain)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to inject %s: %w", report.ResName(), err)
+	}
+	if explanation, ok := response.AuditAnnotations[webhook.ExplanationAuditAnnotation]; ok {
+		if err := json.Unmarshal([]byte(explanation), &report.Explanation); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode the explanation for %s: %w", report.ResName(), err)
+		}
+	}
+
+	log.Infof("patch received for: %s", report.ResName())
+	log.Debugf("patch: %s", response.Patch)
+	injectedJSON, err := applyPatch(annotatedJSON, response.Patch)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -236,6 +364,23 @@ func (rt resourceTransformerInject) transform(bytes []byte) ([]byte, []inject.Re
 	return injectedYAML, []inject.Report{*report}, nil
 }
 
+// applyPatch applies a JSON patch to a YAML or JSON document, and returns the
+// patched document as JSON.
+func applyPatch(doc []byte, patchJSON []byte) ([]byte, error) {
+	docJSON, err := yaml.YAMLToJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(patchJSON) == 0 {
+		return docJSON, nil
+	}
+	patch, err := jsonpatch.DecodePatch(patchJSON)
+	if err != nil {
+		return nil, err
+	}
+	return patch.Apply(docJSON)
+}
+
 func (resourceTransformerInject) generateReport(reports []inject.Report, output io.Writer) {
 	injected := []inject.Report{}
 	annotatable := false
@@ -244,6 +389,7 @@ func (resourceTransformerInject) generateReport(reports []inject.Report, output
 	udp := []string{}
 	injectDisabled := []string{}
 	automountServiceAccountTokenFalse := []string{}
+	portFindings := []string{}
 	warningsPrinted := verbose
 
 	for _, r := range reports {
@@ -279,6 +425,11 @@ func (resourceTransformerInject) generateReport(reports []inject.Report, output
 			automountServiceAccountTokenFalse = append(automountServiceAccountTokenFalse, r.ResName())
 			warningsPrinted = true
 		}
+
+		for _, f := range r.PortFindings {
+			portFindings = append(portFindings, fmt.Sprintf("%s: %s", r.ResName(), f.Message))
+			warningsPrinted = true
+		}
 	}
 
 	//
@@ -328,6 +479,14 @@ func (resourceTransformerInject) generateReport(reports []inject.Report, output
 		output.Write([]byte(fmt.Sprintf("%s %s\n", okStatus, automountServiceAccountTokenDesc)))
 	}
 
+	if len(portFindings) > 0 {
+		for _, f := range portFindings {
+			output.Write([]byte(fmt.Sprintf("%s %s\n", warnStatus, f)))
+		}
+	} else if verbose {
+		output.Write([]byte(fmt.Sprintf("%s %s\n", okStatus, portsDesc)))
+	}
+
 	//
 	// Summary
 	//
@@ -352,10 +511,50 @@ func (resourceTransformerInject) generateReport(reports []inject.Report, output
 		}
 	}
 
+	for _, r := range reports {
+		if r.Explanation != nil {
+			output.Write([]byte("\n"))
+			writeExplanation(r, output)
+		}
+	}
+
 	// Trailing newline to separate from kubectl output if piping
 	output.Write([]byte("\n"))
 }
 
+func writeExplanation(r inject.Report, output io.Writer) {
+	fmt.Fprintf(output, "%s \"%s\" proxy configuration:\n", r.Kind, r.Name)
+
+	w := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE\tANNOTATION")
+	for _, s := range r.Explanation.Settings {
+		value := s.Value
+		if value == "" {
+			value = "-"
+		}
+		annotation := s.Annotation
+		if annotation == "" {
+			annotation = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, value, explainedSource(s.Source, s.ProxyConfig), annotation)
+	}
+	w.Flush()
+
+	for _, ignored := range r.Explanation.Ignored {
+		fmt.Fprintf(output, "%s %s annotation \"%s: %s\" ignored: %s\n",
+			warnStatus, explainedSource(ignored.Source, ignored.ProxyConfig), ignored.Annotation, ignored.Value, ignored.Reason)
+	}
+}
+
+// explainedSource formats the source of a setting, along with the ProxyConfig
+// it comes from, if any.
+func explainedSource(source inject.Source, proxyConfig string) string {
+	if proxyConfig == "" {
+		return string(source)
+	}
+	return fmt.Sprintf("%s(%s)", source, proxyConfig)
+}
+
 func fetchConfigs(ctx context.Context) (*linkerd2.Values, error) {
 
 	hc := healthcheck.NewWithCoreChecks(&healthcheck.Options{
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	yamlDecoder "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// diffContext is the number of unchanged lines shown around every change.
+const diffContext = 3
+
+type diffLine struct {
+	op   diffmatchpatch.Operation
+	text string
+}
+
+// runInjectDiff injects the resources read from inputs like
+// uninjectAndInject, but instead of the injected resources it writes to
+// outWriter a unified diff between them and the input, both normalized so
+// that only the changes made by injection show up.
+// Returns the integer representation of os.Exit code; 0 on success and 1 on failure.
+func runInjectDiff(inputs []io.Reader, errWriter, outWriter io.Writer, transformer *resourceTransformerInject, label string) int {
+	var in bytes.Buffer
+	for _, input := range inputs {
+		if _, err := io.Copy(&in, input); err != nil {
+			fmt.Fprintf(errWriter, "Error reading resources: %v\n", err)
+			return 1
+		}
+		in.WriteString("\n---\n")
+	}
+
+	var out bytes.Buffer
+	if exitCode := uninjectAndInject([]io.Reader{bytes.NewReader(in.Bytes())}, errWriter, &out, transformer, yamlOutput); exitCode != 0 {
+		return exitCode
+	}
+
+	before, err := normalizeYAML(in.Bytes())
+	if err != nil {
+		fmt.Fprintf(errWriter, "Error normalizing resources: %v\n", err)
+		return 1
+	}
+	after, err := normalizeYAML(out.Bytes())
+	if err != nil {
+		fmt.Fprintf(errWriter, "Error normalizing injected resources: %v\n", err)
+		return 1
+	}
+	writeUnifiedDiff(outWriter, label, label+" (injected)", before, after)
+	return 0
+}
+
+// normalizeYAML re-encodes every document of a YAML stream with sorted keys
+// and the same indentation, dropping comments and empty documents.
+func normalizeYAML(in []byte) (string, error) {
+	reader := yamlDecoder.NewYAMLReader(bufio.NewReaderSize(bytes.NewReader(in), 4096))
+	var docs []string
+	for {
+		doc, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", err
+		}
+		j, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return "", err
+		}
+		if string(j) == "null" {
+			continue
+		}
+		y, err := yaml.JSONToYAML(j)
+		if err != nil {
+			return "", err
+		}
+		docs = append(docs, string(y))
+	}
+	return strings.Join(docs, "---\n"), nil
+}
+
+// writeUnifiedDiff writes the changes turning a into b in the unified
+// format, with diffContext lines of context.
+func writeUnifiedDiff(w io.Writer, fromLabel, toLabel, a, b string) {
+	dmp := diffmatchpatch.New()
+	chars1, chars2, lines := dmp.DiffLinesToChars(a, b)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(chars1, chars2, false), lines)
+
+	var all []diffLine
+	for _, d := range diffs {
+		for _, text := range strings.SplitAfter(d.Text, "\n") {
+			if text != "" {
+				all = append(all, diffLine{d.Type, text})
+			}
+		}
+	}
+
+	// oldLine and newLine are the line numbers in a and b of every line of
+	// all, or of the next line for lines missing from a or b
+	oldLine := make([]int, len(all)+1)
+	newLine := make([]int, len(all)+1)
+	oldLine[0], newLine[0] = 1, 1
+	for i, l := range all {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if l.op != diffmatchpatch.DiffInsert {
+			oldLine[i+1]++
+		}
+		if l.op != diffmatchpatch.DiffDelete {
+			newLine[i+1]++
+		}
+	}
+
+	headerWritten := false
+	for i := 0; i < len(all); {
+		if all[i].op == diffmatchpatch.DiffEqual {
+			i++
+			continue
+		}
+		start := max(0, i-diffContext)
+		// extend the hunk until diffContext lines past the last change that
+		// isn't followed by another one within 2*diffContext lines
+		end := i
+		for j := i; j < len(all) && j <= end+2*diffContext; j++ {
+			if all[j].op != diffmatchpatch.DiffEqual {
+				end = j
+			}
+		}
+		stop := min(len(all), end+diffContext+1)
+
+		if !headerWritten {
+			fmt.Fprintf(w, "--- %s\n+++ %s\n", fromLabel, toLabel)
+			headerWritten = true
+		}
+		fmt.Fprintf(w, "@@ -%s +%s @@\n",
+			hunkRange(oldLine[start], oldLine[stop]-oldLine[start]),
+			hunkRange(newLine[start], newLine[stop]-newLine[start]))
+		for _, l := range all[start:stop] {
+			prefix := " "
+			switch l.op {
+			case diffmatchpatch.DiffInsert:
+				prefix = "+"
+			case diffmatchpatch.DiffDelete:
+				prefix = "-"
+			}
+			fmt.Fprint(w, prefix, l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				fmt.Fprintln(w)
+			}
+		}
+		i = stop
+	}
+}
+
+// hunkRange formats the start and length of a hunk, the start of an empty
+// range being the line before it.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		start--
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/webhook"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+const proxyInjectorPort = 8443
+
+// dryRunClient runs manifests through the proxy injector's dry-run
+// endpoint, so that they are injected exactly as the webhook would inject
+// them in the cluster.
+type dryRunClient struct {
+	portForward *k8s.PortForward
+	client      *http.Client
+	url         string
+}
+
+// newDryRunClient port-forwards to the proxy injector. Its certificate is
+// verified against the CA bundle of the injector's
+// MutatingWebhookConfiguration, and requests are authenticated with the
+// kubeconfig's credentials.
+func newDryRunClient(ctx context.Context, k8sAPI *k8s.KubernetesAPI, namespace string) (*dryRunClient, error) {
+	mwc, err := k8sAPI.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, k8s.ProxyInjectorWebhookConfigName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(mwc.Webhooks) == 0 {
+		return nil, fmt.Errorf("MutatingWebhookConfiguration %s has no webhooks", k8s.ProxyInjectorWebhookConfigName)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(mwc.Webhooks[0].ClientConfig.CABundle) {
+		return nil, fmt.Errorf("MutatingWebhookConfiguration %s has an invalid caBundle", k8s.ProxyInjectorWebhookConfigName)
+	}
+
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			RootCAs:    roots,
+			ServerName: fmt.Sprintf("%s.%s.svc", k8s.ProxyInjectorWebhookServiceName, namespace),
+			MinVersion: tls.VersionTLS13,
+		},
+	}
+	rt, err := rest.HTTPWrappersForConfig(k8sAPI.Config, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	portForward, err := k8s.NewPortForward(ctx, k8sAPI, namespace, k8s.ProxyInjectorWebhookServiceName, "localhost", 0, proxyInjectorPort, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := portForward.Init(); err != nil {
+		return nil, err
+	}
+
+	return &dryRunClient{
+		portForward: portForward,
+		client:      &http.Client{Transport: rt, Timeout: 30 * time.Second},
+		url:         fmt.Sprintf("https://%s%s", portForward.AddressAndPort(), webhook.DryRunPath),
+	}, nil
+}
+
+// inject returns the AdmissionResponse of the proxy injector for manifest.
+func (c *dryRunClient) inject(ctx context.Context, manifest []byte, explain bool) (*admissionv1beta1.AdmissionResponse, error) {
+	u := c.url
+	if explain {
+		u += "?" + url.Values{webhook.DryRunExplainParam: []string{"true"}}.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(manifest))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.New("proxy injector dry-run endpoint not found, --server-side requires the control plane to be installed with proxyInjector.dryRun.enabled=true")
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("proxy injector dry-run unauthorized, --server-side requires token-based kubeconfig credentials: %s", strings.TrimSpace(string(body)))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy injector dry-run failed (%s): %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var response admissionv1beta1.AdmissionResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode proxy injector dry-run response: %w", err)
+	}
+	if !response.Allowed {
+		msg := "denied"
+		if response.Result != nil {
+			msg = response.Result.Message
+		}
+		return nil, errors.New(msg)
+	}
+	return &response, nil
+}
+
+func (c *dryRunClient) close() {
+	if c.portForward != nil {
+		c.portForward.Stop()
+	}
+}
@@ -0,0 +1,405 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/linkerd/linkerd2/pkg/inject"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	yamlv3 "go.yaml.in/yaml/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	yamlDecoder "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	resourceListAPIVersion = "config.kubernetes.io/v1"
+	resourceListKind       = "ResourceList"
+
+	krmSeverityError   = "error"
+	krmSeverityWarning = "warning"
+	krmSeverityInfo    = "info"
+)
+
+// krmTransformer is a resourceTransformer that also describes its outcome
+// for each resource, as the results of a KRM function.
+type krmTransformer interface {
+	resourceTransformer
+	// krmResult returns the result reported for a transformed resource, or
+	// nil if there's nothing worth reporting
+	krmResult(inject.Report) *krmResult
+}
+
+// resourceList is the input and output of a KRM function, as invoked by
+// Kustomize. See https://github.com/kubernetes-sigs/kustomize/blob/master/cmd/config/docs/api-conventions/functions-spec.md
+type resourceList struct {
+	APIVersion     string            `json:"apiVersion"`
+	Kind           string            `json:"kind"`
+	Items          []json.RawMessage `json:"items"`
+	FunctionConfig json.RawMessage   `json:"functionConfig,omitempty"`
+	Results        []krmResult       `json:"results,omitempty"`
+}
+
+type krmResult struct {
+	Message     string          `json:"message"`
+	Severity    string          `json:"severity"`
+	ResourceRef *krmResourceRef `json:"resourceRef,omitempty"`
+}
+
+type krmResourceRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// resourceTransformerUninjectAndInject uninjects every resource before
+// injecting it, like uninjectAndInject does for whole inputs. This allows the
+// resources to be transformed one at a time.
+type resourceTransformerUninjectAndInject struct {
+	*resourceTransformerInject
+}
+
+func (rt resourceTransformerUninjectAndInject) transform(bytes []byte) ([]byte, []inject.Report, error) {
+	uninjected, _, err := resourceTransformerUninjectSilent{rt.values}.transform(bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rt.resourceTransformerInject.transform(uninjected)
+}
+
+// readStreamingInput reads the resources of the CONFIG-FILE argument, or
+// stdin if there's none, as Helm and Kustomize pipe them in.
+func readStreamingInput(args []string) ([]io.Reader, error) {
+	if len(args) < 1 {
+		return read("-")
+	}
+	return read(args[0])
+}
+
+// runPostRenderer transforms the manifests read from inputs as a Helm
+// post-renderer: every document is written to outWriter as soon as it's
+// transformed, in the input order and with its comments, such as the
+// "# Source:" comments added by Helm.
+// Returns the integer representation of os.Exit code; 0 on success and 1 on failure.
+func runPostRenderer(inputs []io.Reader, errWriter, outWriter io.Writer, rt resourceTransformer) int {
+	reports := []inject.Report{}
+	for _, input := range inputs {
+		irs, err := processStream(input, outWriter, rt)
+		reports = append(reports, irs...)
+		if err != nil {
+			fmt.Fprintf(errWriter, "Error transforming resources:\n%v\n", err)
+			return 1
+		}
+	}
+	rt.generateReport(reports, errWriter)
+	return 0
+}
+
+// processStream transforms every YAML document of in and writes it to out,
+// preserving its comments.
+func processStream(in io.Reader, out io.Writer, rt resourceTransformer) ([]inject.Report, error) {
+	reader := yamlDecoder.NewYAMLReader(bufio.NewReaderSize(in, 4096))
+	reports := []inject.Report{}
+
+	for {
+		doc, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return reports, nil
+			}
+			return reports, err
+		}
+
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		comments, body := splitLeadingComments(doc)
+		result := body
+		if len(bytes.TrimSpace(body)) > 0 {
+			var irs []inject.Report
+			isList, err := kindIsList(body)
+			if err != nil {
+				return reports, err
+			}
+			if isList {
+				result, irs, err = processList(body, rt)
+			} else {
+				result, irs, err = rt.transform(body)
+			}
+			if err != nil {
+				return reports, err
+			}
+			reports = append(reports, irs...)
+
+			result, err = preserveComments(body, result)
+			if err != nil {
+				return reports, err
+			}
+		}
+
+		var buf bytes.Buffer
+		buf.WriteString("---\n")
+		buf.Write(comments)
+		buf.Write(result)
+		if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		if _, err := out.Write(buf.Bytes()); err != nil {
+			return reports, err
+		}
+	}
+}
+
+// splitLeadingComments splits a YAML document into the comments and blank
+// lines that precede its content, and its content. The separator that the
+// first document of a stream may start with is dropped.
+func splitLeadingComments(doc []byte) ([]byte, []byte) {
+	var comments []byte
+	i := 0
+	for i < len(doc) {
+		end := bytes.IndexByte(doc[i:], '\n')
+		if end < 0 {
+			end = len(doc) - i
+		} else {
+			end++
+		}
+		line := bytes.TrimSpace(doc[i : i+end])
+		if len(line) > 0 && line[0] != '#' && !bytes.Equal(line, []byte("---")) {
+			break
+		}
+		if !bytes.Equal(line, []byte("---")) {
+			comments = append(comments, doc[i:i+end]...)
+		}
+		i += end
+	}
+	return comments, doc[i:]
+}
+
+// preserveComments copies the comments of the original document onto the
+// matching nodes of the transformed one, which is returned re-encoded. It's
+// returned as is when the original has no comments.
+func preserveComments(original, transformed []byte) ([]byte, error) {
+	if !bytes.Contains(original, []byte("#")) || bytes.Equal(original, transformed) {
+		return transformed, nil
+	}
+
+	var from, to yamlv3.Node
+	if err := yamlv3.Unmarshal(original, &from); err != nil {
+		return nil, err
+	}
+	if err := yamlv3.Unmarshal(transformed, &to); err != nil {
+		return nil, err
+	}
+	if !copyComments(&from, &to) {
+		return transformed, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	encoder.CompactSeqIndent()
+	if err := encoder.Encode(&to); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// copyComments copies the comments of from and its children onto to and its
+// matching children, and returns whether any was copied. Mapping entries are
+// matched by key, and sequence items by their "name" field if they have one,
+// or else by position, as injection adds containers and volumes.
+func copyComments(from, to *yamlv3.Node) bool {
+	copied := false
+	for _, c := range []struct{ from, to *string }{
+		{&from.HeadComment, &to.HeadComment},
+		{&from.LineComment, &to.LineComment},
+		{&from.FootComment, &to.FootComment},
+	} {
+		if *c.from != "" && *c.to == "" {
+			*c.to = *c.from
+			copied = true
+		}
+	}
+	if from.Kind != to.Kind {
+		return copied
+	}
+
+	switch from.Kind {
+	case yamlv3.DocumentNode:
+		for i := range from.Content {
+			if i < len(to.Content) {
+				copied = copyComments(from.Content[i], to.Content[i]) || copied
+			}
+		}
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(from.Content); i += 2 {
+			for j := 0; j+1 < len(to.Content); j += 2 {
+				if from.Content[i].Value == to.Content[j].Value {
+					copied = copyComments(from.Content[i], to.Content[j]) || copied
+					copied = copyComments(from.Content[i+1], to.Content[j+1]) || copied
+					break
+				}
+			}
+		}
+	case yamlv3.SequenceNode:
+		for i, item := range from.Content {
+			if match := matchingItem(item, i, to.Content); match != nil {
+				copied = copyComments(item, match) || copied
+			}
+		}
+	}
+	return copied
+}
+
+// matchingItem returns the item of items matching item, the i-th item of the
+// original sequence.
+func matchingItem(item *yamlv3.Node, i int, items []*yamlv3.Node) *yamlv3.Node {
+	if name := itemName(item); name != "" {
+		for _, candidate := range items {
+			if itemName(candidate) == name {
+				return candidate
+			}
+		}
+		return nil
+	}
+	if i < len(items) {
+		return items[i]
+	}
+	return nil
+}
+
+func itemName(item *yamlv3.Node) string {
+	if item.Kind != yamlv3.MappingNode {
+		return ""
+	}
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		if item.Content[i].Value == "name" && item.Content[i+1].Kind == yamlv3.ScalarNode {
+			return item.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+// runKRMFunction transforms the items of the ResourceList read from input as
+// a Kustomize KRM function, and writes the ResourceList back to outWriter,
+// along with a result for every resource that was transformed or skipped.
+// Returns the integer representation of os.Exit code; 0 on success and 1 on failure.
+func runKRMFunction(input io.Reader, errWriter, outWriter io.Writer, rt krmTransformer) int {
+	in, err := io.ReadAll(input)
+	if err != nil {
+		fmt.Fprintf(errWriter, "Error reading ResourceList: %v\n", err)
+		return 1
+	}
+
+	var list resourceList
+	if err := yaml.Unmarshal(in, &list); err != nil {
+		fmt.Fprintf(errWriter, "Error reading ResourceList: %v\n", err)
+		return 1
+	}
+	if list.Kind != resourceListKind {
+		fmt.Fprintf(errWriter, "Error reading ResourceList: expected kind %s, got %q\n", resourceListKind, list.Kind)
+		return 1
+	}
+	if list.APIVersion == "" {
+		list.APIVersion = resourceListAPIVersion
+	}
+	// Results are only reported for this function's run
+	list.Results = nil
+
+	exitCode := 0
+	reports := []inject.Report{}
+	for i, item := range list.Items {
+		ref, err := krmRef(item)
+		if err != nil {
+			list.Results = append(list.Results, krmResult{Message: err.Error(), Severity: krmSeverityError})
+			exitCode = 1
+			continue
+		}
+
+		result, irs, err := rt.transform(item)
+		if err == nil {
+			list.Items[i], err = yaml.YAMLToJSON(result)
+		}
+		if err != nil {
+			list.Results = append(list.Results, krmResult{Message: err.Error(), Severity: krmSeverityError, ResourceRef: ref})
+			exitCode = 1
+			continue
+		}
+
+		for _, r := range irs {
+			if res := rt.krmResult(r); res != nil {
+				res.ResourceRef = ref
+				list.Results = append(list.Results, *res)
+			}
+		}
+		reports = append(reports, irs...)
+	}
+
+	out, err := yaml.Marshal(list)
+	if err != nil {
+		fmt.Fprintf(errWriter, "Error printing ResourceList: %v\n", err)
+		return 1
+	}
+	if _, err := outWriter.Write(out); err != nil {
+		fmt.Fprintf(errWriter, "Error printing ResourceList: %v\n", err)
+		return 1
+	}
+	rt.generateReport(reports, errWriter)
+	return exitCode
+}
+
+func krmRef(item []byte) (*krmResourceRef, error) {
+	var obj struct {
+		metav1.TypeMeta   `json:",inline"`
+		metav1.ObjectMeta `json:"metadata,omitempty"`
+	}
+	if err := json.Unmarshal(item, &obj); err != nil {
+		return nil, fmt.Errorf("invalid ResourceList item: %w", err)
+	}
+	return &krmResourceRef{
+		APIVersion: obj.APIVersion,
+		Kind:       obj.Kind,
+		Name:       obj.Name,
+		Namespace:  obj.Namespace,
+	}, nil
+}
+
+func (rt resourceTransformerInject) krmResult(r inject.Report) *krmResult {
+	if ok, reasons := r.Injectable(); ok {
+		return &krmResult{Message: "injected", Severity: krmSeverityInfo}
+	} else if r.Annotated {
+		return &krmResult{Message: "annotated", Severity: krmSeverityInfo}
+	} else if !r.UnsupportedResource {
+		messages := make([]string, 0, len(reasons))
+		for _, reason := range reasons {
+			if msg, ok := inject.Reasons[reason]; ok {
+				messages = append(messages, msg)
+			} else if reason == "" && r.InjectDisabled {
+				// The CLI only honors the workload's annotation, without
+				// recording a reason
+				messages = append(messages, fmt.Sprintf("\"%s: %s\" annotation set", k8s.ProxyInjectAnnotation, r.InjectAnnotationValue))
+			} else {
+				messages = append(messages, reason)
+			}
+		}
+		return &krmResult{Message: fmt.Sprintf("skipped: %s", strings.Join(messages, ", ")), Severity: krmSeverityWarning}
+	}
+	return nil
+}
+
+func (resourceTransformerUninject) krmResult(r inject.Report) *krmResult {
+	if r.Uninjected.Proxy || r.Uninjected.ProxyInit {
+		return &krmResult{Message: "uninjected", Severity: krmSeverityInfo}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/inject"
+)
+
+func TestPostRenderer(t *testing.T) {
+	in, err := os.Open("testdata/inject_helm_post_renderer.input.yml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer in.Close()
+
+	errBuf := &bytes.Buffer{}
+	outBuf := &bytes.Buffer{}
+	transformer := &resourceTransformerInject{
+		values:    defaultConfig(),
+		overrider: inject.GetOverriddenValues,
+	}
+	if exitCode := runPostRenderer([]io.Reader{in}, errBuf, outBuf, resourceTransformerUninjectAndInject{transformer}); exitCode != 0 {
+		t.Fatalf("Unexpected error. Exit code from runPostRenderer: %d: %s", exitCode, errBuf)
+	}
+	testDataDiffer.DiffTestdata(t, "inject_helm_post_renderer.golden.yml", outBuf.String())
+	testDataDiffer.DiffTestdata(t, "inject_helm_post_renderer.golden.stderr", errBuf.String())
+
+	// Uninjecting the injected stream restores the workloads, and keeps the
+	// documents' order and comments
+	errBuf.Reset()
+	uninjected := &bytes.Buffer{}
+	if exitCode := runPostRenderer([]io.Reader{outBuf}, errBuf, uninjected, resourceTransformerUninject{}); exitCode != 0 {
+		t.Fatalf("Unexpected error. Exit code from runPostRenderer: %d: %s", exitCode, errBuf)
+	}
+	testDataDiffer.DiffTestdata(t, "inject_helm_post_renderer_uninject.golden.yml", uninjected.String())
+}
+
+func TestKRMFunction(t *testing.T) {
+	in, err := os.Open("testdata/inject_krm_function.input.yml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer in.Close()
+
+	errBuf := &bytes.Buffer{}
+	outBuf := &bytes.Buffer{}
+	transformer := &resourceTransformerInject{
+		values:    defaultConfig(),
+		overrider: inject.GetOverriddenValues,
+	}
+	if exitCode := runKRMFunction(in, errBuf, outBuf, resourceTransformerUninjectAndInject{transformer}); exitCode != 0 {
+		t.Fatalf("Unexpected error. Exit code from runKRMFunction: %d: %s", exitCode, errBuf)
+	}
+	testDataDiffer.DiffTestdata(t, "inject_krm_function.golden.yml", outBuf.String())
+
+	uninjected := &bytes.Buffer{}
+	if exitCode := runKRMFunction(outBuf, errBuf, uninjected, resourceTransformerUninject{}); exitCode != 0 {
+		t.Fatalf("Unexpected error. Exit code from runKRMFunction: %d: %s", exitCode, errBuf)
+	}
+	testDataDiffer.DiffTestdata(t, "inject_krm_function_uninject.golden.yml", uninjected.String())
+
+	if exitCode := runKRMFunction(bytes.NewBufferString("kind: List\n"), errBuf, io.Discard, resourceTransformerUninject{}); exitCode == 0 {
+		t.Error("Expected inputs other than a ResourceList to be rejected")
+	}
+}
+
+func TestPreserveComments(t *testing.T) {
+	original := []byte(`kind: Deployment
+spec:
+  replicas: 1 # scaled by the HPA
+  template:
+    spec:
+      containers:
+      # The web frontend
+      - name: web
+        image: web:v1 # pinned
+`)
+	transformed := []byte(`kind: Deployment
+spec:
+  replicas: 1
+  template:
+    metadata:
+      annotations:
+        linkerd.io/inject: enabled
+    spec:
+      containers:
+      - image: proxy
+        name: linkerd-proxy
+      - image: web:v1
+        name: web
+`)
+	expected := `kind: Deployment
+spec:
+  replicas: 1 # scaled by the HPA
+  template:
+    metadata:
+      annotations:
+        linkerd.io/inject: enabled
+    spec:
+      containers:
+      - image: proxy
+        name: linkerd-proxy
+      # The web frontend
+      - image: web:v1 # pinned
+        name: web
+`
+
+	result, err := preserveComments(original, transformed)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(result) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
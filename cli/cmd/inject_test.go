@@ -3,18 +3,24 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/linkerd/linkerd2/controller/webhook"
 	"github.com/linkerd/linkerd2/pkg/charts/linkerd2"
 	"github.com/linkerd/linkerd2/pkg/cmd"
 	"github.com/linkerd/linkerd2/pkg/inject"
 	"github.com/linkerd/linkerd2/pkg/k8s"
 	"github.com/linkerd/linkerd2/testutil"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 )
 
 type testCase struct {
@@ -198,6 +204,18 @@ func TestUninjectAndInject(t *testing.T) {
 			injectProxy:      false,
 			testInjectConfig: defaultValues,
 		},
+		{
+			inputFileName:  "inject_emojivoto_cronjob.input.yml",
+			goldenFileName: "inject_emojivoto_cronjob_supervisor.golden.yml",
+			reportFileName: "inject_emojivoto_cronjob.report",
+			injectProxy:    true,
+			testInjectConfig: func() *linkerd2.Values {
+				values := defaultConfig()
+				values.Proxy.NativeSidecar = false
+				values.Proxy.EnableJobSupervisor = true
+				return values
+			}(),
+		},
 		{
 			inputFileName:    "inject_emojivoto_cronjob_nometa.input.yml",
 			goldenFileName:   "inject_emojivoto_cronjob_nometa.golden.yml",
@@ -503,6 +521,13 @@ func TestRunInjectCmd(t *testing.T) {
 			exitCode:             1,
 			injectProxy:          true,
 		},
+		{
+			inputFileName:        "inject_port_misconfiguration.input.yml",
+			stdOutGoldenFileName: "inject_port_misconfiguration.golden.yml",
+			stdErrGoldenFileName: "inject_port_misconfiguration.golden.stderr",
+			exitCode:             0,
+			injectProxy:          true,
+		},
 	}
 
 	for i, tc := range testCases {
@@ -628,6 +653,91 @@ func TestInjectFilePath(t *testing.T) {
 	})
 }
 
+func TestInjectExplain(t *testing.T) {
+	in, err := os.Open("testdata/inject_emojivoto_deployment_config_overrides.input.yml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defaults := defaultConfig()
+	config := defaultConfig()
+	config.Proxy.LogLevel = "warn,linkerd=debug"
+	values := defaultConfig()
+	values.Proxy.LogLevel = "warn,linkerd=debug"
+	values.Proxy.Await = false
+
+	errBuf := &bytes.Buffer{}
+	outBuf := &bytes.Buffer{}
+	transformer := &resourceTransformerInject{
+		values:       values,
+		overrider:    inject.GetOverriddenValues,
+		explain:      true,
+		defaults:     defaults,
+		configValues: config,
+	}
+	if exitCode := runInjectCmd([]io.Reader{in}, errBuf, outBuf, transformer, "yaml"); exitCode != 0 {
+		t.Fatalf("Unexpected error. Exit code from runInjectCmd: %d: %s", exitCode, errBuf)
+	}
+
+	testDataDiffer.DiffTestdata(t, "inject_emojivoto_deployment_explain.golden.stderr", errBuf.String())
+}
+
+func TestInjectServerSide(t *testing.T) {
+	in, err := os.Open("testdata/inject_emojivoto_deployment.input.yml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != webhook.DryRunPath || r.URL.Query().Get(webhook.DryRunExplainParam) != "true" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil || !strings.Contains(string(body), `"linkerd.io/inject":"enabled"`) {
+			http.Error(w, "workload is not annotated", http.StatusBadRequest)
+			return
+		}
+		patchType := admissionv1beta1.PatchTypeJSONPatch
+		response := admissionv1beta1.AdmissionResponse{
+			Allowed:   true,
+			PatchType: &patchType,
+			Patch:     []byte(`[{"op":"add","path":"/spec/template/spec/containers/-","value":{"name":"linkerd-proxy","image":"cr.l5d.io/linkerd/proxy:server-side"}}]`),
+			AuditAnnotations: map[string]string{
+				webhook.ExplanationAuditAnnotation: `{"settings":[{"name":"proxy.image.version","value":"server-side","source":"linkerd-config"}]}`,
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	errBuf := &bytes.Buffer{}
+	outBuf := &bytes.Buffer{}
+	transformer := &resourceTransformerInject{
+		values:    defaultConfig(),
+		overrider: inject.GetOverriddenValues,
+		explain:   true,
+		serverSide: &dryRunClient{
+			client: server.Client(),
+			url:    server.URL + webhook.DryRunPath,
+		},
+	}
+	if exitCode := runInjectCmd([]io.Reader{in}, errBuf, outBuf, transformer, "yaml"); exitCode != 0 {
+		t.Fatalf("Unexpected error. Exit code from runInjectCmd: %d: %s", exitCode, errBuf)
+	}
+
+	for _, expected := range []string{"linkerd.io/inject: enabled", "image: cr.l5d.io/linkerd/proxy:server-side"} {
+		if !strings.Contains(outBuf.String(), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, outBuf)
+		}
+	}
+	if !strings.Contains(errBuf.String(), "proxy.image.version  server-side  linkerd-config") {
+		t.Errorf("Expected the server-side explanation to be reported, got:\n%s", errBuf)
+	}
+}
+
 func TestToURL(t *testing.T) {
 	// if the string follows a URL pattern, true has to be returned
 	// if not false is returned
@@ -862,3 +972,158 @@ func diffOverrides(t *testing.T, expectedOverrides map[string]string, actualOver
 		}
 	}
 }
+
+func TestInjectUninjectRoundTrip(t *testing.T) {
+	testCases := []string{
+		"inject_emojivoto_deployment.input.yml",
+		"inject_emojivoto_deployment_config_overrides.input.yml",
+		"inject_emojivoto_list.input.yml",
+		"inject_emojivoto_pod.input.yml",
+		"inject_emojivoto_statefulset.input.yml",
+		"inject_emojivoto_cronjob.input.yml",
+	}
+
+	for _, inputFileName := range testCases {
+		inputFileName := inputFileName // pin
+		t.Run(inputFileName, func(t *testing.T) {
+			input, err := os.ReadFile(filepath.Join("testdata", inputFileName))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			values := defaultConfig()
+			transformer := &resourceTransformerInject{
+				injectProxy:         true,
+				values:              values,
+				overrideAnnotations: getOverrideAnnotations(values, defaultConfig()),
+				enableDebugSidecar:  true,
+				allowNsInject:       true,
+				overrider:           inject.GetOverriddenValues,
+			}
+			injected := &bytes.Buffer{}
+			report := &bytes.Buffer{}
+			if exitCode := runInjectCmd([]io.Reader{bytes.NewReader(input)}, report, injected, transformer, "yaml"); exitCode != 0 {
+				t.Fatalf("Unexpected error injecting YAML: %s", report)
+			}
+			if !strings.Contains(injected.String(), k8s.ProxyInjectionManifestAnnotation) {
+				t.Fatalf("Expected the injected YAML to have the %s annotation", k8s.ProxyInjectionManifestAnnotation)
+			}
+
+			uninjected := &bytes.Buffer{}
+			if exitCode := runUninjectSilentCmd([]io.Reader{injected}, report, uninjected, values, "yaml"); exitCode != 0 {
+				t.Fatalf("Unexpected error uninjecting YAML: %s", report)
+			}
+
+			expected, err := normalizeYAML(input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			actual, err := normalizeYAML(uninjected.Bytes())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if actual != expected {
+				diff := &bytes.Buffer{}
+				writeUnifiedDiff(diff, "input", "uninjected", expected, actual)
+				t.Fatalf("Expected uninjection to restore the input, got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUninjectManifestWorkloadMeta(t *testing.T) {
+	input, err := os.ReadFile("testdata/inject_emojivoto_deployment.input.yml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Injection doesn't change the workload's own metadata, so its linkerd.io
+	// annotations and labels are kept
+	withMeta := strings.Replace(string(input), "metadata:\n  name: web\n",
+		"metadata:\n  annotations:\n    linkerd.io/created-by: linkerd/cli\n  labels:\n    linkerd.io/control-plane-ns: linkerd\n  name: web\n", 1)
+	if withMeta == string(input) {
+		t.Fatal("Expected the input to have the web Deployment's metadata")
+	}
+
+	values := defaultConfig()
+	transformer := &resourceTransformerInject{
+		injectProxy: true,
+		values:      values,
+		overrider:   inject.GetOverriddenValues,
+	}
+	injected := &bytes.Buffer{}
+	report := &bytes.Buffer{}
+	if exitCode := runInjectCmd([]io.Reader{strings.NewReader(withMeta)}, report, injected, transformer, "yaml"); exitCode != 0 {
+		t.Fatalf("Unexpected error injecting YAML: %s", report)
+	}
+
+	uninjected := &bytes.Buffer{}
+	if exitCode := runUninjectSilentCmd([]io.Reader{injected}, report, uninjected, values, "yaml"); exitCode != 0 {
+		t.Fatalf("Unexpected error uninjecting YAML: %s", report)
+	}
+
+	expected, err := normalizeYAML([]byte(withMeta))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	actual, err := normalizeYAML(uninjected.Bytes())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if actual != expected {
+		diff := &bytes.Buffer{}
+		writeUnifiedDiff(diff, "input", "uninjected", expected, actual)
+		t.Fatalf("Expected uninjection to restore the original input, got:\n%s", diff)
+	}
+}
+
+func TestRunInjectDiff(t *testing.T) {
+	in, err := os.Open("testdata/inject_emojivoto_deployment.input.yml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer in.Close()
+
+	values := defaultConfig()
+	transformer := &resourceTransformerInject{
+		injectProxy: true,
+		values:      values,
+		overrider:   inject.GetOverriddenValues,
+	}
+	errBuffer := &bytes.Buffer{}
+	outBuffer := &bytes.Buffer{}
+	if exitCode := runInjectDiff([]io.Reader{in}, errBuffer, outBuffer, transformer, "deployment.yml"); exitCode != 0 {
+		t.Fatalf("Unexpected error: %s", errBuffer)
+	}
+	testDataDiffer.DiffTestdata(t, "inject_emojivoto_deployment.diff.golden", outBuffer.String())
+}
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	a := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+	b := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	expected := `--- a
++++ b
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -10,3 +10,4 @@
+ j
+ k
+ l
++m
+`
+	out := &bytes.Buffer{}
+	writeUnifiedDiff(out, "a", "b", a, b)
+	if out.String() != expected {
+		t.Fatalf("Expected:\n%s\nbut got:\n%s", expected, out)
+	}
+
+	out.Reset()
+	writeUnifiedDiff(out, "a", "b", a, a)
+	if out.Len() != 0 {
+		t.Fatalf("Expected no diff between identical inputs, got:\n%s", out)
+	}
+}
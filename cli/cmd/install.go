@@ -65,6 +65,8 @@ var (
 		"templates/gateway.networking.k8s.io_tlsroutes.yaml",
 		"templates/gateway.networking.k8s.io_tcproutes.yaml",
 		"templates/workload/external-workload.yaml",
+		"templates/config/proxy-config.yaml",
+		"templates/config/proxy-rollout.yaml",
 	}
 
 	TemplatesControlPlane = []string{
@@ -72,6 +74,7 @@ var (
 		"templates/identity-rbac.yaml",
 		"templates/destination-rbac.yaml",
 		"templates/heartbeat-rbac.yaml",
+		"templates/check-runner-rbac.yaml",
 		"templates/podmonitor.yaml",
 		"templates/proxy-injector-rbac.yaml",
 		"templates/psp.yaml",
@@ -80,6 +83,7 @@ var (
 		"templates/identity.yaml",
 		"templates/destination.yaml",
 		"templates/heartbeat.yaml",
+		"templates/check-runner.yaml",
 		"templates/proxy-injector.yaml",
 	}
 
@@ -293,6 +293,9 @@ func TestRender(t *testing.T) {
 		{defaultValues, "install_custom_domain.golden", values.Options{}},
 		{defaultValues, "install_values_file.golden", values.Options{ValueFiles: []string{filepath.Join("testdata", "install_config.yaml")}}},
 		{defaultValues, "install_default_token.golden", values.Options{Values: []string{"identity.serviceAccountTokenProjection=false"}}},
+		{defaultValues, "install_enforcement.golden", values.Options{Values: []string{"proxyInjector.enforcement.enabled=true", "proxyInjector.enforcement.clusterWide=true"}}},
+		{defaultValues, "install_proxy_injector_dry_run.golden", values.Options{Values: []string{"proxyInjector.dryRun.enabled=true"}}},
+		{defaultValues, "install_check_runner.golden", values.Options{Values: []string{"checkRunner.enabled=true", "checkRunner.interval=10m", "checkRunner.categories={linkerd-identity,linkerd-data-plane}"}}},
 		{gidValues, "install_gid_output.golden", values.Options{}},
 		{tracingValues, "install_tracing.golden", values.Options{}},
 	}
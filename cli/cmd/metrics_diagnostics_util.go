@@ -17,6 +17,7 @@ import (
 
 // shared between metrics and diagnostics command
 type metricsResult struct {
+	namespace string
 	pod       string
 	container string
 	metrics   []byte
@@ -76,8 +77,9 @@ func getMetrics(
 			containers, err := getAllContainersWithPortSuffix(p, portName)
 			if err != nil {
 				resultChan <- metricsResult{
-					pod: p.GetName(),
-					err: err,
+					namespace: p.GetNamespace(),
+					pod:       p.GetName(),
+					err:       err,
 				}
 				return
 			}
@@ -93,6 +95,7 @@ func getMetrics(
 				bytes, err := k8s.GetContainerMetrics(k8sAPI, p, c, emitLogs, cname)
 
 				resultChan <- metricsResult{
+					namespace: p.GetNamespace(),
 					pod:       p.GetName(),
 					container: c.Name,
 					metrics:   bytes,
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -28,6 +29,7 @@ func runUninjectSilentCmd(inputs []io.Reader, errWriter, outWriter io.Writer, va
 
 func newCmdUninject() *cobra.Command {
 	var output string
+	var postRenderer, krmFunction bool
 
 	cmd := &cobra.Command{
 		Use:   "uninject [flags] CONFIG-FILE",
@@ -43,25 +45,46 @@ sub-folders, or coming from stdin.`,
   curl http://url.to/yml | linkerd uninject - | kubectl apply -f -
 
   # Uninject all the resources inside a folder and its sub-folders.
-  linkerd uninject <folder> | kubectl apply -f -`,
-		RunE: func(cmd *cobra.Command, args []string) error {
+  linkerd uninject <folder> | kubectl apply -f -
 
-			if len(args) < 1 {
+  # Uninject the proxy from the manifests of a Helm release.
+  helm template emojivoto ./emojivoto --post-renderer linkerd --post-renderer-args uninject --post-renderer-args --post-renderer`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if postRenderer && krmFunction {
+				return errors.New("--post-renderer and --krm-function are mutually exclusive")
+			}
+			streaming := postRenderer || krmFunction
+			if len(args) < 1 && !streaming {
 				return fmt.Errorf("please specify a kubernetes resource file")
 			}
+			if streaming && output != yamlOutput {
+				return errors.New("--post-renderer and --krm-function only support YAML output")
+			}
 
-			in, err := read(args[0])
+			in, err := readStreamingInput(args)
 			if err != nil {
 				return err
 			}
 
-			exitCode := runUninjectCmd(in, os.Stderr, os.Stdout, nil, output)
+			var exitCode int
+			switch {
+			case postRenderer:
+				exitCode = runPostRenderer(in, os.Stderr, os.Stdout, resourceTransformerUninject{})
+			case krmFunction:
+				exitCode = runKRMFunction(io.MultiReader(in...), os.Stderr, os.Stdout, resourceTransformerUninject{})
+			default:
+				exitCode = runUninjectCmd(in, os.Stderr, os.Stdout, nil, output)
+			}
 			os.Exit(exitCode)
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format, one of: json|yaml")
+	cmd.Flags().BoolVar(&postRenderer, "post-renderer", postRenderer,
+		"Run as a Helm post-renderer: read the manifests from stdin unless CONFIG-FILE is given, and output every document in order with its comments")
+	cmd.Flags().BoolVar(&krmFunction, "krm-function", krmFunction,
+		"Run as a Kustomize KRM function: read a ResourceList from stdin unless CONFIG-FILE is given, and output the transformed ResourceList")
 
 	return cmd
 }
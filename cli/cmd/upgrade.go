@@ -119,6 +119,8 @@ A full list of configurable values can be found at https://www.github.com/linker
 	cmd.Flags().BoolVar(&crds, "crds", false, "Upgrade Linkerd CRDs")
 	cmd.PersistentFlags().StringVarP(&output, "output", "o", "yaml", "Output format. One of: json|yaml")
 
+	cmd.AddCommand(newCmdUpgradeProxyRollout())
+
 	return cmd
 }
 
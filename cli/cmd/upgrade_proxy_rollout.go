@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/linkerd/linkerd2/cli/table"
+	pcv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/proxyconfig/v1alpha1"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const stableTrack = "stable"
+
+type proxyRolloutStatusOptions struct {
+	namespace    string
+	outputFormat string
+	wait         time.Duration
+}
+
+// proxyRolloutStatus is the adoption and error rate of every proxy version
+// running in the data plane, along with the ProxyRollouts driving them.
+type proxyRolloutStatus struct {
+	Rollouts []proxyRolloutSummary `json:"rollouts"`
+	Versions []proxyVersionStatus  `json:"versions"`
+}
+
+type proxyRolloutSummary struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Percentage int32  `json:"percentage"`
+}
+
+type proxyVersionStatus struct {
+	Version string `json:"version"`
+	// Track is the name of the ProxyRollout whose canary the pods run, or
+	// "stable"
+	Track    string  `json:"track"`
+	Pods     int     `json:"pods"`
+	Adoption float64 `json:"adoption"`
+	// Requests and Failures are the inbound responses served by the scraped
+	// proxies since they started
+	Requests  uint64  `json:"requests"`
+	Failures  uint64  `json:"failures"`
+	ErrorRate float64 `json:"errorRate"`
+	// Unreachable is the number of pods whose metrics couldn't be scraped
+	Unreachable int `json:"unreachable"`
+}
+
+func newProxyRolloutStatusOptions() *proxyRolloutStatusOptions {
+	return &proxyRolloutStatusOptions{
+		outputFormat: tableOutput,
+		wait:         30 * time.Second,
+	}
+}
+
+func (o *proxyRolloutStatusOptions) validate() error {
+	if o.outputFormat == tableOutput || o.outputFormat == jsonOutput {
+		return nil
+	}
+	return fmt.Errorf("--output currently only supports %s and %s", tableOutput, jsonOutput)
+}
+
+// newCmdUpgradeProxyRollout creates a new cobra command `proxy-rollout` which
+// contains commands to follow the rollout of proxy versions
+func newCmdUpgradeProxyRollout() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proxy-rollout",
+		Short: "Follow the gradual rollout of proxy versions",
+		Long: `Follow the gradual rollout of proxy versions.
+
+  Proxy versions are rolled out by creating ProxyRollout resources in the
+  control plane namespace. The proxy injector assigns the given percentage of
+  the workloads of the selected namespaces to the ProxyRollout's canary
+  version, based on their UID. Pods get the canary version once they're
+  re-created, e.g. with "kubectl rollout restart".`,
+		Example: `  # Roll edge-24.1.2 out to 10% of the workloads of the namespaces labeled env=staging
+  cat <<EOF | kubectl apply -f -
+  apiVersion: config.linkerd.io/v1alpha1
+  kind: ProxyRollout
+  metadata:
+    name: edge-24-1-2
+    namespace: linkerd
+  spec:
+    version: edge-24.1.2
+    percentage: 10
+    namespaceSelector:
+      matchLabels:
+        env: staging
+  EOF
+
+  # Then follow its adoption and error rate
+  linkerd upgrade proxy-rollout status`,
+	}
+
+	cmd.AddCommand(newCmdUpgradeProxyRolloutStatus())
+
+	return cmd
+}
+
+func newCmdUpgradeProxyRolloutStatus() *cobra.Command {
+	options := newProxyRolloutStatusOptions()
+
+	cmd := &cobra.Command{
+		Use:   "status [flags]",
+		Args:  cobra.NoArgs,
+		Short: "Show the adoption and error rate of each proxy version",
+		Long: `Show the adoption and error rate of each proxy version.
+
+  The meshed pods are grouped by proxy version and by track: either the
+  ProxyRollout whose canary they run, or "stable". The error rate is the share
+  of failed inbound responses served by the proxies since they started, as
+  scraped from their metrics endpoint.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.validate(); err != nil {
+				return err
+			}
+
+			k8sAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup, 0)
+			if err != nil {
+				return err
+			}
+
+			rollouts, err := k8sAPI.L5dCrdClient.ProxyconfigV1alpha1().ProxyRollouts(controlPlaneNamespace).List(cmd.Context(), metav1.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to list ProxyRollouts: %w", err)
+			}
+
+			pods, err := k8sAPI.CoreV1().Pods(options.namespace).List(cmd.Context(), metav1.ListOptions{
+				LabelSelector: fmt.Sprintf("%s=%s", k8s.ControllerNSLabel, controlPlaneNamespace),
+			})
+			if err != nil {
+				return err
+			}
+			var dataPlane []corev1.Pod
+			for _, pod := range pods.Items {
+				if pod.Namespace != controlPlaneNamespace {
+					dataPlane = append(dataPlane, pod)
+				}
+			}
+
+			results := getMetrics(k8sAPI, dataPlane, k8s.ProxyAdminPortName, options.wait, verbose)
+			status, err := buildProxyRolloutStatus(rollouts.Items, dataPlane, results)
+			if err != nil {
+				return err
+			}
+			return renderProxyRolloutStatus(status, options.outputFormat, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace of the pods to report on; all namespaces by default")
+	cmd.Flags().StringVarP(&options.outputFormat, "output", "o", options.outputFormat, fmt.Sprintf("Output format; one of: \"%s\" or \"%s\"", tableOutput, jsonOutput))
+	cmd.Flags().DurationVarP(&options.wait, "wait", "w", options.wait, "Time allowed to fetch the proxies' metrics")
+
+	return cmd
+}
+
+// buildProxyRolloutStatus groups pods by proxy version and track, and sums
+// the inbound responses found in their metrics.
+func buildProxyRolloutStatus(rollouts []pcv1alpha1.ProxyRollout, pods []corev1.Pod, results []metricsResult) (proxyRolloutStatus, error) {
+	status := proxyRolloutStatus{
+		Rollouts: []proxyRolloutSummary{},
+		Versions: []proxyVersionStatus{},
+	}
+	for _, pr := range rollouts {
+		status.Rollouts = append(status.Rollouts, proxyRolloutSummary{
+			Name:       pr.Name,
+			Version:    pr.Spec.Version,
+			Percentage: pr.Spec.Percentage,
+		})
+	}
+	sort.Slice(status.Rollouts, func(i, j int) bool { return status.Rollouts[i].Name < status.Rollouts[j].Name })
+
+	type key struct{ version, track string }
+	versions := map[key]*proxyVersionStatus{}
+	podKeys := map[string]key{}
+	for _, pod := range pods {
+		k := key{
+			version: pod.Annotations[k8s.ProxyVersionAnnotation],
+			track:   pod.Annotations[k8s.ProxyRolloutAnnotation],
+		}
+		if k.version == "" {
+			k.version = "unknown"
+		}
+		if k.track == "" {
+			k.track = stableTrack
+		}
+		if _, ok := versions[k]; !ok {
+			versions[k] = &proxyVersionStatus{Version: k.version, Track: k.track}
+		}
+		versions[k].Pods++
+		podKeys[pod.Namespace+"/"+pod.Name] = k
+	}
+
+	for _, result := range results {
+		k, ok := podKeys[result.namespace+"/"+result.pod]
+		if !ok {
+			continue
+		}
+		if result.err != nil {
+			versions[k].Unreachable++
+			continue
+		}
+		requests, failures, err := inboundResponses(result.metrics)
+		if err != nil {
+			return status, fmt.Errorf("failed to parse metrics of pod %s/%s: %w", result.namespace, result.pod, err)
+		}
+		versions[k].Requests += requests
+		versions[k].Failures += failures
+	}
+
+	for _, v := range versions {
+		v.Adoption = float64(v.Pods) / float64(len(pods))
+		if v.Requests > 0 {
+			v.ErrorRate = float64(v.Failures) / float64(v.Requests)
+		}
+		status.Versions = append(status.Versions, *v)
+	}
+	sort.Slice(status.Versions, func(i, j int) bool {
+		if status.Versions[i].Version != status.Versions[j].Version {
+			return status.Versions[i].Version < status.Versions[j].Version
+		}
+		return status.Versions[i].Track < status.Versions[j].Track
+	})
+	return status, nil
+}
+
+// inboundResponses returns the total and failed inbound responses counted by
+// the proxy's response_total metric.
+func inboundResponses(metrics []byte) (uint64, uint64, error) {
+	parser := expfmt.NewTextParser(model.LegacyValidation)
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(metrics))
+	if err != nil {
+		return 0, 0, err
+	}
+	family, ok := families["response_total"]
+	if !ok {
+		return 0, 0, nil
+	}
+
+	var requests, failures uint64
+	for _, m := range family.GetMetric() {
+		labels := map[string]string{}
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["direction"] != "inbound" || m.GetCounter() == nil {
+			continue
+		}
+		count := uint64(m.GetCounter().GetValue())
+		requests += count
+		if labels["classification"] == "failure" {
+			failures += count
+		}
+	}
+	return requests, failures, nil
+}
+
+func renderProxyRolloutStatus(status proxyRolloutStatus, format string, w io.Writer) error {
+	if format == jsonOutput {
+		out, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+	}
+
+	if len(status.Rollouts) == 0 {
+		fmt.Fprintln(w, "No ProxyRollouts found.")
+	}
+	for _, pr := range status.Rollouts {
+		fmt.Fprintf(w, "ProxyRollout %s: %s on %d%% of workloads\n", pr.Name, pr.Version, pr.Percentage)
+	}
+	fmt.Fprintln(w)
+
+	if len(status.Versions) == 0 {
+		fmt.Fprintln(w, "No meshed pods found.")
+		return nil
+	}
+	columns := []table.Column{
+		table.NewColumn("VERSION").WithLeftAlign(),
+		table.NewColumn("TRACK").WithLeftAlign(),
+		table.NewColumn("PODS"),
+		table.NewColumn("ADOPTION"),
+		table.NewColumn("REQUESTS"),
+		table.NewColumn("ERROR RATE"),
+		table.NewColumn("UNREACHABLE"),
+	}
+	rows := []table.Row{}
+	for _, v := range status.Versions {
+		errorRate := "-"
+		if v.Requests > 0 {
+			errorRate = fmt.Sprintf("%.2f%%", 100*v.ErrorRate)
+		}
+		rows = append(rows, table.Row{
+			v.Version,
+			v.Track,
+			strconv.Itoa(v.Pods),
+			fmt.Sprintf("%.1f%%", 100*v.Adoption),
+			strconv.FormatUint(v.Requests, 10),
+			errorRate,
+			strconv.Itoa(v.Unreachable),
+		})
+	}
+	t := table.NewTable(columns, rows)
+	t.Render(w)
+	return nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	pcv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/proxyconfig/v1alpha1"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProxyRolloutStatus(t *testing.T) {
+	rollouts := []pcv1alpha1.ProxyRollout{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "edge-24-1-2", Namespace: "linkerd"},
+			Spec:       pcv1alpha1.ProxyRolloutSpec{Version: "edge-24.1.2", Percentage: 25},
+		},
+	}
+
+	pod := func(namespace, name, version, rollout string) corev1.Pod {
+		annotations := map[string]string{k8s.ProxyVersionAnnotation: version}
+		if rollout != "" {
+			annotations[k8s.ProxyRolloutAnnotation] = rollout
+		}
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: annotations}}
+	}
+	pods := []corev1.Pod{
+		pod("emojivoto", "web-1", "edge-24.1.1", ""),
+		pod("emojivoto", "web-2", "edge-24.1.1", ""),
+		pod("emojivoto", "voting-1", "edge-24.1.2", "edge-24-1-2"),
+		pod("books", "web-1", "edge-24.1.1", ""),
+	}
+
+	metrics := func(success, failure string) []byte {
+		return []byte(`# HELP response_total Total count of HTTP responses.
+# TYPE response_total counter
+response_total{direction="inbound",classification="success",status_code="200"} ` + success + `
+response_total{direction="inbound",classification="failure",status_code="500"} ` + failure + `
+response_total{direction="outbound",classification="failure",status_code="500"} 1000
+`)
+	}
+	results := []metricsResult{
+		{namespace: "emojivoto", pod: "web-1", container: k8s.ProxyContainerName, metrics: metrics("90", "10")},
+		{namespace: "emojivoto", pod: "web-2", container: k8s.ProxyContainerName, metrics: metrics("100", "0")},
+		{namespace: "emojivoto", pod: "voting-1", container: k8s.ProxyContainerName, metrics: metrics("45", "5")},
+		{namespace: "books", pod: "web-1", err: errors.New("pod not running: web-1")},
+	}
+
+	status, err := buildProxyRolloutStatus(rollouts, pods, results)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, format := range []string{tableOutput, jsonOutput} {
+		var buf bytes.Buffer
+		if err := renderProxyRolloutStatus(status, format, &buf); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		testDataDiffer.DiffTestdata(t, "upgrade_proxy_rollout_status."+format+".golden", buf.String())
+	}
+}
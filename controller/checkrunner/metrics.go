@@ -0,0 +1,38 @@
+package checkrunner
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	labelCategory    = "category"
+	labelDescription = "description"
+	labelSeverity    = "severity"
+	labelResult      = "result"
+
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+var (
+	checkResult = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "linkerd_check_result",
+		Help: "Result of a check in the last run: 1 if it passed, 0 if it failed.",
+	}, []string{labelCategory, labelDescription, labelSeverity})
+
+	checkRunTimestamp = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "linkerd_check_last_run_timestamp_seconds",
+		Help: "Unix timestamp at which the last run of the checks completed.",
+	})
+
+	checkRunDuration = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "linkerd_check_last_run_duration_seconds",
+		Help: "Time it took to run the checks the last time.",
+	})
+
+	checkRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "linkerd_check_runs_total",
+		Help: "A counter for number of runs of the checks, by result.",
+	}, []string{labelResult})
+)
@@ -0,0 +1,256 @@
+package checkrunner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// StatusConfigMapName is the name of the ConfigMap, in the control plane
+	// namespace, holding the status of the last run of the checks
+	StatusConfigMapName = "linkerd-check-status"
+
+	// StatusKey is the key of the JSON encoded Status in the status ConfigMap
+	StatusKey = "status"
+
+	// Reasons of the category conditions
+	reasonPassed  = "ChecksPassed"
+	reasonWarning = "ChecksWarning"
+	reasonFailed  = "ChecksFailed"
+)
+
+// DefaultCategories are the categories run when none are given. They don't
+// depend on the environment of the CLI, and cover the certificates, the data
+// plane and the namespace configuration of the extensions. The checks of the
+// extensions themselves, such as viz and multicluster, are only run by their
+// own CLI. The version checks aren't run by default, as they call
+// versioncheck.linkerd.io with the install's UUID.
+var DefaultCategories = []healthcheck.CategoryID{
+	healthcheck.KubernetesAPIChecks,
+	healthcheck.LinkerdControlPlaneExistenceChecks,
+	healthcheck.LinkerdIdentity,
+	healthcheck.LinkerdWebhooksAndAPISvcTLS,
+	healthcheck.LinkerdDataPlaneChecks,
+	healthcheck.LinkerdExtensionChecks,
+}
+
+type (
+	// Status is the outcome of the last run of the checks, as published in
+	// the status ConfigMap.
+	Status struct {
+		LastRun  metav1.Time `json:"lastRun"`
+		Duration string      `json:"duration"`
+		Success  bool        `json:"success"`
+		Warning  bool        `json:"warning"`
+		// Conditions has a condition per category, whose type is the
+		// category ID
+		Conditions []metav1.Condition `json:"conditions"`
+		Checks     []CheckStatus      `json:"checks"`
+	}
+
+	// CheckStatus is the result of a single check.
+	CheckStatus struct {
+		Category    string `json:"category"`
+		Description string `json:"description"`
+		Severity    string `json:"severity"`
+		Passed      bool   `json:"passed"`
+		Error       string `json:"error,omitempty"`
+		HintURL     string `json:"hintURL,omitempty"`
+	}
+
+	// Runner periodically runs health checks, exporting their results as
+	// Prometheus metrics and in the status ConfigMap.
+	Runner struct {
+		client     kubernetes.Interface
+		namespace  string
+		interval   time.Duration
+		newChecker func() healthcheck.Runner
+		now        func() time.Time
+	}
+)
+
+// NewRunner creates a Runner for the given categories. A new HealthChecker
+// is created on every run, as it keeps the state gathered by the checks.
+func NewRunner(client kubernetes.Interface, namespace string, interval time.Duration, categories []healthcheck.CategoryID, options *healthcheck.Options) *Runner {
+	return &Runner{
+		client:    client,
+		namespace: namespace,
+		interval:  interval,
+		newChecker: func() healthcheck.Runner {
+			return healthcheck.NewHealthChecker(categories, options)
+		},
+		now: time.Now,
+	}
+}
+
+// Run runs the checks every interval, until ctx is cancelled.
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if err := r.runOnce(ctx); err != nil {
+			log.Warnf("Failed to publish check status: %s", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) runOnce(ctx context.Context) error {
+	start := r.now()
+	var checks []CheckStatus
+	success, warning := r.newChecker().RunChecks(func(result *healthcheck.CheckResult) {
+		if result.Retry {
+			return
+		}
+		check := CheckStatus{
+			Category: string(result.Category),
+			// Verbose successes append details to the description, which
+			// would make for unstable metric labels
+			Description: strings.SplitN(result.Description, "\n", 2)[0],
+			Severity:    severityError,
+			Passed:      result.Err == nil,
+		}
+		if result.Warning {
+			check.Severity = severityWarning
+		}
+		if result.Err != nil {
+			check.Error = result.Err.Error()
+			check.HintURL = result.HintURL
+		}
+		checks = append(checks, check)
+	})
+	end := r.now()
+
+	// Checks skipped after a fatal failure are dropped from the metrics,
+	// rather than reporting their result from a previous run
+	checkResult.Reset()
+	for _, check := range checks {
+		value := 0.0
+		if check.Passed {
+			value = 1
+		}
+		checkResult.WithLabelValues(check.Category, check.Description, check.Severity).Set(value)
+	}
+	checkRunTimestamp.Set(float64(end.Unix()))
+	checkRunDuration.Set(end.Sub(start).Seconds())
+	result := "success"
+	if !success {
+		result = "failure"
+	} else if warning {
+		result = "warning"
+	}
+	checkRuns.WithLabelValues(result).Inc()
+
+	return r.publish(ctx, Status{
+		LastRun:  metav1.NewTime(end),
+		Duration: end.Sub(start).Round(time.Millisecond).String(),
+		Success:  success,
+		Warning:  warning,
+		Checks:   checks,
+	})
+}
+
+// publish writes status to the status ConfigMap, carrying over the
+// transition times of the conditions that didn't change.
+func (r *Runner) publish(ctx context.Context, status Status) error {
+	configMaps := r.client.CoreV1().ConfigMaps(r.namespace)
+	existing, err := configMaps.Get(ctx, StatusConfigMapName, metav1.GetOptions{})
+	notFound := kerrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return err
+	}
+	var previous Status
+	if !notFound {
+		// An unreadable status only loses the previous transition times
+		_ = json.Unmarshal([]byte(existing.Data[StatusKey]), &previous)
+	}
+	status.Conditions = categoryConditions(previous.Conditions, status.Checks, status.LastRun)
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      StatusConfigMapName,
+			Namespace: r.namespace,
+			Labels: map[string]string{
+				pkgK8s.ControllerComponentLabel: "check-runner",
+				pkgK8s.ControllerNSLabel:        r.namespace,
+			},
+		},
+		Data: map[string]string{StatusKey: string(data)},
+	}
+	if notFound {
+		_, err = configMaps.Create(ctx, cm, metav1.CreateOptions{})
+		return err
+	}
+	cm.ResourceVersion = existing.ResourceVersion
+	_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
+
+// categoryConditions updates conditions with the results of checks, one
+// condition per category; the conditions of the categories that didn't run
+// are removed.
+func categoryConditions(conditions []metav1.Condition, checks []CheckStatus, now metav1.Time) []metav1.Condition {
+	var categories []string
+	failures := map[string][]string{}
+	warnings := map[string][]string{}
+	for _, check := range checks {
+		if _, ok := failures[check.Category]; !ok {
+			categories = append(categories, check.Category)
+			failures[check.Category] = nil
+		}
+		if check.Passed {
+			continue
+		}
+		msg := fmt.Sprintf("%s: %s", check.Description, check.Error)
+		if check.Severity == severityWarning {
+			warnings[check.Category] = append(warnings[check.Category], msg)
+		} else {
+			failures[check.Category] = append(failures[check.Category], msg)
+		}
+	}
+
+	updated := []metav1.Condition{}
+	for _, category := range categories {
+		condition := metav1.Condition{
+			Type:               category,
+			Status:             metav1.ConditionTrue,
+			Reason:             reasonPassed,
+			Message:            "all checks passed",
+			LastTransitionTime: now,
+		}
+		if previous := meta.FindStatusCondition(conditions, category); previous != nil {
+			updated = append(updated, *previous)
+		}
+		switch {
+		case len(failures[category]) > 0:
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = reasonFailed
+			condition.Message = strings.Join(append(failures[category], warnings[category]...), "; ")
+		case len(warnings[category]) > 0:
+			condition.Reason = reasonWarning
+			condition.Message = strings.Join(warnings[category], "; ")
+		}
+		meta.SetStatusCondition(&updated, condition)
+	}
+	return updated
+}
@@ -0,0 +1,167 @@
+package checkrunner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeChecker struct {
+	results []healthcheck.CheckResult
+}
+
+func (f *fakeChecker) RunChecks(observer healthcheck.CheckObserver) (bool, bool) {
+	success, warning := true, false
+	for i := range f.results {
+		observer(&f.results[i])
+		if f.results[i].Err != nil && !f.results[i].Retry {
+			if f.results[i].Warning {
+				warning = true
+			} else {
+				success = false
+			}
+		}
+	}
+	return success, warning
+}
+
+func TestRunOnce(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	checker := &fakeChecker{}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := &Runner{
+		client:     client,
+		namespace:  "linkerd",
+		newChecker: func() healthcheck.Runner { return checker },
+		now:        func() time.Time { return now },
+	}
+
+	run := func() Status {
+		t.Helper()
+		if err := r.runOnce(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		cm, err := client.CoreV1().ConfigMaps("linkerd").Get(context.Background(), StatusConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		var status Status
+		if err := json.Unmarshal([]byte(cm.Data[StatusKey]), &status); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		return status
+	}
+
+	checker.results = []healthcheck.CheckResult{
+		{Category: healthcheck.LinkerdIdentity, Description: "certificate config is valid"},
+		{Category: healthcheck.LinkerdIdentity, Description: "issuer cert is valid for at least 60 days", Warning: true,
+			Err: errors.New("issuer certificate will expire on 2026-02-01"), HintURL: "https://linkerd.io/checks/#l5d-identity-issuer-cert-not-expiring-soon"},
+		{Category: healthcheck.LinkerdDataPlaneChecks, Description: "data plane proxies are ready\ndetails"},
+		{Category: healthcheck.LinkerdDataPlaneChecks, Description: "retrying", Retry: true, Err: errors.New("waiting")},
+	}
+	status := run()
+	if !status.Success || !status.Warning {
+		t.Fatalf("Expected a successful run with warnings, got success=%t warning=%t", status.Success, status.Warning)
+	}
+	if len(status.Checks) != 3 {
+		t.Fatalf("Expected 3 checks, got %d", len(status.Checks))
+	}
+	if status.Checks[1].HintURL == "" || status.Checks[0].HintURL != "" {
+		t.Fatalf("Expected only failed checks to have a hint URL, got %+v", status.Checks)
+	}
+	if len(status.Conditions) != 2 {
+		t.Fatalf("Expected 2 conditions, got %+v", status.Conditions)
+	}
+	identity := status.Conditions[0]
+	if identity.Type != string(healthcheck.LinkerdIdentity) || identity.Status != metav1.ConditionTrue || identity.Reason != reasonWarning {
+		t.Fatalf("Unexpected identity condition: %+v", identity)
+	}
+
+	expiring := checkResult.WithLabelValues(string(healthcheck.LinkerdIdentity), "issuer cert is valid for at least 60 days", severityWarning)
+	if v := gaugeValue(t, expiring); v != 0 {
+		t.Fatalf("Expected the expiring issuer check to be reported as failed, got %f", v)
+	}
+	ready := checkResult.WithLabelValues(string(healthcheck.LinkerdDataPlaneChecks), "data plane proxies are ready", severityError)
+	if v := gaugeValue(t, ready); v != 1 {
+		t.Fatalf("Expected the data plane check to be reported as passed, got %f", v)
+	}
+
+	// A failing category transitions, while the unchanged one keeps its
+	// transition time
+	later := now.Add(time.Hour)
+	r.now = func() time.Time { return later }
+	checker.results = []healthcheck.CheckResult{
+		{Category: healthcheck.LinkerdIdentity, Description: "certificate config is valid", Err: errors.New("invalid issuer")},
+		{Category: healthcheck.LinkerdDataPlaneChecks, Description: "data plane proxies are ready"},
+	}
+	status = run()
+	if status.Success {
+		t.Fatal("Expected a failed run")
+	}
+	for _, c := range status.Conditions {
+		switch c.Type {
+		case string(healthcheck.LinkerdIdentity):
+			if c.Status != metav1.ConditionFalse || c.Reason != reasonFailed || !c.LastTransitionTime.Time.Equal(later) {
+				t.Fatalf("Unexpected identity condition: %+v", c)
+			}
+		case string(healthcheck.LinkerdDataPlaneChecks):
+			if !c.LastTransitionTime.Time.Equal(now) {
+				t.Fatalf("Expected the data plane condition to keep its transition time, got %+v", c)
+			}
+		}
+	}
+	series := make(chan prometheus.Metric, 10)
+	checkResult.Collect(series)
+	close(series)
+	if n := len(series); n != 2 {
+		t.Fatalf("Expected the results of the last run only, got %d series", n)
+	}
+}
+
+func gaugeValue(t *testing.T, g prometheus.Gauge) float64 {
+	t.Helper()
+	m := &dto.Metric{}
+	if err := g.Write(m); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return m.GetGauge().GetValue()
+}
+
+func TestDefaultCategories(t *testing.T) {
+	r := NewRunner(fake.NewSimpleClientset(), "linkerd", time.Minute, DefaultCategories, &healthcheck.Options{})
+	hc, ok := r.newChecker().(*healthcheck.HealthChecker)
+	if !ok {
+		t.Fatalf("Expected a *healthcheck.HealthChecker, got %T", r.newChecker())
+	}
+
+	var enabled []healthcheck.CategoryID
+	for _, c := range hc.GetCategories() {
+		if c.Enabled() {
+			enabled = append(enabled, c.ID)
+		}
+	}
+	expected := []healthcheck.CategoryID{
+		healthcheck.KubernetesAPIChecks,
+		healthcheck.LinkerdControlPlaneExistenceChecks,
+		healthcheck.LinkerdIdentity,
+		healthcheck.LinkerdWebhooksAndAPISvcTLS,
+		healthcheck.LinkerdDataPlaneChecks,
+		healthcheck.LinkerdExtensionChecks,
+	}
+	if len(enabled) != len(expected) {
+		t.Fatalf("Expected categories %v to be registered, got %v", expected, enabled)
+	}
+	for i := range expected {
+		if enabled[i] != expected[i] {
+			t.Fatalf("Expected categories %v to be registered, got %v", expected, enabled)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package checkrunner
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/checkrunner"
+	"github.com/linkerd/linkerd2/pkg/admin"
+	"github.com/linkerd/linkerd2/pkg/flags"
+	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+)
+
+// Main executes the check-runner subcommand
+func Main(args []string) {
+	cmd := flag.NewFlagSet("check-runner", flag.ExitOnError)
+
+	adminAddr := cmd.String("admin-addr", fmt.Sprintf(":%d", 9997), "address of HTTP admin server")
+	kubeConfigPath := cmd.String("kubeconfig", "", "path to kube config")
+	controllerNamespace := cmd.String("controller-namespace", "linkerd", "namespace in which Linkerd is installed")
+	cniNamespace := cmd.String("cni-namespace", "linkerd-cni", "namespace in which the Linkerd CNI plugin is installed")
+	interval := cmd.Duration("interval", 5*time.Minute, "time between two runs of the checks")
+	categories := cmd.String("categories", "", "comma-separated list of the check categories to run; defaults to the certificate, data plane and extension namespace checks")
+	enablePprof := cmd.Bool("enable-pprof", false, "Enable pprof endpoints on the admin server")
+
+	flags.ConfigureAndParse(cmd, args)
+
+	categoryIDs := checkrunner.DefaultCategories
+	if *categories != "" {
+		categoryIDs = nil
+		for _, c := range strings.Split(*categories, ",") {
+			categoryIDs = append(categoryIDs, healthcheck.CategoryID(strings.TrimSpace(c)))
+		}
+	}
+
+	k8sAPI, err := k8s.NewAPI(*kubeConfigPath, "", "", []string{}, 0)
+	if err != nil {
+		log.Fatalf("Failed to initialize k8s API: %s", err)
+	}
+
+	ready := true
+	adminServer := admin.NewServer(*adminAddr, *enablePprof, &ready)
+	go func() {
+		log.Infof("starting admin server on %s", *adminAddr)
+		if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("failed to start check-runner admin server: %s", err)
+		}
+	}()
+
+	runner := checkrunner.NewRunner(k8sAPI, *controllerNamespace, *interval, categoryIDs, &healthcheck.Options{
+		ControlPlaneNamespace: *controllerNamespace,
+		CNINamespace:          *cniNamespace,
+		KubeConfig:            *kubeConfigPath,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go runner.Run(ctx)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	log.Info("shutting down")
+	cancel()
+	adminServer.Shutdown(context.Background())
+}
@@ -0,0 +1,94 @@
+package connectivityprobe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/connectivityprobe"
+	"github.com/linkerd/linkerd2/pkg/flags"
+	log "github.com/sirupsen/logrus"
+)
+
+// Main executes the connectivity-probe subcommand
+func Main(args []string) {
+	cmd := flag.NewFlagSet("connectivity-probe", flag.ExitOnError)
+
+	mode := cmd.String("mode", "server", "whether to run the probe as a server or a client")
+	httpAddr := cmd.String("http-addr", fmt.Sprintf(":%d", connectivityprobe.HTTPPort), "address on which the server answers HTTP requests")
+	tcpAddr := cmd.String("tcp-addr", fmt.Sprintf(":%d", connectivityprobe.TCPPort), "address on which the server echoes TCP connections")
+	serverHTTPAddr := cmd.String("server-http-addr", "", "address of the HTTP port of the server, requested by the client")
+	serverTCPAddr := cmd.String("server-tcp-addr", "", "address of the TCP port of the server, requested by the client if set")
+	expectDenied := cmd.Bool("expect-denied", false, "require the requests of the client to be denied")
+	timeout := cmd.Duration("timeout", 2*time.Minute, "time after which the client stops retrying failed requests")
+	resultPath := cmd.String("result-path", "/dev/termination-log", "path to which the client writes its result")
+
+	flags.ConfigureAndParse(cmd, args)
+
+	switch *mode {
+	case "server":
+		runServer(*httpAddr, *tcpAddr)
+	case "client":
+		if *serverHTTPAddr == "" {
+			log.Fatal("-server-http-addr is required in client mode")
+		}
+		runClient(*resultPath, connectivityprobe.ClientConfig{
+			HTTPAddr:     *serverHTTPAddr,
+			TCPAddr:      *serverTCPAddr,
+			ExpectDenied: *expectDenied,
+			Timeout:      *timeout,
+		})
+	default:
+		log.Fatalf("unknown mode: %s", *mode)
+	}
+}
+
+func runServer(httpAddr, tcpAddr string) {
+	listener, err := net.Listen("tcp", tcpAddr)
+	if err != nil {
+		log.Fatalf("failed to listen on %s: %s", tcpAddr, err)
+	}
+	go func() {
+		log.Infof("echoing TCP connections on %s", tcpAddr)
+		if err := connectivityprobe.ServeTCP(listener); err != nil && !errors.Is(err, net.ErrClosed) {
+			log.Errorf("failed to serve TCP: %s", err)
+		}
+	}()
+
+	server := connectivityprobe.NewServer(httpAddr)
+	go func() {
+		log.Infof("answering HTTP requests on %s", httpAddr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("failed to serve HTTP: %s", err)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	log.Info("shutting down")
+	listener.Close()
+	server.Shutdown(context.Background())
+}
+
+func runClient(resultPath string, config connectivityprobe.ClientConfig) {
+	result := connectivityprobe.RunClient(context.Background(), config)
+	out, err := json.Marshal(result)
+	if err != nil {
+		log.Fatalf("failed to encode result: %s", err)
+	}
+	fmt.Println(string(out))
+	// The result is read from the termination message of the container
+	if err := os.WriteFile(resultPath, out, 0600); err != nil {
+		log.Fatalf("failed to write result to %s: %s", resultPath, err)
+	}
+}
@@ -2,9 +2,11 @@ package identity
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -12,6 +14,7 @@ import (
 	"time"
 
 	idctl "github.com/linkerd/linkerd2/controller/identity"
+	controllerK8s "github.com/linkerd/linkerd2/controller/k8s"
 	"github.com/linkerd/linkerd2/pkg/admin"
 	"github.com/linkerd/linkerd2/pkg/flags"
 	"github.com/linkerd/linkerd2/pkg/identity"
@@ -40,9 +43,18 @@ func Main(args []string) {
 	trustDomain := cmd.String("identity-trust-domain", "", "configures the name suffix used for identities")
 	identityIssuanceLifeTime := cmd.String("identity-issuance-lifetime", "", "the amount of time for which the Identity issuer should certify identity")
 	identityClockSkewAllowance := cmd.String("identity-clock-skew-allowance", "", "the amount of time to allow for clock skew within a Linkerd cluster")
+	lifetimeAnnotations := cmd.Bool("enable-issuance-lifetime-annotations", false, "Allow Namespaces and ServiceAccounts to override the issuance lifetime through annotations")
+	minIssuanceLifetime := cmd.Duration("identity-issuance-lifetime-min", time.Hour, "The minimum issuance lifetime that can be set through annotations")
+	maxIssuanceLifetime := cmd.Duration("identity-issuance-lifetime-max", 0, "The maximum issuance lifetime that can be set through annotations (defaults to identity-issuance-lifetime)")
 	enablePprof := cmd.Bool("enable-pprof", false, "Enable pprof endpoints on the admin server")
 	qps := cmd.Float64("kube-apiclient-qps", 100, "Maximum QPS sent to the kube-apiserver before throttling")
 	burst := cmd.Int("kube-apiclient-burst", 200, "Burst value over kube-apiclient-qps")
+	spiffeURISAN := cmd.Bool("enable-spiffe-uri-san", false, "Include a SPIFFE ID URI SAN in issued certificates")
+	attestationIssuer := cmd.String("attestation-issuer", "", "Issuer of signed attestation documents accepted from ExternalWorkloads (disabled when empty)")
+	attestationAudience := cmd.String("attestation-audience", idctl.LinkerdAudienceKey, "Audience required in attestation documents")
+	attestationSubjectClaim := cmd.String("attestation-subject-claim", idctl.DefaultAttestationSubjectClaim, "Attestation document claim matched against ExternalWorkload annotations")
+	attestationJWKS := cmd.String("attestation-jwks", "", "Path to a JWKS file with the keys used to verify attestation documents")
+	spiffeBundleAddr := cmd.String("spiffe-bundle-addr", "", "Address on which to serve the trust anchors as a SPIFFE bundle (disabled when empty)")
 
 	issuerPath := cmd.String("issuer",
 		"/var/run/linkerd/identity/issuer",
@@ -121,6 +133,15 @@ func Main(args []string) {
 			validity.Lifetime = il
 		}
 	}
+	if *lifetimeAnnotations {
+		if *maxIssuanceLifetime == 0 {
+			*maxIssuanceLifetime = validity.Lifetime
+		}
+		if err := idctl.ValidateLifetimeBounds(*minIssuanceLifetime, *maxIssuanceLifetime); err != nil {
+			//nolint:gocritic
+			log.Fatalf("Invalid issuance lifetime bounds: %s", err)
+		}
+	}
 
 	expectedName := fmt.Sprintf("identity.%s.%s", *controllerNS, *trustDomain)
 	issuerEvent := make(chan struct{})
@@ -150,11 +171,40 @@ func Main(args []string) {
 	}
 	log.Infof("Using k8s client with QPS=%.2f Burst=%d", config.QPS, config.Burst)
 
-	v, err := idctl.NewK8sTokenValidator(ctx, k8sAPI, dom)
+	var v identity.Validator
+	v, err = idctl.NewK8sTokenValidator(ctx, k8sAPI, dom)
 	if err != nil {
 		log.Fatalf("Failed to initialize identity service: %s", err)
 	}
 
+	if *attestationIssuer != "" {
+		jwks, err := os.ReadFile(filepath.Clean(*attestationJWKS))
+		if err != nil {
+			log.Fatalf("Failed to read attestation JWKS: %s", err)
+		}
+		ewAPI, err := controllerK8s.InitializeAPIForConfig(ctx, config, false, "local", controllerK8s.ExtWorkload)
+		if err != nil {
+			log.Fatalf("Failed to initialize ExternalWorkload API: %s", err)
+		}
+		if err := idctl.AddAttestationSubjectIndex(ewAPI.ExtWorkload().Informer()); err != nil {
+			log.Fatalf("Failed to initialize ExternalWorkload API: %s", err)
+		}
+		v, err = idctl.NewAttestationValidator(
+			*attestationIssuer,
+			*attestationAudience,
+			*attestationSubjectClaim,
+			jwks,
+			dom,
+			ewAPI.ExtWorkload().Informer().GetIndexer(),
+			v,
+		)
+		if err != nil {
+			log.Fatalf("Failed to initialize attestation validator: %s", err)
+		}
+		ewAPI.Sync(nil)
+		log.Infof("Accepting ExternalWorkload attestations from %s", *attestationIssuer)
+	}
+
 	// Create K8s event recorder
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{
@@ -182,6 +232,21 @@ func Main(args []string) {
 	// Create, initialize and run service
 	//
 	svc := identity.NewService(v, trustAnchors, &validity, recordEventFunc, expectedName, issuerPathCrt, issuerPathKey)
+	if *spiffeURISAN {
+		svc.EnableSpiffeURISAN(*trustDomain)
+	}
+	if *lifetimeAnnotations {
+		metadataAPI, err := controllerK8s.InitializeMetadataAPIForConfig(config, "local", controllerK8s.NS, controllerK8s.SA)
+		if err != nil {
+			log.Fatalf("Failed to initialize metadata API: %s", err)
+		}
+		policy, err := idctl.NewAnnotationLifetimePolicy(metadataAPI, *minIssuanceLifetime, *maxIssuanceLifetime)
+		if err != nil {
+			log.Fatalf("Invalid issuance lifetime bounds: %s", err)
+		}
+		metadataAPI.Sync(nil)
+		svc.SetLifetimePolicy(policy)
+	}
 	if err = svc.Initialize(); err != nil {
 		//nolint:gocritic
 		log.Fatalf("Failed to initialize identity service: %s", err)
@@ -190,6 +255,20 @@ func Main(args []string) {
 		svc.Run(issuerEvent, issuerError)
 	}()
 
+	// Publish the active issuer so that divergence between replicas can be
+	// detected, and stop signing with an issuer a quorum has moved on from.
+	publisherDone := make(chan struct{})
+	if hostname, ok := os.LookupEnv("HOSTNAME"); ok {
+		publisher := idctl.NewIssuerStatusPublisher(k8sAPI, *controllerNS, hostname, svc, idctl.DefaultIssuerStatusLeaseDuration)
+		go func() {
+			publisher.Run(ctx)
+			close(publisherDone)
+		}()
+	} else {
+		log.Warn("HOSTNAME not set; not publishing the issuer status")
+		close(publisherDone)
+	}
+
 	//
 	// Bind and serve
 	//
@@ -213,10 +292,43 @@ func Main(args []string) {
 		}
 	}()
 
+	var bundleServer *http.Server
+	if *spiffeBundleAddr != "" {
+		anchors, err := tls.DecodePEMCertificates(string(identityTrustAnchorPEM))
+		if err != nil {
+			log.Fatalf("Failed to read trust anchors: %s", err)
+		}
+		handler, err := identity.NewBundleHandler(anchors, identity.DefaultBundleRefreshHint)
+		if err != nil {
+			log.Fatalf("Failed to build SPIFFE bundle: %s", err)
+		}
+		bundleServer = &http.Server{
+			Addr:              *spiffeBundleAddr,
+			Handler:           handler,
+			ReadHeaderTimeout: 15 * time.Second,
+		}
+		go func() {
+			log.Infof("starting SPIFFE bundle server on %s", *spiffeBundleAddr)
+			if err := bundleServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Errorf("failed to start SPIFFE bundle server: %s", err)
+			}
+		}()
+	}
+
 	ready = true
 
 	<-stop
 	log.Infof("shutting down gRPC server on %s", *addr)
 	srv.GracefulStop()
-	adminServer.Shutdown(ctx)
+	// ctx is only canceled once the HTTP servers are shut down, so that they
+	// get a chance to close their connections gracefully.
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	if bundleServer != nil {
+		bundleServer.Shutdown(shutdownCtx)
+	}
+	adminServer.Shutdown(shutdownCtx)
+	cancel()
+	// Wait for the publisher to delete its Lease before exiting.
+	<-publisherDone
 }
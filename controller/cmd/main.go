@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 
+	checkrunner "github.com/linkerd/linkerd2/controller/cmd/check-runner"
+	connectivityprobe "github.com/linkerd/linkerd2/controller/cmd/connectivity-probe"
 	"github.com/linkerd/linkerd2/controller/cmd/destination"
 	"github.com/linkerd/linkerd2/controller/cmd/heartbeat"
 	"github.com/linkerd/linkerd2/controller/cmd/identity"
@@ -19,6 +21,10 @@ func main() {
 	}
 
 	switch os.Args[1] {
+	case "check-runner":
+		checkrunner.Main(os.Args[2:])
+	case "connectivity-probe":
+		connectivityprobe.Main(os.Args[2:])
 	case "destination":
 		destination.Main(os.Args[2:])
 	case "heartbeat":
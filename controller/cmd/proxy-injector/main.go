@@ -5,11 +5,13 @@ import (
 	"flag"
 	"fmt"
 
+	pclisters "github.com/linkerd/linkerd2/controller/gen/client/listers/proxyconfig/v1alpha1"
 	"github.com/linkerd/linkerd2/controller/k8s"
 	injector "github.com/linkerd/linkerd2/controller/proxy-injector"
 	"github.com/linkerd/linkerd2/controller/webhook"
 	"github.com/linkerd/linkerd2/pkg/flags"
 	"github.com/linkerd/linkerd2/pkg/inject"
+	log "github.com/sirupsen/logrus"
 )
 
 // Main executes the proxy-injector subcommand
@@ -20,16 +22,46 @@ func Main(args []string) {
 	kubeconfig := cmd.String("kubeconfig", "", "path to kubeconfig")
 	linkerdNamespace := cmd.String("linkerd-namespace", "linkerd", "control plane namespace")
 	enablePprof := cmd.Bool("enable-pprof", false, "Enable pprof endpoints on the admin server")
+	enforceMeshingClusterWide := cmd.Bool("enforce-meshing-cluster-wide", false,
+		"Deny non-compliant pods in every namespace not labeled with config.linkerd.io/enforce-meshing=false, instead of only those labeled with config.linkerd.io/enforce-meshing=true")
+	enableDryRun := cmd.Bool("enable-dry-run", false,
+		"Serve the dry-run endpoint used by 'linkerd inject --server-side'")
 	flags.ConfigureAndParse(cmd, args)
 
+	ctx := context.Background()
+
+	// ProxyConfigs are optional: when the CRD is not installed, or not
+	// accessible, only annotations are taken into account.
+	var proxyConfigs pclisters.ProxyConfigLister
+	pcAPI, err := k8s.InitializeAPI(ctx, *kubeconfig, false, "local", k8s.ProxyConfig)
+	if err != nil {
+		log.Warnf("ProxyConfigs are disabled: %s", err)
+	} else {
+		pcAPI.Sync(nil)
+		proxyConfigs = pcAPI.ProxyConfig().Lister()
+	}
+
+	// Likewise, proxy versions are only rolled out gradually when the
+	// ProxyRollout CRD is available.
+	var proxyRollouts pclisters.ProxyRolloutLister
+	prAPI, err := k8s.InitializeAPI(ctx, *kubeconfig, false, "local", k8s.ProxyRollout)
+	if err != nil {
+		log.Warnf("ProxyRollouts are disabled: %s", err)
+	} else {
+		prAPI.Sync(nil)
+		proxyRollouts = prAPI.ProxyRollout().Lister()
+	}
+
 	webhook.Launch(
-		context.Background(),
+		ctx,
 		[]k8s.APIResource{k8s.NS, k8s.Deploy, k8s.RC, k8s.RS, k8s.Job, k8s.DS, k8s.SS, k8s.Pod, k8s.CJ},
-		injector.Inject(*linkerdNamespace, inject.GetOverriddenValues),
+		injector.Inject(*linkerdNamespace, inject.GetOverriddenValues, proxyConfigs, proxyRollouts),
 		"linkerd-proxy-injector",
 		*metricsAddr,
 		*addr,
 		*kubeconfig,
 		*enablePprof,
+		*enableDryRun,
+		injector.Validate(*linkerdNamespace, *enforceMeshingClusterWide),
 	)
 }
@@ -28,5 +28,7 @@ func Main(args []string) {
 		*addr,
 		*kubeconfig,
 		*enablePprof,
+		false,
+		nil,
 	)
 }
@@ -0,0 +1,221 @@
+package connectivityprobe
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// HTTPPort is the port on which the server answers HTTP requests
+	HTTPPort = 8080
+
+	// TCPPort is the port on which the server echoes TCP connections; it's
+	// marked as opaque, so that its traffic is proxied as TCP
+	TCPPort = 8081
+
+	// clientIDHeader is set by the inbound proxy of the server on requests
+	// from meshed clients, to their identity
+	clientIDHeader = "l5d-client-id"
+
+	// tcpPayload is sent by the client, and echoed by the server, over TCP
+	tcpPayload = "linkerd-connectivity-probe\n"
+
+	retryInterval = time.Second
+)
+
+type (
+	// Result is the outcome of a run of the client, written to its termination
+	// message.
+	Result struct {
+		HTTP *Outcome `json:"http,omitempty"`
+		TCP  *Outcome `json:"tcp,omitempty"`
+	}
+
+	// Outcome is the outcome of a request.
+	Outcome struct {
+		// Error is empty if the request succeeded, or was denied when a
+		// denial was expected
+		Error string `json:"error,omitempty"`
+		// ClientID is the identity of the client, as seen by the server
+		ClientID string `json:"clientID,omitempty"`
+	}
+
+	// ClientConfig configures a run of the client.
+	ClientConfig struct {
+		// HTTPAddr is the address of the HTTP port of the server
+		HTTPAddr string
+		// TCPAddr is the address of the TCP port of the server; the TCP
+		// request is skipped if it's empty
+		TCPAddr string
+		// ExpectDenied requires the requests to be denied by the server
+		ExpectDenied bool
+		// Timeout is the time after which failing requests stop being retried
+		Timeout time.Duration
+	}
+
+	httpResponse struct {
+		ClientID string `json:"clientID"`
+	}
+
+	// deniedError is returned when the server, or its proxy, denies a
+	// request: with a 403 response for HTTP, or by closing or resetting the
+	// connection once established for TCP
+	deniedError struct {
+		err error
+	}
+)
+
+func (e deniedError) Error() string {
+	return e.err.Error()
+}
+
+func (e deniedError) Unwrap() error {
+	return e.err
+}
+
+// NewServer returns the HTTP server of the probe, answering with the
+// identity of the client.
+func NewServer(addr string) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(httpResponse{ClientID: r.Header.Get(clientIDHeader)}); err != nil {
+				log.Errorf("failed to write response: %s", err)
+			}
+		}),
+	}
+}
+
+// ServeTCP echoes the connections accepted by listener, until it's closed.
+func ServeTCP(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			if _, err := io.Copy(conn, conn); err != nil {
+				log.Debugf("failed to echo connection from %s: %s", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// RunClient sends an HTTP request, and optionally a TCP request, to the
+// server, retrying failed requests until the timeout.
+func RunClient(ctx context.Context, config ClientConfig) Result {
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	result := Result{HTTP: retry(ctx, config.ExpectDenied, func() (*Outcome, error) {
+		return requestHTTP(ctx, config.HTTPAddr)
+	})}
+	if config.TCPAddr != "" {
+		result.TCP = retry(ctx, config.ExpectDenied, func() (*Outcome, error) {
+			return &Outcome{}, requestTCP(ctx, config.TCPAddr)
+		})
+	}
+	return result
+}
+
+// retry calls request until it succeeds, or is denied when expectDenied is
+// set, or ctx is done. Other failures, such as network errors, are retried.
+func retry(ctx context.Context, expectDenied bool, request func() (*Outcome, error)) *Outcome {
+	for {
+		outcome, err := request()
+		if expectDenied {
+			var denied deniedError
+			if errors.As(err, &denied) {
+				log.Infof("request denied: %s", err)
+				return &Outcome{}
+			}
+			if err == nil {
+				return &Outcome{Error: "request wasn't denied", ClientID: outcome.ClientID}
+			}
+		} else if err == nil {
+			return outcome
+		}
+
+		log.Infof("request failed: %s", err)
+		select {
+		case <-ctx.Done():
+			return &Outcome{Error: err.Error()}
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+func requestHTTP(ctx context.Context, addr string) (*Outcome, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s/", addr), nil)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusForbidden {
+		return nil, deniedError{fmt.Errorf("unexpected status: %s", rsp.Status)}
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", rsp.Status)
+	}
+
+	var body httpResponse
+	if err := json.NewDecoder(rsp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return &Outcome{ClientID: body.ClientID}, nil
+}
+
+func requestTCP(ctx context.Context, addr string) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	// The proxy of the server denies connections by closing or resetting
+	// them once they're established
+	if _, err := io.WriteString(conn, tcpPayload); err != nil {
+		if errors.Is(err, syscall.ECONNRESET) {
+			return deniedError{err}
+		}
+		return err
+	}
+	echo, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return deniedError{errors.New("connection closed by the server")}
+		}
+		if errors.Is(err, syscall.ECONNRESET) {
+			return deniedError{err}
+		}
+		return err
+	}
+	if echo != tcpPayload {
+		return fmt.Errorf("unexpected echo: %q", strings.TrimSpace(echo))
+	}
+	return nil
+}
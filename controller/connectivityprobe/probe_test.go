@@ -0,0 +1,152 @@
+package connectivityprobe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+func TestRunClient(t *testing.T) {
+	// The inbound proxy of a meshed server sets the identity of the client
+	proxied := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set(clientIDHeader, "default.emojivoto.serviceaccount.identity.linkerd.cluster.local")
+		NewServer("").Handler.ServeHTTP(w, r)
+	}))
+	defer proxied.Close()
+
+	denied := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer denied.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer listener.Close()
+	go ServeTCP(listener) //nolint:errcheck
+
+	// The inbound proxy of the server closes unauthorized connections
+	closing, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer closing.Close()
+	go func() {
+		for {
+			conn, err := closing.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	testCases := []struct {
+		description string
+		config      ClientConfig
+		expected    Result
+	}{
+		{
+			description: "reaches the server",
+			config: ClientConfig{
+				HTTPAddr: strings.TrimPrefix(proxied.URL, "http://"),
+				TCPAddr:  listener.Addr().String(),
+			},
+			expected: Result{
+				HTTP: &Outcome{ClientID: "default.emojivoto.serviceaccount.identity.linkerd.cluster.local"},
+				TCP:  &Outcome{},
+			},
+		},
+		{
+			description: "is denied as expected",
+			config: ClientConfig{
+				HTTPAddr:     strings.TrimPrefix(denied.URL, "http://"),
+				ExpectDenied: true,
+			},
+			expected: Result{HTTP: &Outcome{}},
+		},
+		{
+			description: "is denied over TCP as expected",
+			config: ClientConfig{
+				HTTPAddr:     strings.TrimPrefix(denied.URL, "http://"),
+				TCPAddr:      closing.Addr().String(),
+				ExpectDenied: true,
+			},
+			expected: Result{HTTP: &Outcome{}, TCP: &Outcome{}},
+		},
+		{
+			description: "isn't denied",
+			config: ClientConfig{
+				HTTPAddr:     strings.TrimPrefix(proxied.URL, "http://"),
+				ExpectDenied: true,
+			},
+			expected: Result{HTTP: &Outcome{
+				Error:    "request wasn't denied",
+				ClientID: "default.emojivoto.serviceaccount.identity.linkerd.cluster.local",
+			}},
+		},
+		{
+			description: "is denied",
+			config: ClientConfig{
+				HTTPAddr: strings.TrimPrefix(denied.URL, "http://"),
+			},
+			expected: Result{HTTP: &Outcome{Error: "unexpected status: 403 Forbidden"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.description, func(t *testing.T) {
+			tc.config.Timeout = 100 * time.Millisecond
+			result := RunClient(context.Background(), tc.config)
+			if diff := deep.Equal(result, tc.expected); diff != nil {
+				t.Errorf("%+v", diff)
+			}
+		})
+	}
+}
+
+func TestRunClientConnectionRefused(t *testing.T) {
+	denied := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer denied.Close()
+
+	// A port on which nothing listens
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	refused := listener.Addr().String()
+	listener.Close()
+
+	// Network errors aren't denials, and fail the requests once they time out
+	result := RunClient(context.Background(), ClientConfig{
+		HTTPAddr:     refused,
+		ExpectDenied: true,
+		Timeout:      100 * time.Millisecond,
+	})
+	if !strings.Contains(result.HTTP.Error, "connection refused") {
+		t.Errorf("Expected the HTTP request to fail with a connection refused error, got %+v", result.HTTP)
+	}
+
+	result = RunClient(context.Background(), ClientConfig{
+		HTTPAddr:     strings.TrimPrefix(denied.URL, "http://"),
+		TCPAddr:      refused,
+		ExpectDenied: true,
+		Timeout:      100 * time.Millisecond,
+	})
+	if result.HTTP.Error != "" {
+		t.Errorf("Expected the HTTP request to be denied, got %+v", result.HTTP)
+	}
+	if !strings.Contains(result.TCP.Error, "connection refused") {
+		t.Errorf("Expected the TCP request to fail with a connection refused error, got %+v", result.TCP)
+	}
+}
@@ -0,0 +1,4 @@
+package proxyconfig
+
+// GroupName identifies the API Group name for a ProxyConfig
+const GroupName = "config.linkerd.io"
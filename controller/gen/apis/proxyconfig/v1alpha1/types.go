@@ -0,0 +1,195 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +groupName=config.linkerd.io
+
+// ProxyConfig is a typed alternative to the config.linkerd.io annotations. It
+// configures the proxies injected into the pods it selects.
+//
+// Settings are resolved with the following precedence, from lowest to
+// highest: ProxyConfigs in the control plane namespace without a selector
+// (cluster defaults), Namespace annotations, ProxyConfigs in the workload's
+// namespace without a selector, ProxyConfigs in the workload's namespace
+// whose selector matches the pod, and finally the workload's annotations.
+type ProxyConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the proxy configuration and the pods it applies to.
+	Spec ProxyConfigSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ProxyConfigList contains a list of ProxyConfig resources.
+type ProxyConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ProxyConfig `json:"items"`
+}
+
+// ProxyConfigSpec specifies the proxy configuration and the pods it applies
+// to.
+type ProxyConfigSpec struct {
+	// Selector selects the pods in the ProxyConfig's namespace that the
+	// configuration applies to. All pods in the namespace are selected when
+	// it is unset.
+	//
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Proxy holds the proxy settings. Unset fields are inherited from lower
+	// precedence configuration.
+	Proxy ProxySettings `json:"proxy"`
+}
+
+// ProxySettings mirrors the config.linkerd.io proxy annotations.
+type ProxySettings struct {
+	// +optional
+	Image *Image `json:"image,omitempty"`
+
+	// LogLevel is the proxy log level, e.g. "warn,linkerd=info".
+	//
+	// +optional
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// LogFormat is the proxy log format: "plain" or "json".
+	//
+	// +optional
+	LogFormat string `json:"logFormat,omitempty"`
+
+	// +optional
+	Resources *Resources `json:"resources,omitempty"`
+
+	// OpaquePorts lists ports, or ranges of ports, that are proxied without
+	// protocol detection.
+	//
+	// +optional
+	OpaquePorts []string `json:"opaquePorts,omitempty"`
+
+	// SkipInboundPorts lists inbound ports, or ranges of ports, that bypass
+	// the proxy.
+	//
+	// +optional
+	SkipInboundPorts []string `json:"skipInboundPorts,omitempty"`
+
+	// SkipOutboundPorts lists outbound ports, or ranges of ports, that bypass
+	// the proxy.
+	//
+	// +optional
+	SkipOutboundPorts []string `json:"skipOutboundPorts,omitempty"`
+
+	// SkipSubnets lists CIDRs whose traffic bypasses the proxy.
+	//
+	// +optional
+	SkipSubnets []string `json:"skipSubnets,omitempty"`
+
+	// DefaultInboundPolicy is the policy applied to inbound connections
+	// when no Server selects the port.
+	//
+	// +optional
+	DefaultInboundPolicy string `json:"defaultInboundPolicy,omitempty"`
+
+	// AccessLog enables access logging in the given format: "apache" or
+	// "json".
+	//
+	// +optional
+	AccessLog string `json:"accessLog,omitempty"`
+
+	// ShutdownGracePeriod is the maximum time the proxy waits for
+	// connections to close on shutdown.
+	//
+	// +optional
+	ShutdownGracePeriod *metav1.Duration `json:"shutdownGracePeriod,omitempty"`
+
+	// EnableNativeSidecar runs the proxy as a native sidecar container.
+	//
+	// +optional
+	EnableNativeSidecar *bool `json:"enableNativeSidecar,omitempty"`
+
+	// AdditionalEnv is added to the proxy container's environment.
+	//
+	// +optional
+	AdditionalEnv []corev1.EnvVar `json:"additionalEnv,omitempty"`
+}
+
+// Image specifies the proxy image.
+type Image struct {
+	// +optional
+	Name string `json:"name,omitempty"`
+	// +optional
+	Version string `json:"version,omitempty"`
+	// +optional
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty"`
+}
+
+// Resources specifies the proxy container's resource requests and limits.
+type Resources struct {
+	// +optional
+	CPU *ResourceBounds `json:"cpu,omitempty"`
+	// +optional
+	Memory *ResourceBounds `json:"memory,omitempty"`
+	// +optional
+	EphemeralStorage *ResourceBounds `json:"ephemeralStorage,omitempty"`
+}
+
+// ResourceBounds holds the request and limit for a single resource.
+type ResourceBounds struct {
+	// +optional
+	Request *resource.Quantity `json:"request,omitempty"`
+	// +optional
+	Limit *resource.Quantity `json:"limit,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ProxyRollout gradually rolls a canary proxy version out to the workloads
+// of the namespaces it selects. Workloads are assigned to the canary
+// deterministically, so that all the pods of a workload run the same proxy
+// version. ProxyRollouts are only honored in the control plane namespace.
+type ProxyRollout struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the canary version and the workloads it applies to.
+	Spec ProxyRolloutSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ProxyRolloutList contains a list of ProxyRollout resources.
+type ProxyRolloutList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ProxyRollout `json:"items"`
+}
+
+// ProxyRolloutSpec specifies the canary proxy version and the workloads it
+// applies to.
+type ProxyRolloutSpec struct {
+	// Version is the canary proxy version.
+	Version string `json:"version"`
+
+	// Percentage is the share of workloads, from 0 to 100, whose pods get
+	// the canary version when injected. The other workloads keep the stable
+	// version.
+	Percentage int32 `json:"percentage"`
+
+	// NamespaceSelector selects the namespaces whose workloads take part in
+	// the rollout. All namespaces are selected when it is unset.
+	//
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
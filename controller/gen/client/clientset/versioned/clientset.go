@@ -28,6 +28,7 @@ import (
 	linkv1alpha3 "github.com/linkerd/linkerd2/controller/gen/client/clientset/versioned/typed/link/v1alpha3"
 	policyv1alpha1 "github.com/linkerd/linkerd2/controller/gen/client/clientset/versioned/typed/policy/v1alpha1"
 	policyv1beta3 "github.com/linkerd/linkerd2/controller/gen/client/clientset/versioned/typed/policy/v1beta3"
+	proxyconfigv1alpha1 "github.com/linkerd/linkerd2/controller/gen/client/clientset/versioned/typed/proxyconfig/v1alpha1"
 	serverv1beta1 "github.com/linkerd/linkerd2/controller/gen/client/clientset/versioned/typed/server/v1beta1"
 	serverv1beta2 "github.com/linkerd/linkerd2/controller/gen/client/clientset/versioned/typed/server/v1beta2"
 	serverv1beta3 "github.com/linkerd/linkerd2/controller/gen/client/clientset/versioned/typed/server/v1beta3"
@@ -46,6 +47,7 @@ type Interface interface {
 	LinkV1alpha3() linkv1alpha3.LinkV1alpha3Interface
 	PolicyV1alpha1() policyv1alpha1.PolicyV1alpha1Interface
 	PolicyV1beta3() policyv1beta3.PolicyV1beta3Interface
+	ProxyconfigV1alpha1() proxyconfigv1alpha1.ProxyconfigV1alpha1Interface
 	ServerV1beta1() serverv1beta1.ServerV1beta1Interface
 	ServerV1beta2() serverv1beta2.ServerV1beta2Interface
 	ServerV1beta3() serverv1beta3.ServerV1beta3Interface
@@ -62,6 +64,7 @@ type Clientset struct {
 	linkV1alpha3               *linkv1alpha3.LinkV1alpha3Client
 	policyV1alpha1             *policyv1alpha1.PolicyV1alpha1Client
 	policyV1beta3              *policyv1beta3.PolicyV1beta3Client
+	proxyconfigV1alpha1        *proxyconfigv1alpha1.ProxyconfigV1alpha1Client
 	serverV1beta1              *serverv1beta1.ServerV1beta1Client
 	serverV1beta2              *serverv1beta2.ServerV1beta2Client
 	serverV1beta3              *serverv1beta3.ServerV1beta3Client
@@ -99,6 +102,11 @@ func (c *Clientset) PolicyV1beta3() policyv1beta3.PolicyV1beta3Interface {
 	return c.policyV1beta3
 }
 
+// ProxyconfigV1alpha1 retrieves the ProxyconfigV1alpha1Client
+func (c *Clientset) ProxyconfigV1alpha1() proxyconfigv1alpha1.ProxyconfigV1alpha1Interface {
+	return c.proxyconfigV1alpha1
+}
+
 // ServerV1beta1 retrieves the ServerV1beta1Client
 func (c *Clientset) ServerV1beta1() serverv1beta1.ServerV1beta1Interface {
 	return c.serverV1beta1
@@ -192,6 +200,10 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.proxyconfigV1alpha1, err = proxyconfigv1alpha1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 	cs.serverV1beta1, err = serverv1beta1.NewForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
 		return nil, err
@@ -239,6 +251,7 @@ func New(c rest.Interface) *Clientset {
 	cs.linkV1alpha3 = linkv1alpha3.New(c)
 	cs.policyV1alpha1 = policyv1alpha1.New(c)
 	cs.policyV1beta3 = policyv1beta3.New(c)
+	cs.proxyconfigV1alpha1 = proxyconfigv1alpha1.New(c)
 	cs.serverV1beta1 = serverv1beta1.New(c)
 	cs.serverV1beta2 = serverv1beta2.New(c)
 	cs.serverV1beta3 = serverv1beta3.New(c)
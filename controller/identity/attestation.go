@@ -0,0 +1,270 @@
+package identity
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	ewv1beta1 "github.com/linkerd/linkerd2/controller/gen/apis/externalworkload/v1beta1"
+	"github.com/linkerd/linkerd2/pkg/identity"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// AttestationSubjectIndex is the name of the ExternalWorkload informer
+	// index keyed by the IdentityAttestationSubjectAnnotation value.
+	AttestationSubjectIndex = "attestationSubject"
+
+	// DefaultAttestationSubjectClaim is the claim used to look up the
+	// ExternalWorkload bound to an attestation document.
+	DefaultAttestationSubjectClaim = "sub"
+)
+
+// AttestationValidator implements Validator for signed attestation documents
+// (JWTs) presented by ExternalWorkloads. Documents must be signed by one of
+// the keys in a locally configured JWKS and carry the configured issuer and
+// audience. The subject claim is mapped to the ExternalWorkload annotated
+// with the same IdentityAttestationSubjectAnnotation value, and the identity
+// declared in that workload's spec.meshTLS.identity is returned. That identity
+// must be the one of a ServiceAccount of the workload's namespace, in the trust
+// domain, so that ExternalWorkloads can't impersonate workloads of other
+// namespaces, and ExternalWorkloads can't be attested in the control plane
+// namespace.
+//
+// Tokens issued by any other issuer are handed to the next Validator, so that
+// proxies running in Kubernetes keep using ServiceAccount tokens.
+type AttestationValidator struct {
+	issuer, audience, subjectClaim string
+	keys                           map[string]crypto.PublicKey
+	domain                         *TrustDomain
+	workloads                      cache.Indexer
+	next                           identity.Validator
+}
+
+// NewAttestationValidator creates an AttestationValidator. The workloads
+// indexer must have been configured with AddAttestationSubjectIndex.
+func NewAttestationValidator(
+	issuer, audience, subjectClaim string,
+	jwks []byte,
+	domain *TrustDomain,
+	workloads cache.Indexer,
+	next identity.Validator,
+) (*AttestationValidator, error) {
+	if issuer == "" {
+		return nil, errors.New("attestation issuer must be provided")
+	}
+	if domain == nil {
+		return nil, errors.New("trust domain must be provided")
+	}
+	if subjectClaim == "" {
+		subjectClaim = DefaultAttestationSubjectClaim
+	}
+	keys, err := ParseJWKS(jwks)
+	if err != nil {
+		return nil, err
+	}
+	return &AttestationValidator{issuer, audience, subjectClaim, keys, domain, workloads, next}, nil
+}
+
+// AddAttestationSubjectIndex registers AttestationSubjectIndex on the provided
+// ExternalWorkload informer.
+func AddAttestationSubjectIndex(informer cache.SharedIndexInformer) error {
+	err := informer.AddIndexers(cache.Indexers{AttestationSubjectIndex: attestationSubjectIndexFunc})
+	if err != nil {
+		return fmt.Errorf("could not create an indexer for externalworkloads: %w", err)
+	}
+	return nil
+}
+
+func attestationSubjectIndexFunc(obj interface{}) ([]string, error) {
+	ew, ok := obj.(*ewv1beta1.ExternalWorkload)
+	if !ok {
+		return nil, errors.New("object is not an externalworkload")
+	}
+	if subject, ok := ew.GetAnnotations()[k8s.IdentityAttestationSubjectAnnotation]; ok && subject != "" {
+		return []string{subject}, nil
+	}
+	return nil, nil
+}
+
+// Validate accepts signed attestation documents and returns the DNS-form
+// identity of the ExternalWorkload bound to their subject.
+func (a *AttestationValidator) Validate(ctx context.Context, tok []byte) (string, error) {
+	// Peek at the issuer without verifying the signature, to decide which
+	// validator should handle the token.
+	unverified := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(string(tok), unverified); err != nil || !a.handles(unverified) {
+		if a.next == nil {
+			return "", identity.InvalidToken{Reason: "token was not issued by the attestation issuer"}
+		}
+		return a.next.Validate(ctx, tok)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithIssuer(a.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithValidMethods([]string{"ES256", "ES384", "ES512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}),
+	}
+	if a.audience != "" {
+		opts = append(opts, jwt.WithAudience(a.audience))
+	}
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(string(tok), claims, a.keyFunc, opts...); err != nil {
+		return "", identity.NotAuthenticated{}
+	}
+
+	subject, ok := claims[a.subjectClaim].(string)
+	if !ok || subject == "" {
+		return "", identity.InvalidToken{Reason: fmt.Sprintf("attestation is missing the '%s' claim", a.subjectClaim)}
+	}
+
+	objs, err := a.workloads.ByIndex(AttestationSubjectIndex, subject)
+	if err != nil {
+		return "", fmt.Errorf("failed getting %s indexed externalworkloads: %w", AttestationSubjectIndex, err)
+	}
+	switch len(objs) {
+	case 0:
+		return "", identity.InvalidToken{Reason: fmt.Sprintf("no ExternalWorkload is bound to attestation subject %s", subject)}
+	case 1:
+	default:
+		return "", identity.InvalidToken{Reason: fmt.Sprintf("multiple ExternalWorkloads are bound to attestation subject %s", subject)}
+	}
+
+	ew := objs[0].(*ewv1beta1.ExternalWorkload)
+	id, err := a.workloadIdentity(ew)
+	if err != nil {
+		return "", identity.InvalidToken{Reason: fmt.Sprintf("ExternalWorkload %s/%s has an invalid identity '%s': %s", ew.Namespace, ew.Name, ew.Spec.MeshTLS.Identity, err)}
+	}
+	log.Debugf("Attestation subject %s validated for ExternalWorkload %s/%s", subject, ew.Namespace, ew.Name)
+	return id, nil
+}
+
+// workloadIdentity returns the identity of ew, which must be the identity of a
+// ServiceAccount of its namespace.
+func (a *AttestationValidator) workloadIdentity(ew *ewv1beta1.ExternalWorkload) (string, error) {
+	if ew.Namespace == a.domain.controlNS {
+		return "", errors.New("ExternalWorkloads of the control plane namespace can't be attested")
+	}
+	id := ew.Spec.MeshTLS.Identity
+	sa, _, _ := strings.Cut(id, ".")
+	expected, err := a.domain.Identity("serviceaccount", sa, ew.Namespace)
+	if err != nil {
+		return "", err
+	}
+	if id != expected {
+		return "", fmt.Errorf("expected the identity of a ServiceAccount of namespace %s, like %s", ew.Namespace, expected)
+	}
+	return id, nil
+}
+
+func (a *AttestationValidator) handles(claims jwt.MapClaims) bool {
+	iss, err := claims.GetIssuer()
+	return err == nil && iss == a.issuer
+}
+
+func (a *AttestationValidator) keyFunc(tok *jwt.Token) (interface{}, error) {
+	kid, _ := tok.Header["kid"].(string)
+	if kid != "" {
+		if key, ok := a.keys[kid]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown key ID: %s", kid)
+	}
+	// Without a key ID, try every configured key.
+	keys := jwt.VerificationKeySet{}
+	for _, key := range a.keys {
+		keys.Keys = append(keys.Keys, key)
+	}
+	return keys, nil
+}
+
+// ParseJWKS parses a JSON Web Key Set holding EC or RSA public keys, indexed
+// by key ID. Keys without a `kid` are indexed by their position in the set.
+func ParseJWKS(b []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		kid := k.Kid
+		if kid == "" {
+			kid = fmt.Sprintf("%d", i)
+		}
+
+		switch k.Kty {
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				return nil, fmt.Errorf("unsupported curve for key %s: %s", kid, k.Crv)
+			}
+			x, err := decodeBigInt(k.X)
+			if err != nil {
+				return nil, fmt.Errorf("invalid x coordinate for key %s: %w", kid, err)
+			}
+			y, err := decodeBigInt(k.Y)
+			if err != nil {
+				return nil, fmt.Errorf("invalid y coordinate for key %s: %w", kid, err)
+			}
+			keys[kid] = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		case "RSA":
+			n, err := decodeBigInt(k.N)
+			if err != nil {
+				return nil, fmt.Errorf("invalid modulus for key %s: %w", kid, err)
+			}
+			e, err := decodeBigInt(k.E)
+			if err != nil {
+				return nil, fmt.Errorf("invalid exponent for key %s: %w", kid, err)
+			}
+			keys[kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		default:
+			return nil, fmt.Errorf("unsupported key type for key %s: %s", kid, k.Kty)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS does not contain any signing keys")
+	}
+	return keys, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
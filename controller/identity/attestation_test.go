@@ -0,0 +1,205 @@
+package identity
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	ewv1beta1 "github.com/linkerd/linkerd2/controller/gen/apis/externalworkload/v1beta1"
+	"github.com/linkerd/linkerd2/pkg/identity"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	testIssuer = "https://oidc.example.com"
+	testID     = "vm.external.serviceaccount.identity.linkerd.cluster.local"
+)
+
+type fakeValidator struct{ id string }
+
+func (f fakeValidator) Validate(context.Context, []byte) (string, error) {
+	return f.id, nil
+}
+
+func TestAttestationValidator(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	jwks := fmt.Sprintf(`{"keys":[{"kid":"k1","kty":"EC","use":"sig","crv":"P-256","x":"%s","y":"%s"}]}`,
+		base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	)
+
+	controlPlaneWorkload := externalWorkload("destination", "i-linkerd", "linkerd-destination.linkerd.serviceaccount.identity.linkerd.cluster.local")
+	controlPlaneWorkload.Namespace = "linkerd"
+	workloads := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{AttestationSubjectIndex: attestationSubjectIndexFunc})
+	for _, ew := range []*ewv1beta1.ExternalWorkload{
+		externalWorkload("vm", "i-0123", testID),
+		externalWorkload("dup-1", "i-dup", testID),
+		externalWorkload("dup-2", "i-dup", testID),
+		externalWorkload("other-ns", "i-other-ns", "vm.other.serviceaccount.identity.linkerd.cluster.local"),
+		externalWorkload("control-plane", "i-control-plane", "linkerd-destination.linkerd.serviceaccount.identity.linkerd.cluster.local"),
+		externalWorkload("other-domain", "i-other-domain", "vm.external.serviceaccount.identity.linkerd.example.com"),
+		controlPlaneWorkload,
+	} {
+		if err := workloads.Add(ew); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	domain, err := NewTrustDomain("linkerd", "cluster.local")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	v, err := NewAttestationValidator(testIssuer, LinkerdAudienceKey, "", []byte(jwks), domain, workloads, fakeValidator{"k8s-identity"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	sign := func(claims jwt.MapClaims, kid string, signer *ecdsa.PrivateKey) []byte {
+		tok := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		tok.Header["kid"] = kid
+		s, err := tok.SignedString(signer)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		return []byte(s)
+	}
+	claims := func(iss, sub string, exp time.Time) jwt.MapClaims {
+		return jwt.MapClaims{"iss": iss, "sub": sub, "aud": LinkerdAudienceKey, "exp": exp.Unix()}
+	}
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	future := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		desc        string
+		tok         []byte
+		expectedID  string
+		expectedErr error
+	}{
+		{
+			desc:       "valid attestation",
+			tok:        sign(claims(testIssuer, "i-0123", future), "k1", key),
+			expectedID: testID,
+		},
+		{
+			desc:       "other issuers fall through",
+			tok:        sign(claims("https://kubernetes.default.svc", "system:serviceaccount:ns:sa", future), "k1", otherKey),
+			expectedID: "k8s-identity",
+		},
+		{
+			desc:        "expired attestation",
+			tok:         sign(claims(testIssuer, "i-0123", time.Now().Add(-time.Hour)), "k1", key),
+			expectedErr: identity.NotAuthenticated{},
+		},
+		{
+			desc:        "untrusted key",
+			tok:         sign(claims(testIssuer, "i-0123", future), "k1", otherKey),
+			expectedErr: identity.NotAuthenticated{},
+		},
+		{
+			desc:        "unbound subject",
+			tok:         sign(claims(testIssuer, "i-unknown", future), "k1", key),
+			expectedErr: identity.InvalidToken{},
+		},
+		{
+			desc:        "ambiguous subject",
+			tok:         sign(claims(testIssuer, "i-dup", future), "k1", key),
+			expectedErr: identity.InvalidToken{},
+		},
+		{
+			desc:        "identity of another namespace",
+			tok:         sign(claims(testIssuer, "i-other-ns", future), "k1", key),
+			expectedErr: identity.InvalidToken{},
+		},
+		{
+			desc:        "control plane identity",
+			tok:         sign(claims(testIssuer, "i-control-plane", future), "k1", key),
+			expectedErr: identity.InvalidToken{},
+		},
+		{
+			desc:        "identity of another trust domain",
+			tok:         sign(claims(testIssuer, "i-other-domain", future), "k1", key),
+			expectedErr: identity.InvalidToken{},
+		},
+		{
+			desc:        "workload of the control plane namespace",
+			tok:         sign(claims(testIssuer, "i-linkerd", future), "k1", key),
+			expectedErr: identity.InvalidToken{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			id, err := v.Validate(context.Background(), tc.tok)
+			if tc.expectedErr != nil {
+				switch tc.expectedErr.(type) {
+				case identity.NotAuthenticated:
+					var nae identity.NotAuthenticated
+					if !errors.As(err, &nae) {
+						t.Fatalf("Expected NotAuthenticated, got %v", err)
+					}
+				case identity.InvalidToken:
+					var ite identity.InvalidToken
+					if !errors.As(err, &ite) {
+						t.Fatalf("Expected InvalidToken, got %v", err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if id != tc.expectedID {
+				t.Fatalf("Expected identity %s, got %s", tc.expectedID, id)
+			}
+		})
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	if _, err := ParseJWKS([]byte(`{"keys":[]}`)); err == nil {
+		t.Fatal("Expected error for an empty key set")
+	}
+	if _, err := ParseJWKS([]byte(`{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`)); err == nil {
+		t.Fatal("Expected error for a symmetric key")
+	}
+	keys, err := ParseJWKS([]byte(`{"keys":[{"kty":"RSA","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw","e":"AQAB","use":"enc"},{"kty":"RSA","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw","e":"AQAB","kid":"rsa"}]}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("Expected encryption keys to be skipped, got %d keys", len(keys))
+	}
+	if _, ok := keys["rsa"]; !ok {
+		t.Fatalf("Expected key 'rsa', got %v", keys)
+	}
+}
+
+func externalWorkload(name, subject, id string) *ewv1beta1.ExternalWorkload {
+	return &ewv1beta1.ExternalWorkload{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "external",
+			Annotations: map[string]string{k8s.IdentityAttestationSubjectAnnotation: subject},
+		},
+		Spec: ewv1beta1.ExternalWorkloadSpec{
+			MeshTLS: ewv1beta1.MeshTLS{Identity: id, ServerName: name},
+		},
+	}
+}
@@ -0,0 +1,175 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/identity"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultIssuerStatusLeaseDuration is the default duration after which a
+// replica's issuer status is ignored if it has not been renewed.
+const DefaultIssuerStatusLeaseDuration = 30 * time.Second
+
+type (
+	// IssuerState is implemented by the identity service to expose and
+	// control the issuer it signs with.
+	IssuerState interface {
+		IssuerStatus() (string, time.Time)
+		SuspendSigning(reason string)
+		ResumeSigning()
+	}
+
+	// IssuerStatusPublisher publishes the active issuer of this replica in a
+	// Lease and suspends signing while a quorum of the live replicas has
+	// moved on to a newer issuer. A quorum is a strict majority, so signing
+	// is never suspended with one or two live replicas.
+	IssuerStatusPublisher struct {
+		client        kubernetes.Interface
+		namespace     string
+		hostname      string
+		state         IssuerState
+		leaseDuration time.Duration
+		now           func() time.Time
+	}
+)
+
+// NewIssuerStatusPublisher creates an IssuerStatusPublisher for the replica
+// running in the pod named hostname.
+func NewIssuerStatusPublisher(client kubernetes.Interface, namespace, hostname string, state IssuerState, leaseDuration time.Duration) *IssuerStatusPublisher {
+	return &IssuerStatusPublisher{
+		client:        client,
+		namespace:     namespace,
+		hostname:      hostname,
+		state:         state,
+		leaseDuration: leaseDuration,
+		now:           time.Now,
+	}
+}
+
+// Run renews the issuer status Lease and re-evaluates the quorum every third
+// of the lease duration, until ctx is cancelled, at which point the Lease is
+// deleted before returning.
+func (p *IssuerStatusPublisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.leaseDuration / 3)
+	defer ticker.Stop()
+	for {
+		if err := p.sync(ctx); err != nil {
+			log.Warnf("Failed to sync issuer status: %s", err)
+		}
+		select {
+		case <-ctx.Done():
+			p.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *IssuerStatusPublisher) sync(ctx context.Context) error {
+	fingerprint, notBefore := p.state.IssuerStatus()
+	if fingerprint == "" {
+		return nil
+	}
+	own := identity.IssuerStatus{Replica: p.hostname, Fingerprint: fingerprint, NotBefore: notBefore}
+	if err := p.publish(ctx, own); err != nil {
+		return err
+	}
+
+	selector := labels.Set{pkgK8s.ControllerComponentLabel: "identity"}.AsSelector().String()
+	leases, err := p.client.CoordinationV1().Leases(p.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list issuer status leases: %w", err)
+	}
+
+	if newer, ok := supersedingIssuer(own, identity.LiveIssuerStatuses(leases.Items, p.now())); ok {
+		p.state.SuspendSigning(fmt.Sprintf("a quorum of identity replicas signs with a newer issuer (%s)", newer))
+	} else {
+		p.state.ResumeSigning()
+	}
+	return nil
+}
+
+func (p *IssuerStatusPublisher) publish(ctx context.Context, status identity.IssuerStatus) error {
+	name := pkgK8s.IdentityIssuerStatusLeasePrefix + p.hostname
+	durationSeconds := int32(p.leaseDuration.Seconds())
+	now := metav1.NewMicroTime(p.now())
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: p.namespace,
+			Labels: map[string]string{
+				pkgK8s.ControllerComponentLabel: "identity",
+				pkgK8s.ControllerNSLabel:        p.namespace,
+			},
+			Annotations: map[string]string{
+				pkgK8s.IdentityIssuerFingerprintAnnotation: status.Fingerprint,
+				pkgK8s.IdentityIssuerNotBeforeAnnotation:   status.NotBefore.UTC().Format(time.RFC3339),
+			},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &p.hostname,
+			LeaseDurationSeconds: &durationSeconds,
+			RenewTime:            &now,
+		},
+	}
+
+	leases := p.client.CoordinationV1().Leases(p.namespace)
+	existing, err := leases.Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		_, err = leases.Create(ctx, lease, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	lease.ResourceVersion = existing.ResourceVersion
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+func (p *IssuerStatusPublisher) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	name := pkgK8s.IdentityIssuerStatusLeasePrefix + p.hostname
+	err := p.client.CoordinationV1().Leases(p.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		log.Warnf("Failed to delete issuer status lease %s: %s", name, err)
+	}
+}
+
+// supersedingIssuer returns the fingerprint of an issuer that is newer than
+// the one in own and that is used by a strict majority of the live replicas,
+// if there is one.
+func supersedingIssuer(own identity.IssuerStatus, statuses []identity.IssuerStatus) (string, bool) {
+	counts := map[string]int{own.Fingerprint: 1}
+	notBefore := map[string]time.Time{}
+	total := 1
+	for _, s := range statuses {
+		if s.Replica == own.Replica {
+			continue
+		}
+		total++
+		counts[s.Fingerprint]++
+		if s.NotBefore.After(notBefore[s.Fingerprint]) {
+			notBefore[s.Fingerprint] = s.NotBefore
+		}
+	}
+	for fingerprint, count := range counts {
+		if fingerprint == own.Fingerprint {
+			continue
+		}
+		if count*2 > total && notBefore[fingerprint].After(own.NotBefore) {
+			return fingerprint, true
+		}
+	}
+	return "", false
+}
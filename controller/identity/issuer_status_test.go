@@ -0,0 +1,205 @@
+package identity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/identity"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeIssuerState struct {
+	fingerprint string
+	notBefore   time.Time
+	suspended   string
+}
+
+func (f *fakeIssuerState) IssuerStatus() (string, time.Time) { return f.fingerprint, f.notBefore }
+func (f *fakeIssuerState) SuspendSigning(reason string)      { f.suspended = reason }
+func (f *fakeIssuerState) ResumeSigning()                    { f.suspended = "" }
+
+func TestSupersedingIssuer(t *testing.T) {
+	old := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := old.Add(24 * time.Hour)
+
+	testCases := []struct {
+		desc       string
+		statuses   []identity.IssuerStatus
+		superseded bool
+	}{
+		{
+			desc:     "single replica",
+			statuses: nil,
+		},
+		{
+			desc: "all replicas agree",
+			statuses: []identity.IssuerStatus{
+				{Replica: "b", Fingerprint: "old", NotBefore: old},
+				{Replica: "c", Fingerprint: "old", NotBefore: old},
+			},
+		},
+		{
+			desc: "only a minority moved on",
+			statuses: []identity.IssuerStatus{
+				{Replica: "b", Fingerprint: "new", NotBefore: newer},
+				{Replica: "c", Fingerprint: "old", NotBefore: old},
+			},
+		},
+		{
+			desc: "half of the replicas is not a quorum",
+			statuses: []identity.IssuerStatus{
+				{Replica: "b", Fingerprint: "new", NotBefore: newer},
+			},
+		},
+		{
+			desc: "a quorum moved on",
+			statuses: []identity.IssuerStatus{
+				{Replica: "b", Fingerprint: "new", NotBefore: newer},
+				{Replica: "c", Fingerprint: "new", NotBefore: newer},
+			},
+			superseded: true,
+		},
+		{
+			desc: "a quorum uses an older issuer",
+			statuses: []identity.IssuerStatus{
+				{Replica: "b", Fingerprint: "older", NotBefore: old.Add(-time.Hour)},
+				{Replica: "c", Fingerprint: "older", NotBefore: old.Add(-time.Hour)},
+			},
+		},
+		{
+			desc: "stale own status is ignored",
+			statuses: []identity.IssuerStatus{
+				{Replica: "a", Fingerprint: "new", NotBefore: newer},
+				{Replica: "b", Fingerprint: "new", NotBefore: newer},
+			},
+		},
+	}
+
+	own := identity.IssuerStatus{Replica: "a", Fingerprint: "old", NotBefore: old}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			fingerprint, superseded := supersedingIssuer(own, tc.statuses)
+			if superseded != tc.superseded {
+				t.Fatalf("Expected superseded=%t, got %t (%s)", tc.superseded, superseded, fingerprint)
+			}
+		})
+	}
+}
+
+func TestIssuerStatusPublisher(t *testing.T) {
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	newer := now.Add(-time.Hour)
+	lease := func(replica, fingerprint string, notBefore, renewed time.Time) *coordinationv1.Lease {
+		duration := int32(30)
+		renewTime := metav1.NewMicroTime(renewed)
+		return &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pkgK8s.IdentityIssuerStatusLeasePrefix + replica,
+				Namespace: "linkerd",
+				Labels:    map[string]string{pkgK8s.ControllerComponentLabel: "identity"},
+				Annotations: map[string]string{
+					pkgK8s.IdentityIssuerFingerprintAnnotation: fingerprint,
+					pkgK8s.IdentityIssuerNotBeforeAnnotation:   notBefore.Format(time.RFC3339),
+				},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &replica,
+				LeaseDurationSeconds: &duration,
+				RenewTime:            &renewTime,
+			},
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		lease("b", "new", newer, now),
+		lease("c", "new", newer, now),
+		// Expired, and therefore ignored.
+		lease("d", "old", now.Add(-48*time.Hour), now.Add(-time.Hour)),
+		lease("e", "old", now.Add(-48*time.Hour), now.Add(-time.Hour)),
+	)
+	state := &fakeIssuerState{fingerprint: "old", notBefore: now.Add(-48 * time.Hour)}
+	p := NewIssuerStatusPublisher(client, "linkerd", "a", state, DefaultIssuerStatusLeaseDuration)
+	p.now = func() time.Time { return now }
+
+	if err := p.sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if state.suspended == "" {
+		t.Fatal("Expected signing to be suspended")
+	}
+	published, err := client.CoordinationV1().Leases("linkerd").Get(context.Background(), pkgK8s.IdentityIssuerStatusLeasePrefix+"a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if fp := published.Annotations[pkgK8s.IdentityIssuerFingerprintAnnotation]; fp != "old" {
+		t.Fatalf("Expected published fingerprint 'old', got '%s'", fp)
+	}
+
+	// Once the replica picks up the new issuer, it resumes signing.
+	state.fingerprint, state.notBefore = "new", newer
+	if err := p.sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if state.suspended != "" {
+		t.Fatalf("Expected signing to resume, got: %s", state.suspended)
+	}
+
+	p.release()
+	if _, err := client.CoordinationV1().Leases("linkerd").Get(context.Background(), pkgK8s.IdentityIssuerStatusLeasePrefix+"a", metav1.GetOptions{}); err == nil {
+		t.Fatal("Expected the lease to be deleted on release")
+	}
+}
+
+func TestIssuerStatusPublisherTwoReplicas(t *testing.T) {
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	duration := int32(30)
+	replica := "b"
+	renewTime := metav1.NewMicroTime(now)
+	client := fake.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pkgK8s.IdentityIssuerStatusLeasePrefix + replica,
+			Namespace: "linkerd",
+			Labels:    map[string]string{pkgK8s.ControllerComponentLabel: "identity"},
+			Annotations: map[string]string{
+				pkgK8s.IdentityIssuerFingerprintAnnotation: "new",
+				pkgK8s.IdentityIssuerNotBeforeAnnotation:   now.Add(-time.Hour).Format(time.RFC3339),
+			},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &replica,
+			LeaseDurationSeconds: &duration,
+			RenewTime:            &renewTime,
+		},
+	})
+	state := &fakeIssuerState{fingerprint: "old", notBefore: now.Add(-48 * time.Hour)}
+	p := NewIssuerStatusPublisher(client, "linkerd", "a", state, DefaultIssuerStatusLeaseDuration)
+	p.now = func() time.Time { return now }
+
+	// With two replicas, one replica moving on is never a strict majority, so
+	// the other keeps signing with its issuer.
+	if err := p.sync(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if state.suspended != "" {
+		t.Fatalf("Expected signing not to be suspended, got: %s", state.suspended)
+	}
+}
+
+func TestIssuerStatusPublisherRunReleasesLease(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	state := &fakeIssuerState{fingerprint: "old", notBefore: time.Now().Add(-time.Hour)}
+	p := NewIssuerStatusPublisher(client, "linkerd", "a", state, DefaultIssuerStatusLeaseDuration)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p.Run(ctx)
+
+	if _, err := client.CoordinationV1().Leases("linkerd").Get(context.Background(), pkgK8s.IdentityIssuerStatusLeasePrefix+"a", metav1.GetOptions{}); err == nil {
+		t.Fatal("Expected the lease to be deleted once Run returns")
+	}
+}
@@ -0,0 +1,98 @@
+package identity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// AnnotationLifetimePolicy implements LifetimePolicy by reading the
+// IdentityIssuanceLifetimeAnnotation from the ServiceAccount backing an
+// identity, falling back to its Namespace. Lifetimes are clamped to the
+// configured minimum and maximum.
+type AnnotationLifetimePolicy struct {
+	metadataAPI *k8s.MetadataAPI
+	min, max    time.Duration
+}
+
+// NewAnnotationLifetimePolicy creates an AnnotationLifetimePolicy. The
+// metadata API must have been initialized with the NS and SA resources.
+func NewAnnotationLifetimePolicy(metadataAPI *k8s.MetadataAPI, min, max time.Duration) (*AnnotationLifetimePolicy, error) {
+	if err := ValidateLifetimeBounds(min, max); err != nil {
+		return nil, err
+	}
+	return &AnnotationLifetimePolicy{metadataAPI, min, max}, nil
+}
+
+// ValidateLifetimeBounds returns an error if the bounds of an
+// AnnotationLifetimePolicy aren't positive, or if min exceeds max.
+func ValidateLifetimeBounds(min, max time.Duration) error {
+	if min <= 0 || max <= 0 {
+		return fmt.Errorf("lifetime bounds must be positive: min=%s; max=%s", min, max)
+	}
+	if min > max {
+		return fmt.Errorf("minimum lifetime %s exceeds maximum lifetime %s", min, max)
+	}
+	return nil
+}
+
+// Lifetime returns the lifetime annotated on the identity's ServiceAccount or
+// Namespace, clamped to the configured bounds.
+func (p *AnnotationLifetimePolicy) Lifetime(identity string) (time.Duration, bool) {
+	segments := strings.Split(identity, ".")
+	if len(segments) < 3 || segments[2] != "serviceaccount" {
+		return 0, false
+	}
+	sa, ns := segments[0], segments[1]
+
+	value, source := p.annotation(ns, sa)
+	if value == "" {
+		return 0, false
+	}
+
+	lifetime, err := time.ParseDuration(value)
+	if err != nil || lifetime <= 0 {
+		log.Warnf("Ignoring invalid %s annotation on %s: %q", pkgK8s.IdentityIssuanceLifetimeAnnotation, source, value)
+		return 0, false
+	}
+
+	switch {
+	case lifetime < p.min:
+		log.Debugf("Clamping lifetime %s from %s to the minimum of %s", lifetime, source, p.min)
+		lifetime = p.min
+	case lifetime > p.max:
+		log.Debugf("Clamping lifetime %s from %s to the maximum of %s", lifetime, source, p.max)
+		lifetime = p.max
+	}
+	return lifetime, true
+}
+
+// annotation returns the value of the lifetime annotation and a description
+// of the object it was read from. ServiceAccount annotations take precedence
+// over Namespace annotations.
+func (p *AnnotationLifetimePolicy) annotation(ns, sa string) (string, string) {
+	objs, err := p.metadataAPI.GetByNamespaceFiltered(k8s.SA, ns, sa, labels.Everything())
+	if err != nil && !kerrors.IsNotFound(err) {
+		log.Warnf("Failed to get ServiceAccount %s/%s: %s", ns, sa, err)
+	}
+	for _, obj := range objs {
+		if v, ok := obj.GetAnnotations()[pkgK8s.IdentityIssuanceLifetimeAnnotation]; ok {
+			return v, fmt.Sprintf("ServiceAccount %s/%s", ns, sa)
+		}
+	}
+
+	nsMeta, err := p.metadataAPI.Get(k8s.NS, ns)
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			log.Warnf("Failed to get Namespace %s: %s", ns, err)
+		}
+		return "", ""
+	}
+	return nsMeta.GetAnnotations()[pkgK8s.IdentityIssuanceLifetimeAnnotation], fmt.Sprintf("Namespace %s", ns)
+}
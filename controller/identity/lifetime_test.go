@@ -0,0 +1,96 @@
+package identity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+)
+
+func TestAnnotationLifetimePolicy(t *testing.T) {
+	metadataAPI, err := k8s.NewFakeMetadataAPI([]string{`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: edge
+  annotations:
+    identity.linkerd.io/issuance-lifetime: 1h
+`, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: batch
+`, `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: ingress
+  namespace: edge
+`, `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: admin
+  namespace: edge
+  annotations:
+    identity.linkerd.io/issuance-lifetime: 30m
+`, `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: nightly
+  namespace: batch
+  annotations:
+    identity.linkerd.io/issuance-lifetime: 72h
+`, `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: broken
+  namespace: batch
+  annotations:
+    identity.linkerd.io/issuance-lifetime: forever
+`})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	metadataAPI.Sync(nil)
+
+	policy, err := NewAnnotationLifetimePolicy(metadataAPI, 10*time.Minute, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	testCases := []struct {
+		identity         string
+		expectedLifetime time.Duration
+		expectedOk       bool
+	}{
+		{"ingress.edge.serviceaccount.identity.linkerd.cluster.local", time.Hour, true},
+		{"admin.edge.serviceaccount.identity.linkerd.cluster.local", 30 * time.Minute, true},
+		{"nightly.batch.serviceaccount.identity.linkerd.cluster.local", 24 * time.Hour, true},
+		{"broken.batch.serviceaccount.identity.linkerd.cluster.local", 0, false},
+		{"default.batch.serviceaccount.identity.linkerd.cluster.local", 0, false},
+		{"default.unknown.serviceaccount.identity.linkerd.cluster.local", 0, false},
+		{"vm.external.cluster.local", 0, false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.identity, func(t *testing.T) {
+			lifetime, ok := policy.Lifetime(tc.identity)
+			if ok != tc.expectedOk || lifetime != tc.expectedLifetime {
+				t.Fatalf("Expected (%s, %t), got (%s, %t)", tc.expectedLifetime, tc.expectedOk, lifetime, ok)
+			}
+		})
+	}
+}
+
+func TestNewAnnotationLifetimePolicyBounds(t *testing.T) {
+	if _, err := NewAnnotationLifetimePolicy(nil, 2*time.Hour, time.Hour); err == nil {
+		t.Fatal("Expected error when min exceeds max")
+	}
+	if _, err := NewAnnotationLifetimePolicy(nil, 0, time.Hour); err == nil {
+		t.Fatal("Expected error for a zero minimum")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	l5dcrdinformer "github.com/linkerd/linkerd2/controller/gen/client/informers/externalversions"
 	ewinformers "github.com/linkerd/linkerd2/controller/gen/client/informers/externalversions/externalworkload/v1beta1"
 	linkinformers "github.com/linkerd/linkerd2/controller/gen/client/informers/externalversions/link/v1alpha3"
+	pcinformers "github.com/linkerd/linkerd2/controller/gen/client/informers/externalversions/proxyconfig/v1alpha1"
 	srvinformers "github.com/linkerd/linkerd2/controller/gen/client/informers/externalversions/server/v1beta3"
 	spinformers "github.com/linkerd/linkerd2/controller/gen/client/informers/externalversions/serviceprofile/v1alpha2"
 	"github.com/linkerd/linkerd2/pkg/k8s"
@@ -53,6 +54,8 @@ type API struct {
 	endpoint coreinformers.EndpointsInformer
 	es       discoveryinformers.EndpointSliceInformer
 	ew       ewinformers.ExternalWorkloadInformer
+	pc       pcinformers.ProxyConfigInformer
+	pr       pcinformers.ProxyRolloutInformer
 	job      batchv1informers.JobInformer
 	link     linkinformers.LinkInformer
 	mwc      arinformers.MutatingWebhookConfigurationInformer
@@ -138,6 +141,16 @@ func initAPI(ctx context.Context, k8sClient *k8s.KubernetesAPI, dynamicClient dy
 			if err != nil {
 				return nil, err
 			}
+		case res == ProxyConfig:
+			err := k8s.ProxyConfigAccess(ctx, k8sClient)
+			if err != nil {
+				return nil, err
+			}
+		case res == ProxyRollout:
+			err := k8s.ProxyRolloutAccess(ctx, k8sClient)
+			if err != nil {
+				return nil, err
+			}
 		default:
 			continue
 		}
@@ -216,6 +229,14 @@ func NewL5dNamespacedAPI(
 			api.link = l5dCrdSharedInformers.Link().V1alpha3().Links()
 			api.syncChecks = append(api.syncChecks, api.link.Informer().HasSynced)
 			api.promGauges.addInformerSize(k8s.Link, informerLabels, api.link.Informer())
+		case ProxyConfig:
+			api.pc = l5dCrdSharedInformers.Proxyconfig().V1alpha1().ProxyConfigs()
+			api.syncChecks = append(api.syncChecks, api.pc.Informer().HasSynced)
+			api.promGauges.addInformerSize(k8s.ProxyConfig, informerLabels, api.pc.Informer())
+		case ProxyRollout:
+			api.pr = l5dCrdSharedInformers.Proxyconfig().V1alpha1().ProxyRollouts()
+			api.syncChecks = append(api.syncChecks, api.pr.Informer().HasSynced)
+			api.promGauges.addInformerSize(k8s.ProxyRollout, informerLabels, api.pr.Informer())
 		case SP:
 			api.sp = l5dCrdSharedInformers.Linkerd().V1alpha2().ServiceProfiles()
 			api.syncChecks = append(api.syncChecks, api.sp.Informer().HasSynced)
@@ -312,6 +333,20 @@ func newAPI(
 			api.pod = sharedInformers.Core().V1().Pods()
 			api.syncChecks = append(api.syncChecks, api.pod.Informer().HasSynced)
 			api.promGauges.addInformerSize(k8s.Pod, informerLabels, api.pod.Informer())
+		case ProxyConfig:
+			if l5dCrdSharedInformers == nil {
+				panic("Linkerd CRD shared informer not configured")
+			}
+			api.pc = l5dCrdSharedInformers.Proxyconfig().V1alpha1().ProxyConfigs()
+			api.syncChecks = append(api.syncChecks, api.pc.Informer().HasSynced)
+			api.promGauges.addInformerSize(k8s.ProxyConfig, informerLabels, api.pc.Informer())
+		case ProxyRollout:
+			if l5dCrdSharedInformers == nil {
+				panic("Linkerd CRD shared informer not configured")
+			}
+			api.pr = l5dCrdSharedInformers.Proxyconfig().V1alpha1().ProxyRollouts()
+			api.syncChecks = append(api.syncChecks, api.pr.Informer().HasSynced)
+			api.promGauges.addInformerSize(k8s.ProxyRollout, informerLabels, api.pr.Informer())
 		case RC:
 			api.rc = sharedInformers.Core().V1().ReplicationControllers()
 			api.syncChecks = append(api.syncChecks, api.rc.Informer().HasSynced)
@@ -463,6 +498,24 @@ func (api *API) ExtWorkload() ewinformers.ExternalWorkloadInformer {
 	return api.ew
 }
 
+// ProxyConfig provides access to a shared informer and lister for
+// ProxyConfig CRDs
+func (api *API) ProxyConfig() pcinformers.ProxyConfigInformer {
+	if api.pc == nil {
+		panic("ProxyConfig informer not configured")
+	}
+	return api.pc
+}
+
+// ProxyRollout provides access to a shared informer and lister for
+// ProxyRollout CRDs
+func (api *API) ProxyRollout() pcinformers.ProxyRolloutInformer {
+	if api.pr == nil {
+		panic("ProxyRollout informer not configured")
+	}
+	return api.pr
+}
+
 // CM provides access to a shared informer and lister for ConfigMaps.
 func (api *API) CM() coreinformers.ConfigMapInformer {
 	if api.cm == nil {
@@ -3,6 +3,7 @@ package k8s
 import (
 	"strings"
 
+	pcv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/proxyconfig/v1alpha1"
 	serverv1beta3 "github.com/linkerd/linkerd2/controller/gen/apis/server/v1beta3"
 	sazv1beta1 "github.com/linkerd/linkerd2/controller/gen/apis/serverauthorization/v1beta1"
 	spv1alpha2 "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha2"
@@ -36,6 +37,8 @@ const (
 	MWC
 	NS
 	Pod
+	ProxyConfig
+	ProxyRollout
 	RC
 	RS
 	SP
@@ -45,6 +48,7 @@ const (
 	Secret
 	Srv
 	Saz
+	SA
 )
 
 // GVK returns the GroupVersionKind corresponding for the provided APIResource
@@ -72,10 +76,16 @@ func (res APIResource) GVK() (schema.GroupVersionKind, error) {
 		return v1.SchemeGroupVersion.WithKind("Namespace"), nil
 	case Pod:
 		return v1.SchemeGroupVersion.WithKind("Pod"), nil
+	case ProxyConfig:
+		return pcv1alpha1.SchemeGroupVersion.WithKind("ProxyConfig"), nil
+	case ProxyRollout:
+		return pcv1alpha1.SchemeGroupVersion.WithKind("ProxyRollout"), nil
 	case RC:
 		return v1.SchemeGroupVersion.WithKind("ReplicationController"), nil
 	case RS:
 		return appsv1.SchemeGroupVersion.WithKind("ReplicaSet"), nil
+	case SA:
+		return v1.SchemeGroupVersion.WithKind("ServiceAccount"), nil
 	case Saz:
 		return sazv1beta1.SchemeGroupVersion.WithKind("ServerAuthorization"), nil
 	case Secret:
@@ -124,6 +134,8 @@ func GetAPIResource(kind string) (APIResource, error) {
 		return RS, nil
 	case k8s.ServerAuthorization:
 		return Saz, nil
+	case k8s.ServiceAccount:
+		return SA, nil
 	case k8s.Secret:
 		return Secret, nil
 	case k8s.ServiceProfile:
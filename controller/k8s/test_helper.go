@@ -61,6 +61,8 @@ func NewFakeClusterScopedAPI(clientSet kubernetes.Interface, l5dClientSet l5dcrd
 		NS,
 		Pod,
 		ExtWorkload,
+		ProxyConfig,
+		ProxyRollout,
 		RC,
 		RS,
 		SP,
@@ -114,6 +116,7 @@ func NewFakeMetadataAPI(configs []string) (*MetadataAPI, error) {
 		Node,
 		ES,
 		Svc,
+		SA,
 	)
 }
 
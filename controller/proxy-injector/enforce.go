@@ -0,0 +1,190 @@
+package injector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/controller/webhook"
+	"github.com/linkerd/linkerd2/pkg/config"
+	"github.com/linkerd/linkerd2/pkg/inject"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/util"
+	log "github.com/sirupsen/logrus"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// forbiddenSkippedOutboundPorts are the ports whose traffic must go through
+// the proxy of pods in namespaces where meshing is enforced
+var forbiddenSkippedOutboundPorts = []uint32{443}
+
+// Validate returns the function that denies the admission of the pods that
+// would run un-meshed in namespaces where meshing is enforced, or that
+// override the proxy's configuration in forbidden ways. Meshing is enforced in
+// the namespaces labeled with pkgK8s.EnforceMeshingLabel set to "true", and
+// when clusterWide is set, in every namespace that doesn't set it to "false".
+//
+// It's meant to be called by a validating webhook, so that pods are checked
+// once every mutating webhook, including the proxy injector, has run.
+func Validate(linkerdNamespace string, clusterWide bool) webhook.Handler {
+	return func(
+		ctx context.Context,
+		api *k8s.MetadataAPI,
+		request *admissionv1beta1.AdmissionRequest,
+		_ record.EventRecorder,
+	) (*admissionv1beta1.AdmissionResponse, error) {
+		allowed := &admissionv1beta1.AdmissionResponse{UID: request.UID, Allowed: true}
+		if request.Kind.Kind != pkgK8s.Pod || request.Operation != admissionv1beta1.Create {
+			return allowed, nil
+		}
+
+		ns, err := api.Get(k8s.NS, request.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if !meshingEnforced(ns.GetLabels(), clusterWide) {
+			return allowed, nil
+		}
+
+		valuesConfig, err := config.Values(pkgK8s.MountPathValuesConfig)
+		if err != nil {
+			return nil, err
+		}
+		resourceConfig := inject.NewResourceConfig(valuesConfig, inject.OriginWebhook, linkerdNamespace).
+			WithOwnerRetriever(ownerRetriever(ctx, api, request.Namespace)).
+			WithNsAnnotations(ns.GetAnnotations()).
+			WithKind(request.Kind.Kind)
+		report, err := resourceConfig.ParseMetaAndYAML(request.Object.Raw)
+		if err != nil {
+			return nil, err
+		}
+
+		var pod v1.Pod
+		if err := json.Unmarshal(request.Object.Raw, &pod); err != nil {
+			return nil, err
+		}
+
+		// The ports requiring identity for the pod's namespace, which its own
+		// annotation can't drop
+		requiredIdentityPorts := valuesConfig.Proxy.RequireIdentityOnInboundPorts
+		if ports, ok := ns.GetAnnotations()[pkgK8s.ProxyRequireIdentityOnInboundPortsAnnotation]; ok {
+			requiredIdentityPorts = ports
+		}
+
+		violations := policyViolations(&pod, report, util.ParsePorts(requiredIdentityPorts))
+		if len(violations) == 0 {
+			return allowed, nil
+		}
+
+		msg := fmt.Sprintf("namespace %s requires pods to be meshed by Linkerd (%s label): %s",
+			request.Namespace, pkgK8s.EnforceMeshingLabel, strings.Join(violations, "; "))
+		log.Infof("denied %s: %s", report.ResName(), msg)
+
+		counter, err := proxyInjectionEnforcementDenials.GetMetricWith(map[string]string{labelNamespace: request.Namespace})
+		if err != nil {
+			log.Errorf("failed to get proxy_inject_enforcement_denials metric: %q", err)
+		} else {
+			counter.Inc()
+		}
+
+		return &admissionv1beta1.AdmissionResponse{
+			UID:     request.UID,
+			Allowed: false,
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Reason:  metav1.StatusReasonForbidden,
+				Code:    http.StatusForbidden,
+				Message: msg,
+			},
+		}, nil
+	}
+}
+
+// meshingEnforced returns true if meshing is enforced in the namespace with
+// the given labels.
+func meshingEnforced(nsLabels map[string]string, clusterWide bool) bool {
+	if enforce, err := strconv.ParseBool(nsLabels[pkgK8s.EnforceMeshingLabel]); err == nil {
+		return enforce
+	}
+	return clusterWide
+}
+
+// policyViolations returns the actionable reasons for which pod breaks the
+// meshing policy, if any. report is the injection report of the pod, and
+// requiredIdentityPorts are the inbound ports on which its namespace requires
+// identity from clients.
+func policyViolations(pod *v1.Pod, report *inject.Report, requiredIdentityPorts map[uint32]struct{}) []string {
+	if !hasProxy(pod) {
+		_, reasons := report.Injectable()
+		if len(reasons) == 0 {
+			return []string{"the pod wasn't injected with the proxy, which may happen while the proxy injector is unavailable; recreate it once the proxy injector is running"}
+		}
+		readableReasons := make([]string, 0, len(reasons))
+		for _, reason := range reasons {
+			readableReasons = append(readableReasons, inject.Reasons[reason])
+		}
+		return []string{fmt.Sprintf("the pod wouldn't be meshed because %s; enable injection with the \"%s: %s\" annotation on the pod or namespace, and make sure the pod can be injected",
+			strings.Join(readableReasons, ", "), pkgK8s.ProxyInjectAnnotation, pkgK8s.ProxyInjectEnabled)}
+	}
+
+	var violations []string
+	annotations := pod.GetAnnotations()
+
+	if skipped, ok := annotations[pkgK8s.ProxyIgnoreOutboundPortsAnnotation]; ok {
+		ports := util.ParsePorts(skipped)
+		for _, port := range forbiddenSkippedOutboundPorts {
+			if _, ok := ports[port]; ok {
+				violations = append(violations, fmt.Sprintf("the \"%s: %s\" annotation has outbound traffic to port %d bypass the proxy; remove port %d from it",
+					pkgK8s.ProxyIgnoreOutboundPortsAnnotation, skipped, port, port))
+			}
+		}
+	}
+
+	switch policy := annotations[pkgK8s.ProxyDefaultInboundPolicyAnnotation]; policy {
+	case pkgK8s.AllUnauthenticated, pkgK8s.ClusterUnauthenticated:
+		violations = append(violations, fmt.Sprintf("the \"%s: %s\" annotation doesn't require identity from clients; use %s or %s instead",
+			pkgK8s.ProxyDefaultInboundPolicyAnnotation, policy, pkgK8s.AllAuthenticated, pkgK8s.ClusterAuthenticated))
+	}
+
+	if required, ok := annotations[pkgK8s.ProxyRequireIdentityOnInboundPortsAnnotation]; ok {
+		ports := util.ParsePorts(required)
+		var missing []uint32
+		for port := range requiredIdentityPorts {
+			if _, ok := ports[port]; !ok {
+				missing = append(missing, port)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+			missingPorts := make([]string, 0, len(missing))
+			for _, port := range missing {
+				missingPorts = append(missingPorts, strconv.FormatUint(uint64(port), 10))
+			}
+			violations = append(violations, fmt.Sprintf("the \"%s: %s\" annotation doesn't require identity from clients on inbound ports %s; add them to it",
+				pkgK8s.ProxyRequireIdentityOnInboundPortsAnnotation, required, strings.Join(missingPorts, ",")))
+		}
+	}
+
+	return violations
+}
+
+// hasProxy returns true if the pod has a proxy container, either as a regular
+// container or as a native sidecar.
+func hasProxy(pod *v1.Pod) bool {
+	for _, containers := range [][]v1.Container{pod.Spec.Containers, pod.Spec.InitContainers} {
+		for _, c := range containers {
+			if c.Name == pkgK8s.ProxyContainerName {
+				return true
+			}
+		}
+	}
+	return false
+}
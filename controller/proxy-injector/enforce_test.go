@@ -0,0 +1,130 @@
+package injector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/inject"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMeshingEnforced(t *testing.T) {
+	testCases := []struct {
+		label       string
+		clusterWide bool
+		expected    bool
+	}{
+		{"", false, false},
+		{"", true, true},
+		{"true", false, true},
+		{"false", true, false},
+		{"invalid", false, false},
+	}
+	for _, tc := range testCases {
+		labels := map[string]string{}
+		if tc.label != "" {
+			labels[pkgK8s.EnforceMeshingLabel] = tc.label
+		}
+		if actual := meshingEnforced(labels, tc.clusterWide); actual != tc.expected {
+			t.Errorf("label %q, cluster-wide %t: expected %t, got %t", tc.label, tc.clusterWide, tc.expected, actual)
+		}
+	}
+}
+
+func TestPolicyViolations(t *testing.T) {
+	meshed := func(annotations map[string]string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+			Spec: v1.PodSpec{
+				InitContainers: []v1.Container{{Name: pkgK8s.ProxyContainerName}},
+				Containers:     []v1.Container{{Name: "app"}},
+			},
+		}
+	}
+	unmeshed := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "app"}}}}
+
+	testCases := []struct {
+		name          string
+		pod           *v1.Pod
+		report        *inject.Report
+		requiredPorts string
+		expected      []string
+	}{
+		{
+			name:   "meshed pod",
+			pod:    meshed(map[string]string{pkgK8s.ProxyIgnoreOutboundPortsAnnotation: "25,3306"}),
+			report: &inject.Report{},
+		},
+		{
+			name:     "un-meshed pod",
+			pod:      unmeshed,
+			report:   &inject.Report{HostNetwork: true, AutomountServiceAccountToken: true},
+			expected: []string{"the pod wouldn't be meshed because hostNetwork is enabled"},
+		},
+		{
+			name:     "un-meshed injectable pod",
+			pod:      unmeshed,
+			report:   &inject.Report{AutomountServiceAccountToken: true},
+			expected: []string{"the pod wasn't injected with the proxy"},
+		},
+		{
+			name: "forbidden overrides",
+			pod: meshed(map[string]string{
+				pkgK8s.ProxyIgnoreOutboundPortsAnnotation:  "25,400-500",
+				pkgK8s.ProxyDefaultInboundPolicyAnnotation: pkgK8s.AllUnauthenticated,
+			}),
+			report: &inject.Report{},
+			expected: []string{
+				`the "config.linkerd.io/skip-outbound-ports: 25,400-500" annotation has outbound traffic to port 443 bypass the proxy`,
+				`the "config.linkerd.io/default-inbound-policy: all-unauthenticated" annotation doesn't require identity from clients`,
+			},
+		},
+		{
+			name:          "required identity ports kept",
+			pod:           meshed(map[string]string{pkgK8s.ProxyRequireIdentityOnInboundPortsAnnotation: "8080-8090,9090"}),
+			report:        &inject.Report{},
+			requiredPorts: "8080,9090",
+		},
+		{
+			name:          "required identity ports dropped",
+			pod:           meshed(map[string]string{pkgK8s.ProxyRequireIdentityOnInboundPortsAnnotation: "8080"}),
+			report:        &inject.Report{},
+			requiredPorts: "9090,8080,7070",
+			expected: []string{
+				`the "config.linkerd.io/proxy-require-identity-inbound-ports: 8080" annotation doesn't require identity from clients on inbound ports 7070,9090`,
+			},
+		},
+		{
+			name:          "required identity disabled",
+			pod:           meshed(map[string]string{pkgK8s.ProxyRequireIdentityOnInboundPortsAnnotation: ""}),
+			report:        &inject.Report{},
+			requiredPorts: "8080",
+			expected: []string{
+				`the "config.linkerd.io/proxy-require-identity-inbound-ports: " annotation doesn't require identity from clients on inbound ports 8080`,
+			},
+		},
+		{
+			name:          "required identity ports not overridden",
+			pod:           meshed(nil),
+			report:        &inject.Report{},
+			requiredPorts: "8080",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			violations := policyViolations(tc.pod, tc.report, util.ParsePorts(tc.requiredPorts))
+			if len(violations) != len(tc.expected) {
+				t.Fatalf("Expected %d violations, got %v", len(tc.expected), violations)
+			}
+			for i, expected := range tc.expected {
+				if !strings.HasPrefix(violations[i], expected) {
+					t.Errorf("Expected violation %q to start with %q", violations[i], expected)
+				}
+			}
+		})
+	}
+}
@@ -29,6 +29,11 @@ var (
 		Name: "proxy_inject_admission_responses_total",
 		Help: "A counter for number of admission responses from proxy injector.",
 	}, append(responseLabels, validLabelNames(inject.ProxyAnnotations)...))
+
+	proxyInjectionEnforcementDenials = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "proxy_inject_enforcement_denials_total",
+		Help: "A counter for number of pods denied admission by the proxy injector's enforcement mode.",
+	}, []string{labelNamespace})
 )
 
 func admissionRequestLabels(ownerKind, namespace, annotationAt string, configLabels prometheus.Labels) prometheus.Labels {
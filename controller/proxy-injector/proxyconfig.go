@@ -0,0 +1,168 @@
+package injector
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	pcv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/proxyconfig/v1alpha1"
+	pclisters "github.com/linkerd/linkerd2/controller/gen/client/listers/proxyconfig/v1alpha1"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// resolveNsAnnotations layers the ProxyConfigs that apply to a pod and the
+// annotations of its Namespace into a single set of config.linkerd.io
+// annotations, from lowest to highest precedence:
+//
+//  1. ProxyConfigs in the control plane namespace without a selector;
+//  2. the Namespace's annotations;
+//  3. ProxyConfigs in the pod's namespace without a selector;
+//  4. ProxyConfigs in the pod's namespace whose selector matches the pod.
+//
+// The result is used in place of the Namespace's annotations, so that the
+// workload's own annotations keep the highest precedence. Within a level,
+// ProxyConfigs are applied in name order. The resolved annotations coming from
+// ProxyConfigs are returned as well, mapped to the ProxyConfig's
+// namespace/name.
+func resolveNsAnnotations(
+	proxyConfigs pclisters.ProxyConfigLister,
+	linkerdNamespace, namespace string,
+	nsAnnotations, podLabels map[string]string,
+) (map[string]string, map[string]string) {
+	resolved := map[string]string{}
+	sources := map[string]string{}
+	if proxyConfigs == nil {
+		for k, v := range nsAnnotations {
+			resolved[k] = v
+		}
+		return resolved, sources
+	}
+
+	clusterDefaults, _ := listProxyConfigs(proxyConfigs, linkerdNamespace, podLabels)
+	nsWide, selected := listProxyConfigs(proxyConfigs, namespace, podLabels)
+	if namespace == linkerdNamespace {
+		clusterDefaults = nil
+	}
+
+	for _, pc := range clusterDefaults {
+		mergeAnnotations(resolved, sources, proxyConfigAnnotations(&pc.Spec.Proxy), pc)
+	}
+	mergeAnnotations(resolved, sources, nsAnnotations, nil)
+	for _, pc := range nsWide {
+		mergeAnnotations(resolved, sources, proxyConfigAnnotations(&pc.Spec.Proxy), pc)
+	}
+	for _, pc := range selected {
+		log.Debugf("applying ProxyConfig %s/%s", pc.Namespace, pc.Name)
+		mergeAnnotations(resolved, sources, proxyConfigAnnotations(&pc.Spec.Proxy), pc)
+	}
+	return resolved, sources
+}
+
+// listProxyConfigs returns the ProxyConfigs in a namespace without a
+// selector, and those whose selector matches podLabels, sorted by name.
+func listProxyConfigs(proxyConfigs pclisters.ProxyConfigLister, namespace string, podLabels map[string]string) ([]*pcv1alpha1.ProxyConfig, []*pcv1alpha1.ProxyConfig) {
+	pcs, err := proxyConfigs.ProxyConfigs(namespace).List(labels.Everything())
+	if err != nil {
+		log.Warnf("failed to list ProxyConfigs in %s: %s", namespace, err)
+		return nil, nil
+	}
+	sort.Slice(pcs, func(i, j int) bool { return pcs[i].Name < pcs[j].Name })
+
+	var nsWide, selected []*pcv1alpha1.ProxyConfig
+	for _, pc := range pcs {
+		if pc.Spec.Selector == nil {
+			nsWide = append(nsWide, pc)
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pc.Spec.Selector)
+		if err != nil {
+			log.Warnf("ignoring ProxyConfig %s/%s with invalid selector: %s", pc.Namespace, pc.Name, err)
+			continue
+		}
+		if selector.Matches(labels.Set(podLabels)) {
+			selected = append(selected, pc)
+		}
+	}
+	return nsWide, selected
+}
+
+// proxyConfigAnnotations converts ProxySettings into the equivalent
+// config.linkerd.io annotations, so that they go through the same overrides
+// as annotations set by hand.
+func proxyConfigAnnotations(s *pcv1alpha1.ProxySettings) map[string]string {
+	ann := map[string]string{}
+	set := func(key, value string) {
+		if value != "" {
+			ann[key] = value
+		}
+	}
+
+	if s.Image != nil {
+		set(pkgK8s.ProxyImageAnnotation, s.Image.Name)
+		set(pkgK8s.ProxyVersionOverrideAnnotation, s.Image.Version)
+		set(pkgK8s.ProxyImagePullPolicyAnnotation, string(s.Image.PullPolicy))
+	}
+	set(pkgK8s.ProxyLogLevelAnnotation, s.LogLevel)
+	set(pkgK8s.ProxyLogFormatAnnotation, s.LogFormat)
+
+	if r := s.Resources; r != nil {
+		setBounds := func(b *pcv1alpha1.ResourceBounds, requestKey, limitKey string) {
+			if b == nil {
+				return
+			}
+			set(requestKey, quantity(b.Request))
+			set(limitKey, quantity(b.Limit))
+		}
+		setBounds(r.CPU, pkgK8s.ProxyCPURequestAnnotation, pkgK8s.ProxyCPULimitAnnotation)
+		setBounds(r.Memory, pkgK8s.ProxyMemoryRequestAnnotation, pkgK8s.ProxyMemoryLimitAnnotation)
+		setBounds(r.EphemeralStorage, pkgK8s.ProxyEphemeralStorageRequestAnnotation, pkgK8s.ProxyEphemeralStorageLimitAnnotation)
+	}
+
+	set(pkgK8s.ProxyOpaquePortsAnnotation, strings.Join(s.OpaquePorts, ","))
+	set(pkgK8s.ProxyIgnoreInboundPortsAnnotation, strings.Join(s.SkipInboundPorts, ","))
+	set(pkgK8s.ProxyIgnoreOutboundPortsAnnotation, strings.Join(s.SkipOutboundPorts, ","))
+	set(pkgK8s.ProxySkipSubnetsAnnotation, strings.Join(s.SkipSubnets, ","))
+	set(pkgK8s.ProxyDefaultInboundPolicyAnnotation, s.DefaultInboundPolicy)
+	set(pkgK8s.ProxyAccessLogAnnotation, s.AccessLog)
+
+	if s.ShutdownGracePeriod != nil {
+		set(pkgK8s.ProxyShutdownGracePeriodAnnotation, s.ShutdownGracePeriod.Duration.String())
+	}
+	if s.EnableNativeSidecar != nil {
+		set(pkgK8s.ProxyEnableNativeSidecarAnnotation, strconv.FormatBool(*s.EnableNativeSidecar))
+	}
+	if len(s.AdditionalEnv) > 0 {
+		env, err := json.Marshal(s.AdditionalEnv)
+		if err != nil {
+			log.Warnf("failed to encode ProxyConfig additionalEnv: %s", err)
+		} else {
+			set(pkgK8s.ProxyAdditionalEnvAnnotation, string(env))
+		}
+	}
+	return ann
+}
+
+// mergeAnnotations merges src into dst, and records into sources the
+// ProxyConfig src comes from, if any.
+func mergeAnnotations(dst, sources, src map[string]string, pc *pcv1alpha1.ProxyConfig) {
+	for k, v := range src {
+		dst[k] = v
+		if pc != nil {
+			sources[k] = pc.Namespace + "/" + pc.Name
+		} else {
+			delete(sources, k)
+		}
+	}
+}
+
+func quantity(q *resource.Quantity) string {
+	if q == nil {
+		return ""
+	}
+	return q.String()
+}
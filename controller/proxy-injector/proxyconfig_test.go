@@ -0,0 +1,167 @@
+package injector
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+)
+
+func TestResolveNsAnnotations(t *testing.T) {
+	api, err := k8s.NewFakeAPI(`
+apiVersion: config.linkerd.io/v1alpha1
+kind: ProxyConfig
+metadata:
+  name: defaults
+  namespace: linkerd
+spec:
+  proxy:
+    logLevel: warn
+    logFormat: json
+    resources:
+      cpu:
+        request: 100m
+`, `
+apiVersion: config.linkerd.io/v1alpha1
+kind: ProxyConfig
+metadata:
+  name: namespace-wide
+  namespace: emojivoto
+spec:
+  proxy:
+    logLevel: info
+    opaquePorts: ["4222", "6379"]
+`, `
+apiVersion: config.linkerd.io/v1alpha1
+kind: ProxyConfig
+metadata:
+  name: web
+  namespace: emojivoto
+spec:
+  selector:
+    matchLabels:
+      app: web
+  proxy:
+    logLevel: debug
+    resources:
+      memory:
+        limit: 256Mi
+    shutdownGracePeriod: 30s
+    enableNativeSidecar: true
+    additionalEnv:
+    - name: FOO
+      value: bar
+`, `
+apiVersion: config.linkerd.io/v1alpha1
+kind: ProxyConfig
+metadata:
+  name: voting
+  namespace: emojivoto
+spec:
+  selector:
+    matchLabels:
+      app: voting
+  proxy:
+    logLevel: trace
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	api.Sync(nil)
+	lister := api.ProxyConfig().Lister()
+
+	nsAnnotations := map[string]string{
+		pkgK8s.ProxyInjectAnnotation:     pkgK8s.ProxyInjectEnabled,
+		pkgK8s.ProxyLogFormatAnnotation:  "plain",
+		pkgK8s.ProxyCPULimitAnnotation:   "1",
+		pkgK8s.ProxyCPURequestAnnotation: "200m",
+	}
+
+	testCases := []struct {
+		desc      string
+		namespace string
+		podLabels map[string]string
+		expected  map[string]string
+		// expectedSources are the ProxyConfigs the annotations come from
+		expectedSources map[string]string
+	}{
+		{
+			desc:      "selected pod",
+			namespace: "emojivoto",
+			podLabels: map[string]string{"app": "web"},
+			expected: map[string]string{
+				pkgK8s.ProxyInjectAnnotation:              pkgK8s.ProxyInjectEnabled,
+				pkgK8s.ProxyLogLevelAnnotation:            "debug",
+				pkgK8s.ProxyLogFormatAnnotation:           "plain",
+				pkgK8s.ProxyCPURequestAnnotation:          "200m",
+				pkgK8s.ProxyCPULimitAnnotation:            "1",
+				pkgK8s.ProxyMemoryLimitAnnotation:         "256Mi",
+				pkgK8s.ProxyOpaquePortsAnnotation:         "4222,6379",
+				pkgK8s.ProxyShutdownGracePeriodAnnotation: "30s",
+				pkgK8s.ProxyEnableNativeSidecarAnnotation: "true",
+				pkgK8s.ProxyAdditionalEnvAnnotation:       `[{"name":"FOO","value":"bar"}]`,
+			},
+			expectedSources: map[string]string{
+				pkgK8s.ProxyLogLevelAnnotation:            "emojivoto/web",
+				pkgK8s.ProxyMemoryLimitAnnotation:         "emojivoto/web",
+				pkgK8s.ProxyOpaquePortsAnnotation:         "emojivoto/namespace-wide",
+				pkgK8s.ProxyShutdownGracePeriodAnnotation: "emojivoto/web",
+				pkgK8s.ProxyEnableNativeSidecarAnnotation: "emojivoto/web",
+				pkgK8s.ProxyAdditionalEnvAnnotation:       "emojivoto/web",
+			},
+		},
+		{
+			desc:      "pod not selected",
+			namespace: "emojivoto",
+			podLabels: map[string]string{"app": "emoji"},
+			expected: map[string]string{
+				pkgK8s.ProxyInjectAnnotation:      pkgK8s.ProxyInjectEnabled,
+				pkgK8s.ProxyLogLevelAnnotation:    "info",
+				pkgK8s.ProxyLogFormatAnnotation:   "plain",
+				pkgK8s.ProxyCPURequestAnnotation:  "200m",
+				pkgK8s.ProxyCPULimitAnnotation:    "1",
+				pkgK8s.ProxyOpaquePortsAnnotation: "4222,6379",
+			},
+			expectedSources: map[string]string{
+				pkgK8s.ProxyLogLevelAnnotation:    "emojivoto/namespace-wide",
+				pkgK8s.ProxyOpaquePortsAnnotation: "emojivoto/namespace-wide",
+			},
+		},
+		{
+			desc:      "namespace without ProxyConfigs",
+			namespace: "books",
+			podLabels: map[string]string{"app": "web"},
+			expected: map[string]string{
+				pkgK8s.ProxyInjectAnnotation:     pkgK8s.ProxyInjectEnabled,
+				pkgK8s.ProxyLogLevelAnnotation:   "warn",
+				pkgK8s.ProxyLogFormatAnnotation:  "plain",
+				pkgK8s.ProxyCPURequestAnnotation: "200m",
+				pkgK8s.ProxyCPULimitAnnotation:   "1",
+			},
+			expectedSources: map[string]string{
+				pkgK8s.ProxyLogLevelAnnotation: "linkerd/defaults",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			resolved, sources := resolveNsAnnotations(lister, "linkerd", tc.namespace, nsAnnotations, tc.podLabels)
+			if diff := deep.Equal(resolved, tc.expected); diff != nil {
+				t.Fatalf("%+v", diff)
+			}
+			if diff := deep.Equal(sources, tc.expectedSources); diff != nil {
+				t.Fatalf("%+v", diff)
+			}
+		})
+	}
+
+	t.Run("no lister", func(t *testing.T) {
+		resolved, _ := resolveNsAnnotations(nil, "linkerd", "emojivoto", nsAnnotations, nil)
+		if diff := deep.Equal(resolved, nsAnnotations); diff != nil {
+			t.Fatalf("%+v", diff)
+		}
+	})
+}
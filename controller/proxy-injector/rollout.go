@@ -0,0 +1,97 @@
+package injector
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	pcv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/proxyconfig/v1alpha1"
+	pclisters "github.com/linkerd/linkerd2/controller/gen/client/listers/proxyconfig/v1alpha1"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// rolloutBuckets is the number of buckets workloads are hashed into; a
+// ProxyRollout's percentage is the number of buckets assigned to its canary
+// version.
+const rolloutBuckets = 100
+
+// selectProxyRollout returns the ProxyRollout in the control plane namespace
+// whose namespaceSelector matches nsLabels, or nil if there's none. When
+// several match, the first one in name order wins.
+func selectProxyRollout(proxyRollouts pclisters.ProxyRolloutLister, linkerdNamespace string, nsLabels map[string]string) *pcv1alpha1.ProxyRollout {
+	if proxyRollouts == nil {
+		return nil
+	}
+	prs, err := proxyRollouts.ProxyRollouts(linkerdNamespace).List(labels.Everything())
+	if err != nil {
+		log.Warnf("failed to list ProxyRollouts in %s: %s", linkerdNamespace, err)
+		return nil
+	}
+	sort.Slice(prs, func(i, j int) bool { return prs[i].Name < prs[j].Name })
+
+	for _, pr := range prs {
+		if pr.Spec.Version == "" {
+			log.Warnf("ignoring ProxyRollout %s/%s without a version", pr.Namespace, pr.Name)
+			continue
+		}
+		if pr.Spec.NamespaceSelector == nil {
+			return pr
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pr.Spec.NamespaceSelector)
+		if err != nil {
+			log.Warnf("ignoring ProxyRollout %s/%s with invalid namespaceSelector: %s", pr.Namespace, pr.Name, err)
+			continue
+		}
+		if selector.Matches(labels.Set(nsLabels)) {
+			return pr
+		}
+	}
+	return nil
+}
+
+// rolloutKey returns the key assigning a workload to a canary: the UID of its
+// top-level owner, e.g. the Deployment of a pod rather than its ReplicaSet,
+// which changes with every revision. When the owner couldn't be retrieved,
+// its namespace, kind and name are used instead. owner is the owner resolved
+// by the owner retriever, if any; kind and name are those of the admitted
+// resource, used without an owner.
+func rolloutKey(ownerUID types.UID, namespace string, owner *metav1.OwnerReference, kind, name string) string {
+	if ownerUID != "" {
+		return string(ownerUID)
+	}
+	if owner != nil {
+		kind, name = owner.Kind, owner.Name
+	}
+	return fmt.Sprintf("%s/%s/%s", namespace, strings.ToLower(kind), name)
+}
+
+// versionPinned returns whether the proxy version is set explicitly in any of
+// annotations, i.e. on the Namespace, by a ProxyConfig or on the workload.
+func versionPinned(annotations ...map[string]string) bool {
+	for _, a := range annotations {
+		if _, ok := a[pkgK8s.ProxyVersionOverrideAnnotation]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// inCanary returns whether the workload identified by key, from rolloutKey,
+// is assigned to the canary version of pr. The assignment only depends on the
+// key, so all the pods of a workload get the same version, and raising the
+// percentage only ever moves workloads from the stable to the canary version.
+func inCanary(pr *pcv1alpha1.ProxyRollout, key string) bool {
+	return rolloutBucket(key) < pr.Spec.Percentage
+}
+
+func rolloutBucket(key string) int32 {
+	h := fnv.New32a()
+	// Writing to a hash never fails
+	_, _ = h.Write([]byte(key))
+	return int32(h.Sum32() % rolloutBuckets)
+}
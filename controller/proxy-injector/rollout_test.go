@@ -0,0 +1,189 @@
+package injector
+
+import (
+	"fmt"
+	"testing"
+
+	pcv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/proxyconfig/v1alpha1"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSelectProxyRollout(t *testing.T) {
+	api, err := k8s.NewFakeAPI(`
+apiVersion: config.linkerd.io/v1alpha1
+kind: ProxyRollout
+metadata:
+  name: b-staging
+  namespace: linkerd
+spec:
+  version: edge-24.1.2
+  percentage: 50
+  namespaceSelector:
+    matchLabels:
+      env: staging
+`, `
+apiVersion: config.linkerd.io/v1alpha1
+kind: ProxyRollout
+metadata:
+  name: a-team
+  namespace: linkerd
+spec:
+  version: edge-24.1.3
+  percentage: 10
+  namespaceSelector:
+    matchExpressions:
+    - key: team
+      operator: Exists
+`, `
+apiVersion: config.linkerd.io/v1alpha1
+kind: ProxyRollout
+metadata:
+  name: ignored
+  namespace: emojivoto
+spec:
+  version: edge-24.1.4
+  percentage: 100
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	api.Sync(nil)
+	lister := api.ProxyRollout().Lister()
+
+	testCases := []struct {
+		desc     string
+		nsLabels map[string]string
+		expected string
+	}{
+		{
+			desc:     "selected namespace",
+			nsLabels: map[string]string{"env": "staging"},
+			expected: "b-staging",
+		},
+		{
+			desc:     "first rollout in name order",
+			nsLabels: map[string]string{"env": "staging", "team": "books"},
+			expected: "a-team",
+		},
+		{
+			desc:     "namespace not selected",
+			nsLabels: map[string]string{"env": "prod"},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			pr := selectProxyRollout(lister, "linkerd", tc.nsLabels)
+			name := ""
+			if pr != nil {
+				name = pr.Name
+			}
+			if name != tc.expected {
+				t.Fatalf("Expected ProxyRollout %q, got %q", tc.expected, name)
+			}
+		})
+	}
+
+	if pr := selectProxyRollout(nil, "linkerd", map[string]string{"env": "staging"}); pr != nil {
+		t.Fatalf("Expected no ProxyRollout without a lister, got %s", pr.Name)
+	}
+}
+
+func TestInCanary(t *testing.T) {
+	pr := func(percentage int32) *pcv1alpha1.ProxyRollout {
+		return &pcv1alpha1.ProxyRollout{Spec: pcv1alpha1.ProxyRolloutSpec{Version: "edge-24.1.2", Percentage: percentage}}
+	}
+
+	canary := 0
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("uid-%d", i)
+		if inCanary(pr(0), key) {
+			t.Fatalf("Expected %s not to be in the canary at 0%%", key)
+		}
+		if !inCanary(pr(100), key) {
+			t.Fatalf("Expected %s to be in the canary at 100%%", key)
+		}
+		if inCanary(pr(20), key) {
+			canary++
+			if !inCanary(pr(50), key) {
+				t.Fatalf("Expected %s to stay in the canary when raising the percentage", key)
+			}
+		}
+		if inCanary(pr(20), key) != inCanary(pr(20), key) {
+			t.Fatalf("Expected the assignment of %s to be deterministic", key)
+		}
+	}
+	if canary < 150 || canary > 250 {
+		t.Fatalf("Expected about 20%% of workloads in the canary, got %d out of 1000", canary)
+	}
+}
+
+func TestRolloutKey(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		ownerUID types.UID
+		owner    *metav1.OwnerReference
+		kind     string
+		name     string
+		expected string
+	}{
+		{
+			desc:     "pod of a retrieved deployment",
+			ownerUID: "9b1deb4d-3b7d-4bad-9bdd-2b0d7b3dcb6d",
+			owner:    &metav1.OwnerReference{Kind: pkgK8s.Deployment, Name: "web"},
+			kind:     "Pod",
+			name:     "web-5d8f7c9b6-",
+			expected: "9b1deb4d-3b7d-4bad-9bdd-2b0d7b3dcb6d",
+		},
+		{
+			desc:     "pod of a deployment that couldn't be retrieved",
+			owner:    &metav1.OwnerReference{Kind: pkgK8s.Deployment, Name: "web"},
+			kind:     "Pod",
+			name:     "web-5d8f7c9b6-",
+			expected: "emojivoto/deployment/web",
+		},
+		{
+			desc:     "pod of a cronjob",
+			owner:    &metav1.OwnerReference{Kind: pkgK8s.CronJob, Name: "backup"},
+			kind:     "Pod",
+			name:     "backup-28571520-",
+			expected: "emojivoto/cronjob/backup",
+		},
+		{
+			desc:     "workload",
+			kind:     "Deployment",
+			name:     "web",
+			expected: "emojivoto/deployment/web",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			key := rolloutKey(tc.ownerUID, "emojivoto", tc.owner, tc.kind, tc.name)
+			if key != tc.expected {
+				t.Fatalf("Expected key %q, got %q", tc.expected, key)
+			}
+		})
+	}
+}
+
+func TestVersionPinned(t *testing.T) {
+	pinned := map[string]string{pkgK8s.ProxyVersionOverrideAnnotation: "edge-24.1.1"}
+	other := map[string]string{pkgK8s.ProxyLogLevelAnnotation: "debug"}
+
+	if versionPinned(other, nil) {
+		t.Fatal("Expected the version not to be pinned")
+	}
+	if !versionPinned(pinned, other) {
+		t.Fatal("Expected the version to be pinned by the namespace")
+	}
+	if !versionPinned(other, pinned) {
+		t.Fatal("Expected the version to be pinned by the workload")
+	}
+}
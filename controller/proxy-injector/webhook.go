@@ -2,12 +2,15 @@ package injector
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 
+	pclisters "github.com/linkerd/linkerd2/controller/gen/client/listers/proxyconfig/v1alpha1"
 	"github.com/linkerd/linkerd2/controller/k8s"
 	"github.com/linkerd/linkerd2/controller/webhook"
+	l5dcharts "github.com/linkerd/linkerd2/pkg/charts/linkerd2"
 	"github.com/linkerd/linkerd2/pkg/config"
 	"github.com/linkerd/linkerd2/pkg/inject"
 	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
@@ -17,6 +20,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 )
 
@@ -27,8 +31,11 @@ const (
 
 // Inject returns the function that produces an AdmissionResponse containing
 // the patch, if any, to apply to the pod (proxy sidecar and eventually the
-// init container to set it up)
-func Inject(linkerdNamespace string, overrider inject.ValueOverrider) webhook.Handler {
+// init container to set it up). When proxyConfigs is not nil, the ProxyConfigs
+// that apply to the pod are layered on top of the Namespace's annotations.
+// When proxyRollouts is not nil, workloads selected by a ProxyRollout may be
+// assigned its canary proxy version.
+func Inject(linkerdNamespace string, overrider inject.ValueOverrider, proxyConfigs pclisters.ProxyConfigLister, proxyRollouts pclisters.ProxyRolloutLister) webhook.Handler {
 	return func(
 		ctx context.Context,
 		api *k8s.MetadataAPI,
@@ -67,6 +74,12 @@ func Inject(linkerdNamespace string, overrider inject.ValueOverrider) webhook.Ha
 		}
 		log.Infof("received %s", report.ResName())
 
+		// Pod labels are only known once the object has been parsed, so the
+		// ProxyConfigs are resolved now, before any Namespace annotation is
+		// consulted.
+		nsAnnotations, nsAnnotationSources := resolveNsAnnotations(proxyConfigs, linkerdNamespace, request.Namespace, ns.GetAnnotations(), resourceConfig.GetPodLabels())
+		resourceConfig.WithNsAnnotations(nsAnnotations).WithNsAnnotationSources(nsAnnotationSources)
+
 		// If the resource has an owner, then it should be retrieved for recording
 		// events.
 		var parent *metav1.PartialObjectMetadata
@@ -88,6 +101,24 @@ func Inject(linkerdNamespace string, overrider inject.ValueOverrider) webhook.Ha
 			}
 		}
 
+		// Workloads are assigned to a ProxyRollout's canary by their top-level
+		// owner, so that all their pods run the same version, across the
+		// workload's revisions. A version set explicitly on the Namespace, a
+		// ProxyConfig or the workload isn't overridden.
+		var rollout string
+		if pr := selectProxyRollout(proxyRollouts, linkerdNamespace, ns.GetLabels()); pr != nil {
+			var ownerUID types.UID
+			if parent != nil {
+				ownerUID = parent.GetUID()
+			}
+			key := rolloutKey(ownerUID, request.Namespace, resourceConfig.GetOwnerRef(), request.Kind.Kind, report.Name)
+			pinned := versionPinned(resourceConfig.GetNsAnnotations(), resourceConfig.GetWorkloadAnnotations())
+			if !pinned && inCanary(pr, key) {
+				resourceConfig.GetNsAnnotations()[pkgK8s.ProxyVersionOverrideAnnotation] = pr.Spec.Version
+				rollout = pr.Name
+			}
+		}
+
 		configLabels := configToPrometheusLabels(resourceConfig)
 
 		counter, err := proxyInjectionAdmissionRequests.GetMetricWith(admissionRequestLabels(ownerKind, request.Namespace, report.InjectAnnotationAt, configLabels))
@@ -102,6 +133,9 @@ func Inject(linkerdNamespace string, overrider inject.ValueOverrider) webhook.Ha
 		injectable, reasons := report.Injectable()
 		if injectable {
 			resourceConfig.AppendPodAnnotation(pkgK8s.CreatedByAnnotation, fmt.Sprintf("linkerd/proxy-injector %s", version.Version))
+			if rollout != "" {
+				resourceConfig.AppendPodAnnotation(pkgK8s.ProxyRolloutAnnotation, rollout)
+			}
 
 			// If namespace has annotations that do not exist on pod then copy them
 			// over to pod's template.
@@ -122,11 +156,27 @@ func Inject(linkerdNamespace string, overrider inject.ValueOverrider) webhook.Ha
 				}
 			}
 
+			report.PortFindings, err = resourceConfig.AnalyzePorts(overrider)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range report.PortFindings {
+				log.Warnf("%s: %s", report.ResName(), f.Message)
+			}
+
 			patchJSON, err := resourceConfig.GetPodPatch(true, overrider)
 			if err != nil {
 				return nil, err
 			}
 
+			var auditAnnotations map[string]string
+			if webhook.ExplainRequested(ctx) {
+				auditAnnotations, err = explain(resourceConfig, overrider, valuesConfig)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			if parent != nil {
 				recorder.Event(parent, v1.EventTypeNormal, eventTypeInjected, "Linkerd sidecar proxy injected")
 			}
@@ -142,10 +192,12 @@ func Inject(linkerdNamespace string, overrider inject.ValueOverrider) webhook.Ha
 
 			patchType := admissionv1beta1.PatchTypeJSONPatch
 			return &admissionv1beta1.AdmissionResponse{
-				UID:       request.UID,
-				Allowed:   true,
-				PatchType: &patchType,
-				Patch:     patchJSON,
+				UID:              request.UID,
+				Allowed:          true,
+				PatchType:        &patchType,
+				Patch:            patchJSON,
+				AuditAnnotations: auditAnnotations,
+				Warnings:         inject.Warnings(report.PortFindings),
 			}, nil
 		}
 
@@ -216,6 +268,24 @@ func Inject(linkerdNamespace string, overrider inject.ValueOverrider) webhook.Ha
 	}
 }
 
+// explain returns the provenance of the injected proxy's settings, encoded
+// in the webhook.ExplanationAuditAnnotation.
+func explain(conf *inject.ResourceConfig, overrider inject.ValueOverrider, values *l5dcharts.Values) (map[string]string, error) {
+	defaults, err := l5dcharts.NewValues()
+	if err != nil {
+		return nil, err
+	}
+	explanation, err := conf.Explain(overrider, defaults, values)
+	if err != nil {
+		return nil, err
+	}
+	explanationJSON, err := json.Marshal(explanation)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{webhook.ExplanationAuditAnnotation: string(explanationJSON)}, nil
+}
+
 func ownerRetriever(ctx context.Context, api *k8s.MetadataAPI, ns string) inject.OwnerRetrieverFunc {
 	return func(p *v1.Pod) (string, string, error) {
 		p.SetNamespace(ns)
@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/linkerd/linkerd2/pkg/util"
+	log "github.com/sirupsen/logrus"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DryRunPath is the path of the endpoint that runs the webhook's handler
+	// on a manifest, without admitting anything, and returns the resulting
+	// AdmissionResponse. The manifest is POSTed as the request body, and the
+	// caller authenticates with a bearer token.
+	DryRunPath = "/dry-run"
+
+	// DryRunNamespaceParam is the query parameter holding the namespace used
+	// for manifests that don't specify one
+	DryRunNamespaceParam = "namespace"
+
+	// DryRunExplainParam is the query parameter asking the handler to explain
+	// its response
+	DryRunExplainParam = "explain"
+)
+
+// dryRunError is an error with the HTTP status to respond with
+type dryRunError struct {
+	status int
+	err    error
+}
+
+func (e *dryRunError) Error() string {
+	return e.err.Error()
+}
+
+func (s *Server) serveDryRun(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(res, fmt.Sprintf("method %s not allowed", req.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	response, err := s.dryRun(req)
+	if err != nil {
+		status := http.StatusBadRequest
+		var dryRunErr *dryRunError
+		if errors.As(err, &dryRunErr) {
+			status = dryRunErr.status
+		}
+		http.Error(res, err.Error(), status)
+		return
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	if _, err := res.Write(responseJSON); err != nil {
+		log.Errorf("failed to write dry-run response: %s", err)
+	}
+}
+
+func (s *Server) dryRun(req *http.Request) (*admissionv1beta1.AdmissionResponse, error) {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil, &dryRunError{http.StatusUnauthorized, errors.New("a bearer token is required")}
+	}
+
+	var data []byte
+	if req.Body != nil {
+		var err error
+		data, err = util.ReadAllLimit(req.Body, 10*util.MB)
+		if err != nil {
+			return nil, &dryRunError{http.StatusInternalServerError, err}
+		}
+	}
+	raw, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	var meta metav1.PartialObjectMetadata
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	if meta.Kind == "" {
+		return nil, errors.New("manifest is missing the \"kind\" field")
+	}
+	gv, err := schema.ParseGroupVersion(meta.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid apiVersion: %w", err)
+	}
+
+	namespace := meta.Namespace
+	if namespace == "" {
+		namespace = req.URL.Query().Get(DryRunNamespaceParam)
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	user, err := s.authorizeDryRun(req.Context(), token, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := req.Context()
+	if explain, _ := strconv.ParseBool(req.URL.Query().Get(DryRunExplainParam)); explain {
+		ctx = WithExplain(ctx)
+	}
+
+	dryRun := true
+	request := &admissionv1beta1.AdmissionRequest{
+		UID:       uuid.NewUUID(),
+		Kind:      metav1.GroupVersionKind{Group: gv.Group, Version: gv.Version, Kind: meta.Kind},
+		Namespace: namespace,
+		Name:      meta.Name,
+		Operation: admissionv1beta1.Create,
+		UserInfo:  *user,
+		Object:    runtime.RawExtension{Raw: raw},
+		DryRun:    &dryRun,
+	}
+	log.Infof("received dry-run request %q from %s for %s/%s", request.UID, user.Username, namespace, meta.Name)
+
+	// Events are dropped: nothing is actually admitted
+	response, err := s.handler(ctx, s.metadataAPI, request, &record.FakeRecorder{})
+	if err != nil {
+		return nil, &dryRunError{http.StatusUnprocessableEntity, err}
+	}
+	return response, nil
+}
+
+// authorizeDryRun authenticates the token's user, who must be allowed to
+// create pods in namespace.
+func (s *Server) authorizeDryRun(ctx context.Context, token, namespace string) (*authnv1.UserInfo, error) {
+	review, err := s.dryRunAPI.AuthenticationV1().TokenReviews().Create(ctx, &authnv1.TokenReview{
+		Spec: authnv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, &dryRunError{http.StatusInternalServerError, fmt.Errorf("failed to review token: %w", err)}
+	}
+	if !review.Status.Authenticated {
+		return nil, &dryRunError{http.StatusUnauthorized, fmt.Errorf("authentication failed: %s", review.Status.Error)}
+	}
+	user := review.Status.User
+
+	extra := map[string]authzv1.ExtraValue{}
+	for k, v := range user.Extra {
+		extra[k] = authzv1.ExtraValue(v)
+	}
+	sar, err := s.dryRunAPI.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authzv1.SubjectAccessReview{
+		Spec: authzv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authzv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "create",
+				Resource:  "pods",
+			},
+			User:   user.Username,
+			Groups: user.Groups,
+			UID:    user.UID,
+			Extra:  extra,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, &dryRunError{http.StatusInternalServerError, fmt.Errorf("failed to review access: %w", err)}
+	}
+	if !sar.Status.Allowed {
+		return nil, &dryRunError{http.StatusForbidden, fmt.Errorf("%s is not allowed to create pods in namespace %s", user.Username, namespace)}
+	}
+	return &user, nil
+}
+
+// enableDryRun serves the DryRunPath endpoint, using client to authenticate
+// and authorize its callers.
+func (s *Server) enableDryRun(client kubernetes.Interface) {
+	s.dryRunAPI = client
+}
@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestServeDryRun(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web
+`
+
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authnv1.TokenReview)
+		if review.Spec.Token == "valid" || review.Spec.Token == "forbidden" {
+			review.Status.Authenticated = true
+			review.Status.User = authnv1.UserInfo{Username: review.Spec.Token}
+		}
+		return true, review, nil
+	})
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		sar := action.(k8stesting.CreateAction).GetObject().(*authzv1.SubjectAccessReview)
+		attrs := sar.Spec.ResourceAttributes
+		sar.Status.Allowed = sar.Spec.User == "valid" && attrs.Namespace == "emojivoto" && attrs.Verb == "create" && attrs.Resource == "pods"
+		return true, sar, nil
+	})
+
+	var received *admissionv1beta1.AdmissionRequest
+	handler := func(ctx context.Context, _ *k8s.MetadataAPI, request *admissionv1beta1.AdmissionRequest, _ record.EventRecorder) (*admissionv1beta1.AdmissionResponse, error) {
+		received = request
+		response := &admissionv1beta1.AdmissionResponse{UID: request.UID, Allowed: true}
+		if ExplainRequested(ctx) {
+			response.AuditAnnotations = map[string]string{ExplanationAuditAnnotation: "{}"}
+		}
+		return response, nil
+	}
+
+	testServer := getConfiguredServer(mockHTTPServer, nil, handler, nil)
+
+	t.Run("disabled", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, DryRunPath+"?namespace=emojivoto", strings.NewReader(manifest))
+		request.Header.Set("Authorization", "Bearer valid")
+		recorder := httptest.NewRecorder()
+		testServer.serve(recorder, request)
+		if received != nil || recorder.Code != http.StatusNotFound {
+			t.Fatalf("Expected the dry-run endpoint to be disabled, got status %d", recorder.Code)
+		}
+	})
+
+	testServer.enableDryRun(client)
+
+	testCases := []struct {
+		desc   string
+		token  string
+		path   string
+		status int
+	}{
+		{"no token", "", DryRunPath + "?namespace=emojivoto", http.StatusUnauthorized},
+		{"invalid token", "invalid", DryRunPath + "?namespace=emojivoto", http.StatusUnauthorized},
+		{"forbidden user", "forbidden", DryRunPath + "?namespace=emojivoto", http.StatusForbidden},
+		{"forbidden namespace", "valid", DryRunPath, http.StatusForbidden},
+		{"allowed", "valid", DryRunPath + "?namespace=emojivoto&explain=true", http.StatusOK},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			received = nil
+			request := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(manifest))
+			if tc.token != "" {
+				request.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			recorder := httptest.NewRecorder()
+			testServer.serve(recorder, request)
+
+			if recorder.Code != tc.status {
+				t.Fatalf("Expected status %d, got %d: %s", tc.status, recorder.Code, recorder.Body)
+			}
+			if tc.status != http.StatusOK {
+				if received != nil {
+					t.Fatalf("Expected the handler not to be called")
+				}
+				return
+			}
+
+			if received.Kind.Kind != "Deployment" || received.Kind.Group != "apps" || received.Namespace != "emojivoto" || received.Name != "web" {
+				t.Fatalf("Unexpected admission request: %+v", received)
+			}
+			if received.DryRun == nil || !*received.DryRun {
+				t.Fatalf("Expected a dry-run admission request")
+			}
+			if received.UserInfo.Username != "valid" {
+				t.Fatalf("Expected the request user to be set, got %q", received.UserInfo.Username)
+			}
+
+			var response admissionv1beta1.AdmissionResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if !response.Allowed || response.AuditAnnotations[ExplanationAuditAnnotation] != "{}" {
+				t.Fatalf("Unexpected admission response: %+v", response)
+			}
+		})
+	}
+}
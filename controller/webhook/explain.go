@@ -0,0 +1,22 @@
+package webhook
+
+import "context"
+
+// ExplanationAuditAnnotation is the AdmissionResponse audit annotation holding
+// the handler's explanation, when requested
+const ExplanationAuditAnnotation = "explanation"
+
+type explainKey struct{}
+
+// WithExplain returns a copy of ctx asking the handler to explain its
+// response through the ExplanationAuditAnnotation.
+func WithExplain(ctx context.Context) context.Context {
+	return context.WithValue(ctx, explainKey{}, true)
+}
+
+// ExplainRequested returns true when the handler is asked to explain its
+// response.
+func ExplainRequested(ctx context.Context) bool {
+	explain, _ := ctx.Value(explainKey{}).(bool)
+	return explain
+}
@@ -13,7 +13,9 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-// Launch sets up and starts the webhook and metrics servers
+// Launch sets up and starts the webhook and metrics servers. When
+// enableDryRun is true, the webhook server also serves the DryRunPath
+// endpoint. When validator is not nil, it handles the ValidatePath endpoint.
 func Launch(
 	ctx context.Context,
 	apiresources []k8s.APIResource,
@@ -23,6 +25,8 @@ func Launch(
 	addr string,
 	kubeconfig string,
 	enablePprof bool,
+	enableDryRun bool,
+	validator Handler,
 ) {
 	ready := false
 	adminServer := admin.NewServer(metricsAddr, enablePprof, &ready)
@@ -56,7 +60,7 @@ func Launch(
 		log.Fatalf("failed to initialize Kubernetes API: %s", err)
 	}
 
-	s, err := NewServer(ctx, k8sAPI, metadataAPI, addr, pkgk8s.MountPathTLSBase, handler, component)
+	s, err := NewServer(ctx, k8sAPI, metadataAPI, addr, pkgk8s.MountPathTLSBase, handler, component, enableDryRun, validator)
 	if err != nil {
 		//nolint:gocritic
 		log.Fatalf("failed to initialize the webhook server: %s", err)
@@ -18,6 +18,7 @@ import (
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/record"
@@ -33,6 +34,10 @@ type Handler func(
 	record.EventRecorder,
 ) (*admissionv1beta1.AdmissionResponse, error)
 
+// ValidatePath is the path of the endpoint that runs the server's validating
+// handler, when it has one. Every other path runs its main handler.
+const ValidatePath = "/validate"
+
 // Server describes the https server implementing the webhook
 type Server struct {
 	*http.Server
@@ -40,6 +45,14 @@ type Server struct {
 	handler     Handler
 	certValue   *atomic.Value
 	recorder    record.EventRecorder
+
+	// validator is the handler of the ValidatePath endpoint, which is only
+	// served when it's set
+	validator Handler
+
+	// dryRunAPI is used to authorize the callers of the DryRunPath endpoint,
+	// which is only served when it's set
+	dryRunAPI kubernetes.Interface
 }
 
 // NewServer returns a new instance of Server
@@ -50,6 +63,8 @@ func NewServer(
 	addr, certPath string,
 	handler Handler,
 	component string,
+	enableDryRun bool,
+	validator Handler,
 ) (*Server, error) {
 	updateEvent := make(chan struct{})
 	errEvent := make(chan error)
@@ -78,6 +93,10 @@ func NewServer(
 	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: component})
 
 	s := getConfiguredServer(server, metadataAPI, handler, recorder)
+	if enableDryRun {
+		s.enableDryRun(api)
+	}
+	s.validator = validator
 	if err := watcher.UpdateCert(s.certValue); err != nil {
 		log.Fatalf("Failed to initialized certificate: %s", err)
 	}
@@ -99,7 +118,7 @@ func getConfiguredServer(
 	recorder record.EventRecorder,
 ) *Server {
 	var emptyCert atomic.Value
-	s := &Server{httpServer, metadataAPI, handler, &emptyCert, recorder}
+	s := &Server{httpServer, metadataAPI, handler, &emptyCert, recorder, nil, nil}
 	s.Handler = http.HandlerFunc(s.serve)
 	httpServer.TLSConfig.GetCertificate = s.getCertificate
 	return s
@@ -122,6 +141,20 @@ func (s *Server) getCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error
 }
 
 func (s *Server) serve(res http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == DryRunPath {
+		if s.dryRunAPI == nil {
+			http.NotFound(res, req)
+			return
+		}
+		s.serveDryRun(res, req)
+		return
+	}
+
+	handler := s.handler
+	if req.URL.Path == ValidatePath && s.validator != nil {
+		handler = s.validator
+	}
+
 	var (
 		data []byte
 		err  error
@@ -139,7 +172,7 @@ func (s *Server) serve(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	response, err := s.processReq(req.Context(), data)
+	response, err := s.processReq(req.Context(), handler, data)
 	if err != nil {
 		http.Error(res, err.Error(), http.StatusBadRequest)
 		return
@@ -157,7 +190,7 @@ func (s *Server) serve(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func (s *Server) processReq(ctx context.Context, data []byte) (*admissionv1beta1.AdmissionReview, error) {
+func (s *Server) processReq(ctx context.Context, handler Handler, data []byte) (*admissionv1beta1.AdmissionReview, error) {
 	admissionReview, err := decode(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode admission review request: %w", err)
@@ -168,7 +201,7 @@ func (s *Server) processReq(ctx context.Context, data []byte) (*admissionv1beta1
 	log.Infof("received admission review request %q", admissionReview.Request.UID)
 	log.Debugf("admission request: %+v", admissionReview.Request)
 
-	admissionResponse, err := s.handler(ctx, s.metadataAPI, admissionReview.Request, s.recorder)
+	admissionResponse, err := handler(ctx, s.metadataAPI, admissionReview.Request, s.recorder)
 	if err != nil {
 		log.Error("failed to run webhook handler. Reason: ", err)
 		admissionReview.Response = &admissionv1beta1.AdmissionResponse{
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -12,6 +13,8 @@ import (
 	"time"
 
 	"github.com/linkerd/linkerd2/controller/k8s"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/client-go/tools/record"
 )
 
 var mockHTTPServer = &http.Server{
@@ -45,6 +48,31 @@ func TestServe(t *testing.T) {
 	})
 }
 
+func TestServeValidate(t *testing.T) {
+	mutate := func(context.Context, *k8s.MetadataAPI, *admissionv1beta1.AdmissionRequest, record.EventRecorder) (*admissionv1beta1.AdmissionResponse, error) {
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}, nil
+	}
+	validate := func(context.Context, *k8s.MetadataAPI, *admissionv1beta1.AdmissionRequest, record.EventRecorder) (*admissionv1beta1.AdmissionResponse, error) {
+		return &admissionv1beta1.AdmissionResponse{Allowed: false}, nil
+	}
+	testServer := getConfiguredServer(mockHTTPServer, nil, mutate, nil)
+	testServer.validator = validate
+
+	review := []byte(`{"request":{"uid":"1"}}`)
+	for path, allowed := range map[string]bool{"/": true, ValidatePath: false} {
+		recorder := httptest.NewRecorder()
+		testServer.serve(recorder, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(review)))
+
+		var response admissionv1beta1.AdmissionReview
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if response.Response == nil || response.Response.Allowed != allowed {
+			t.Errorf("%s: expected allowed to be %t, got %+v", path, allowed, response.Response)
+		}
+	}
+}
+
 func TestShutdown(t *testing.T) {
 	testServer := getConfiguredServer(mockHTTPServer, nil, nil, nil)
 
@@ -59,9 +59,10 @@ var (
 )
 
 type checkOptions struct {
-	wait    time.Duration
-	output  string
-	timeout time.Duration
+	wait        time.Duration
+	output      string
+	timeout     time.Duration
+	checkConfig string
 }
 
 func newCheckOptions() *checkOptions {
@@ -73,8 +74,8 @@ func newCheckOptions() *checkOptions {
 }
 
 func (options *checkOptions) validate() error {
-	if options.output != healthcheck.TableOutput && options.output != healthcheck.JSONOutput && options.output != healthcheck.ShortOutput {
-		return fmt.Errorf("Invalid output type '%s'. Supported output types are: %s, %s, %s", options.output, healthcheck.JSONOutput, healthcheck.TableOutput, healthcheck.ShortOutput)
+	if options.output != healthcheck.TableOutput && options.output != healthcheck.JSONOutput && options.output != healthcheck.ShortOutput && !healthcheck.IsReportOutput(options.output) {
+		return fmt.Errorf("Invalid output type '%s'. Supported output types are: %s, %s, %s, %s, %s", options.output, healthcheck.JSONOutput, healthcheck.TableOutput, healthcheck.ShortOutput, healthcheck.JUnitOutput, healthcheck.SARIFOutput)
 	}
 	return nil
 }
@@ -122,9 +123,10 @@ non-zero exit code.`,
 			return configureAndRunChecks(stdout, stderr, options)
 		},
 	}
-	cmd.Flags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of: table, json, short")
+	cmd.Flags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of: table, json, short, junit, sarif")
 	cmd.Flags().DurationVar(&options.wait, "wait", options.wait, "Maximum allowed time for all tests to pass")
 	cmd.Flags().DurationVar(&options.timeout, "timeout", options.timeout, "Timeout for calls to the Kubernetes API")
+	cmd.Flags().StringVar(&options.checkConfig, "check-config", options.checkConfig, fmt.Sprintf("Path to a YAML file enabling or disabling categories and checks, overriding their thresholds, and turning warnings into failures (default: $%s)", healthcheck.CheckConfigEnvVar))
 	cmd.Flags().Bool("proxy", false, "")
 	cmd.Flags().MarkHidden("proxy")
 	cmd.Flags().StringP("namespace", "n", "", "")
@@ -143,6 +145,10 @@ func configureAndRunChecks(wout io.Writer, werr io.Writer, options *checkOptions
 	if err != nil {
 		return fmt.Errorf("Validation error when executing check command: %w", err)
 	}
+	checkConfig, err := healthcheck.LoadCheckConfigOrEnv(options.checkConfig)
+	if err != nil {
+		return err
+	}
 	checks := []healthcheck.CategoryID{
 		LinkerdMulticlusterExtensionCheck,
 	}
@@ -154,6 +160,7 @@ func configureAndRunChecks(wout io.Writer, werr io.Writer, options *checkOptions
 		ImpersonateGroup:      impersonateGroup,
 		APIAddr:               apiAddr,
 		RetryDeadline:         time.Now().Add(options.wait),
+		CheckConfig:           checkConfig,
 	})
 
 	err = linkerdHC.InitializeKubeAPIClient()
@@ -183,25 +190,30 @@ func multiclusterCategory(hc *healthChecker, wait time.Duration) *healthcheck.Ca
 	checkers := []healthcheck.Checker{}
 	checkers = append(checkers,
 		*healthcheck.NewChecker("Link CRD exists").
+			WithID("l5d-multicluster-link-crd-exists").
 			WithHintAnchor("l5d-multicluster-link-crd-exists").
 			Fatal().
 			WithCheck(func(ctx context.Context) error { return hc.checkLinkCRD(ctx) }))
 	checkers = append(checkers,
 		*healthcheck.NewChecker("Link resources are valid").
+			WithID("l5d-multicluster-links-are-valid").
 			WithHintAnchor("l5d-multicluster-links-are-valid").
 			Fatal().
 			WithCheck(func(ctx context.Context) error { return hc.checkLinks(ctx) }))
 	checkers = append(checkers,
 		*healthcheck.NewChecker("Link and CLI versions match").
+			WithID("l5d-multicluster-links-version").
 			WithHintAnchor("l5d-multicluster-links-version").
 			Warning().
 			WithCheck(func(ctx context.Context) error { return hc.checkLinkVersions() }))
 	checkers = append(checkers,
 		*healthcheck.NewChecker("remote cluster access credentials are valid").
+			WithID("l5d-smc-target-clusters-access").
 			WithHintAnchor("l5d-smc-target-clusters-access").
 			WithCheck(func(ctx context.Context) error { return hc.checkRemoteClusterConnectivity(ctx) }))
 	checkers = append(checkers,
 		*healthcheck.NewChecker("clusters share trust anchors").
+			WithID("l5d-multicluster-clusters-share-anchors").
 			WithHintAnchor("l5d-multicluster-clusters-share-anchors").
 			WithCheck(func(ctx context.Context) error {
 				localAnchors, err := tls.DecodePEMCertificates(hc.LinkerdConfig().IdentityTrustAnchorsPEM)
@@ -212,12 +224,14 @@ func multiclusterCategory(hc *healthChecker, wait time.Duration) *healthcheck.Ca
 			}))
 	checkers = append(checkers,
 		*healthcheck.NewChecker("service mirror controller has required permissions").
+			WithID("l5d-multicluster-source-rbac-correct").
 			WithHintAnchor("l5d-multicluster-source-rbac-correct").
 			WithCheck(func(ctx context.Context) error {
 				return hc.checkServiceMirrorLocalRBAC(ctx)
 			}))
 	checkers = append(checkers,
 		*healthcheck.NewChecker("service mirror controllers are running").
+			WithID("l5d-multicluster-service-mirror-running").
 			WithHintAnchor("l5d-multicluster-service-mirror-running").
 			WithRetryDeadline(hc.RetryDeadline).
 			SurfaceErrorOnRetry().
@@ -226,6 +240,7 @@ func multiclusterCategory(hc *healthChecker, wait time.Duration) *healthcheck.Ca
 			}))
 	checkers = append(checkers,
 		*healthcheck.NewChecker("extension is managing controllers").
+			WithID("l5d-multicluster-managed-controllers").
 			WithHintAnchor("l5d-multicluster-managed-controllers").
 			Warning().
 			WithCheck(func(ctx context.Context) error {
@@ -233,18 +248,21 @@ func multiclusterCategory(hc *healthChecker, wait time.Duration) *healthcheck.Ca
 			}))
 	checkers = append(checkers,
 		*healthcheck.NewChecker("probe services able to communicate with all gateway mirrors").
+			WithID("l5d-multicluster-gateways-endpoints").
 			WithHintAnchor("l5d-multicluster-gateways-endpoints").
 			WithCheck(func(ctx context.Context) error {
 				return hc.checkIfGatewayMirrorsHaveEndpoints(ctx, wait)
 			}))
 	checkers = append(checkers,
 		*healthcheck.NewChecker("all mirror services have endpoints").
+			WithID("l5d-multicluster-services-endpoints").
 			WithHintAnchor("l5d-multicluster-services-endpoints").
 			WithCheck(func(ctx context.Context) error {
 				return hc.checkIfMirrorServicesHaveEndpoints(ctx)
 			}))
 	checkers = append(checkers,
 		*healthcheck.NewChecker("all mirror services are part of a Link").
+			WithID("l5d-multicluster-orphaned-services").
 			WithHintAnchor("l5d-multicluster-orphaned-services").
 			Warning().
 			WithCheck(func(ctx context.Context) error {
@@ -253,6 +271,7 @@ func multiclusterCategory(hc *healthChecker, wait time.Duration) *healthcheck.Ca
 
 	checkers = append(checkers,
 		*healthcheck.NewChecker("multicluster extension proxies are healthy").
+			WithID("l5d-multicluster-proxy-healthy").
 			WithHintAnchor("l5d-multicluster-proxy-healthy").
 			Warning().
 			WithRetryDeadline(hc.RetryDeadline).
@@ -269,6 +288,7 @@ func multiclusterCategory(hc *healthChecker, wait time.Duration) *healthcheck.Ca
 
 	checkers = append(checkers,
 		*healthcheck.NewChecker("multicluster extension proxies are up-to-date").
+			WithID("l5d-multicluster-proxy-cp-version").
 			WithHintAnchor("l5d-multicluster-proxy-cp-version").
 			Warning().
 			WithCheck(func(ctx context.Context) error {
@@ -301,6 +321,7 @@ func multiclusterCategory(hc *healthChecker, wait time.Duration) *healthcheck.Ca
 
 	checkers = append(checkers,
 		*healthcheck.NewChecker("multicluster extension proxies and cli versions match").
+			WithID("l5d-multicluster-proxy-cli-version").
 			WithHintAnchor("l5d-multicluster-proxy-cli-version").
 			Warning().
 			WithCheck(func(ctx context.Context) error {
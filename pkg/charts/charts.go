@@ -31,6 +31,7 @@ var (
 		"partials/templates/_network-validator.tpl",
 		"partials/templates/_proxy-config-ann.tpl",
 		"partials/templates/_proxy-init.tpl",
+		"partials/templates/_proxy-supervisor.tpl",
 		"partials/templates/_proxy.tpl",
 		"partials/templates/_pull-secrets.tpl",
 		"partials/templates/_resources.tpl",
@@ -60,6 +60,7 @@ type (
 
 		DestinationController *DestinationController `json:"destinationController"`
 		Heartbeat             map[string]interface{} `json:"heartbeat"`
+		CheckRunner           map[string]interface{} `json:"checkRunner"`
 		SPValidator           map[string]interface{} `json:"spValidator"`
 
 		PodAnnotations    map[string]string `json:"podAnnotations"`
@@ -149,6 +150,7 @@ type (
 		AccessLog                            string           `json:"accessLog"`
 		ShutdownGracePeriod                  string           `json:"shutdownGracePeriod"`
 		NativeSidecar                        bool             `json:"nativeSidecar"`
+		EnableJobSupervisor                  bool             `json:"enableJobSupervisor"`
 		StartupProbe                         *StartupProbe    `json:"startupProbe"`
 		ReadinessProbe                       *Probe           `json:"readinessProbe"`
 		LivenessProbe                        *Probe           `json:"livenessProbe"`
@@ -338,11 +340,14 @@ type (
 	// Identity contains the fields to set the identity variables in the proxy
 	// sidecar container
 	Identity struct {
-		ExternalCA                    bool              `json:"externalCA"`
-		ServiceAccountTokenProjection bool              `json:"serviceAccountTokenProjection"`
-		Issuer                        *Issuer           `json:"issuer"`
-		KubeAPI                       *KubeAPI          `json:"kubeAPI"`
-		PodAnnotations                map[string]string `json:"podAnnotations"`
+		ExternalCA                    bool     `json:"externalCA"`
+		ServiceAccountTokenProjection bool     `json:"serviceAccountTokenProjection"`
+		Issuer                        *Issuer  `json:"issuer"`
+		KubeAPI                       *KubeAPI `json:"kubeAPI"`
+		Spiffe                        *Spiffe  `json:"spiffe"`
+
+		ExternalWorkloadAttestation *ExternalWorkloadAttestation `json:"externalWorkloadAttestation"`
+		PodAnnotations              map[string]string            `json:"podAnnotations"`
 
 		AdditionalEnv   []corev1.EnvVar `json:"additionalEnv"`
 		ExperimentalEnv []corev1.EnvVar `json:"experimentalEnv"`
@@ -354,6 +359,31 @@ type (
 		ClockSkewAllowance string     `json:"clockSkewAllowance"`
 		IssuanceLifetime   string     `json:"issuanceLifetime"`
 		TLS                *IssuerTLS `json:"tls"`
+
+		LifetimeOverrides *LifetimeOverrides `json:"lifetimeOverrides"`
+	}
+
+	// LifetimeOverrides configures per-Namespace and per-ServiceAccount
+	// issuance lifetimes
+	LifetimeOverrides struct {
+		Enabled     bool   `json:"enabled"`
+		MinLifetime string `json:"minLifetime"`
+		MaxLifetime string `json:"maxLifetime"`
+	}
+
+	// Spiffe configures the SPIFFE interoperability of the identity service
+	Spiffe struct {
+		URISAN         bool `json:"uriSAN"`
+		BundleEndpoint bool `json:"bundleEndpoint"`
+	}
+
+	// ExternalWorkloadAttestation configures the validation of attestation
+	// documents presented by ExternalWorkloads
+	ExternalWorkloadAttestation struct {
+		Issuer       string `json:"issuer"`
+		Audience     string `json:"audience"`
+		SubjectClaim string `json:"subjectClaim"`
+		JWKS         string `json:"jwks"`
 	}
 
 	// KubeAPI contains the kube-apiserver client config
@@ -368,6 +398,15 @@ type (
 		PodAnnotations  map[string]string `json:"podAnnotations"`
 		AdditionalEnv   []corev1.EnvVar   `json:"additionalEnv"`
 		ExperimentalEnv []corev1.EnvVar   `json:"experimentalEnv"`
+		Enforcement     *Enforcement      `json:"enforcement"`
+	}
+
+	// Enforcement configures the proxy injector's validating webhook, which
+	// denies the admission of pods that don't comply with the meshing policy
+	Enforcement struct {
+		Enabled       bool   `json:"enabled"`
+		ClusterWide   bool   `json:"clusterWide"`
+		FailurePolicy string `json:"failurePolicy"`
 	}
 
 	// Webhook Helm variables for a webhook
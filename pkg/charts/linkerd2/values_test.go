@@ -102,6 +102,11 @@ func TestNewValues(t *testing.T) {
 			},
 			PodAnnotations: map[string]string{},
 		},
+		CheckRunner: map[string]interface{}{
+			"enabled":    false,
+			"interval":   "5m",
+			"categories": []interface{}{},
+		},
 		SPValidator: map[string]interface{}{
 			"livenessProbe":  map[string]interface{}{"timeoutSeconds": 1.0},
 			"readinessProbe": map[string]interface{}{"timeoutSeconds": 1.0},
@@ -261,11 +266,19 @@ func TestNewValues(t *testing.T) {
 				IssuanceLifetime:   "24h0m0s",
 				TLS:                &IssuerTLS{},
 				Scheme:             "linkerd.io/tls",
+				LifetimeOverrides: &LifetimeOverrides{
+					MinLifetime: "1h0m0s",
+				},
 			},
 			KubeAPI: &KubeAPI{
 				ClientQPS:   100,
 				ClientBurst: 200,
 			},
+			Spiffe: &Spiffe{},
+			ExternalWorkloadAttestation: &ExternalWorkloadAttestation{
+				Audience:     "identity.l5d.io",
+				SubjectClaim: "sub",
+			},
 			PodAnnotations: map[string]string{},
 		},
 		NodeSelector: map[string]string{
@@ -281,6 +294,7 @@ func TestNewValues(t *testing.T) {
 		ProxyInjector: &ProxyInjector{
 			Webhook:        Webhook{TLS: &TLS{}, NamespaceSelector: namespaceSelectorInjector},
 			PodAnnotations: map[string]string{},
+			Enforcement:    &Enforcement{FailurePolicy: "Fail"},
 		},
 		ProfileValidator: &Webhook{TLS: &TLS{}, NamespaceSelector: namespaceSelectorSimple},
 		PolicyValidator:  &Webhook{TLS: &TLS{}, NamespaceSelector: namespaceSelectorSimple},
@@ -142,6 +142,11 @@ const (
 	// extensions discovered in the cluster at runtime
 	LinkerdExtensionChecks CategoryID = "linkerd-extension-checks"
 
+	// LinkerdConnectivityChecks adds checks that deploy a probe server and
+	// clients, to validate the mTLS, opaque ports and policy paths of the
+	// data plane
+	LinkerdConnectivityChecks CategoryID = "linkerd-connectivity"
+
 	// LinkerdCNIResourceLabel is the label key that is used to identify
 	// whether a Kubernetes resource is related to the install-cni command
 	// The value is expected to be "true", "false" or "", where "false" and
@@ -171,9 +176,7 @@ const (
 // AllowedClockSkew sets the allowed skew in clock synchronization
 // between the system running inject command and the node(s), being
 // based on assumed node's heartbeat interval (5 minutes) plus default TLS
-// clock skew allowance.
-//
-// TODO: Make this default value overridable, e.g. by CLI flag
+// clock skew allowance. It can be overridden through the CheckConfig.
 const AllowedClockSkew = 5*time.Minute + tls.DefaultClockSkewAllowance
 
 var linkerdHAControlPlaneComponents = []string{
@@ -274,6 +277,10 @@ func (e VerboseSuccess) Error() string {
 
 // Checker is a smallest unit performing a single check
 type Checker struct {
+	// id identifies the check in the check-config file; unlike the
+	// description and the hint anchor, it's unique and stable
+	id string
+
 	// description is the short description that's printed to the command line
 	// when the check is executed
 	description string
@@ -303,6 +310,10 @@ type Checker struct {
 	// check is the function that's called to execute the check; if the function
 	// returns an error, the check fails
 	check func(context.Context) error
+
+	// remediation is the function that's called to find the fixes of a failed
+	// check, when remediation is enabled (default: no fixes)
+	remediation Remediation
 }
 
 // NewChecker returns a new instance of checker type
@@ -313,6 +324,12 @@ func NewChecker(description string) *Checker {
 	}
 }
 
+// WithID returns a checker with the given ID
+func (c *Checker) WithID(id string) *Checker {
+	c.id = id
+	return c
+}
+
 // WithHintAnchor returns a checker with the given hint anchor
 func (c *Checker) WithHintAnchor(hint string) *Checker {
 	c.hintAnchor = hint
@@ -349,16 +366,26 @@ func (c *Checker) WithCheck(check func(context.Context) error) *Checker {
 	return c
 }
 
+// WithRemediation returns a checker with the provided remediation func
+func (c *Checker) WithRemediation(remediation Remediation) *Checker {
+	c.remediation = remediation
+	return c
+}
+
 // CheckResult encapsulates a check's identifying information and output
 // Note there exists an analogous user-facing type, `cmd.check`, for output via
 // `linkerd check -o json`.
 type CheckResult struct {
 	Category    CategoryID
+	ID          string `json:",omitempty"`
 	Description string
 	HintURL     string
 	Retry       bool
 	Warning     bool
 	Err         error
+	// Fixes remediate the failure of the check; they're only set when
+	// remediation is enabled
+	Fixes []*Fix `json:"-"`
 }
 
 // CheckObserver receives the results of each check.
@@ -384,6 +411,11 @@ func NewCategory(id CategoryID, checkers []Checker, enabled bool) *Category {
 	}
 }
 
+// Enabled returns whether the checkers of the category are run
+func (c *Category) Enabled() bool {
+	return c.enabled
+}
+
 // WithHintBaseURL returns a Category with the provided hintBaseURL
 func (c *Category) WithHintBaseURL(hintBaseURL string) *Category {
 	c.hintBaseURL = hintBaseURL
@@ -407,6 +439,13 @@ type Options struct {
 	InstallManifest       string
 	CRDManifest           string
 	ChartValues           *l5dcharts.Values
+	// Remediate enables finding the fixes of the failed checks
+	Remediate bool
+	// Connectivity configures the LinkerdConnectivityChecks
+	Connectivity ConnectivityOptions
+	// CheckConfig enables or disables categories and checks, and overrides
+	// their thresholds
+	CheckConfig *CheckConfig
 }
 
 // HealthChecker encapsulates all health check checkers, and clients required to
@@ -426,6 +465,8 @@ type HealthChecker struct {
 	issuerCert       *tls.Cred
 	trustAnchors     []*x509.Certificate
 	cniDaemonSet     *appsv1.DaemonSet
+
+	connectivityProbe *connectivityProbe
 }
 
 // Runner is implemented by any health-checkers that can be triggered with RunChecks()
@@ -518,6 +559,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			KubernetesAPIChecks,
 			[]Checker{
 				{
+					id:          "k8s-api-client",
 					description: "can initialize the client",
 					hintAnchor:  "k8s-api",
 					fatal:       true,
@@ -527,6 +569,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "k8s-api-query",
 					description: "can query the Kubernetes API",
 					hintAnchor:  "k8s-api",
 					fatal:       true,
@@ -542,6 +585,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			KubernetesVersionChecks,
 			[]Checker{
 				{
+					id:          "k8s-version",
 					description: "is running the minimum Kubernetes API version",
 					hintAnchor:  "k8s-version",
 					check: func(context.Context) error {
@@ -555,6 +599,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdPreInstallChecks,
 			[]Checker{
 				{
+					id:          "pre-ns",
 					description: "control plane namespace does not already exist",
 					hintAnchor:  "pre-ns",
 					check: func(ctx context.Context) error {
@@ -562,6 +607,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "pre-k8s-cluster-k8s",
 					description: "can create non-namespaced resources",
 					hintAnchor:  "pre-k8s-cluster-k8s",
 					check: func(ctx context.Context) error {
@@ -569,6 +615,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "pre-k8s-service-accounts",
 					description: "can create ServiceAccounts",
 					hintAnchor:  "pre-k8s",
 					check: func(ctx context.Context) error {
@@ -576,6 +623,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "pre-k8s-services",
 					description: "can create Services",
 					hintAnchor:  "pre-k8s",
 					check: func(ctx context.Context) error {
@@ -583,6 +631,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "pre-k8s-deployments",
 					description: "can create Deployments",
 					hintAnchor:  "pre-k8s",
 					check: func(ctx context.Context) error {
@@ -590,6 +639,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "pre-k8s-cronjobs",
 					description: "can create CronJobs",
 					hintAnchor:  "pre-k8s",
 					check: func(ctx context.Context) error {
@@ -597,6 +647,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "pre-k8s-configmaps",
 					description: "can create ConfigMaps",
 					hintAnchor:  "pre-k8s",
 					check: func(ctx context.Context) error {
@@ -604,6 +655,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "pre-k8s-secrets",
 					description: "can create Secrets",
 					hintAnchor:  "pre-k8s",
 					check: func(ctx context.Context) error {
@@ -611,6 +663,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "pre-k8s-read-secrets",
 					description: "can read Secrets",
 					hintAnchor:  "pre-k8s",
 					check: func(ctx context.Context) error {
@@ -618,6 +671,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "pre-k8s-extension-apiserver-authentication",
 					description: "can read extension-apiserver-authentication configmap",
 					hintAnchor:  "pre-k8s",
 					check: func(ctx context.Context) error {
@@ -625,6 +679,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "pre-k8s-clock-skew",
 					description: "no clock skew detected",
 					hintAnchor:  "pre-k8s-clock-skew",
 					warning:     true,
@@ -632,6 +687,15 @@ func (hc *HealthChecker) allCategories() []*Category {
 						return hc.checkClockSkew(ctx)
 					},
 				},
+				{
+					id:          "pre-k8s-node-clock-skew",
+					description: "node clocks are synchronized with the API server",
+					hintAnchor:  "l5d-node-clock-skew",
+					warning:     true,
+					check: func(ctx context.Context) error {
+						return hc.checkNodeClockSkew(ctx)
+					},
+				},
 			},
 			false,
 		),
@@ -639,6 +703,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdCRDChecks,
 			[]Checker{
 				{
+					id:            "l5d-crds-exist",
 					description:   "control plane CustomResourceDefinitions exist",
 					hintAnchor:    "l5d-existence-crd",
 					fatal:         true,
@@ -654,6 +719,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdControlPlaneExistenceChecks,
 			[]Checker{
 				{
+					id:          "l5d-existence-linkerd-config",
 					description: "'linkerd-config' config map exists",
 					hintAnchor:  "l5d-existence-linkerd-config",
 					fatal:       true,
@@ -663,6 +729,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-existence-heartbeat-sa",
 					description: "heartbeat ServiceAccount exist",
 					hintAnchor:  "l5d-existence-sa",
 					fatal:       true,
@@ -674,6 +741,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:            "l5d-existence-replicasets",
 					description:   "control plane replica sets are ready",
 					hintAnchor:    "l5d-existence-replicasets",
 					retryDeadline: hc.RetryDeadline,
@@ -687,6 +755,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:                  "l5d-existence-unschedulable-pods",
 					description:         "no unschedulable pods",
 					hintAnchor:          "l5d-existence-unschedulable-pods",
 					retryDeadline:       hc.RetryDeadline,
@@ -703,6 +772,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:                  "l5d-api-control-ready",
 					description:         "control plane pods are ready",
 					hintAnchor:          "l5d-api-control-ready",
 					retryDeadline:       hc.RetryDeadline,
@@ -721,6 +791,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-cluster-networks-cidr",
 					description: "cluster networks contains all node podCIDRs",
 					hintAnchor:  "l5d-cluster-networks-cidr",
 					check: func(ctx context.Context) error {
@@ -734,6 +805,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-cluster-networks-pods",
 					description: "cluster networks contains all pods",
 					hintAnchor:  "l5d-cluster-networks-pods",
 					check: func(ctx context.Context) error {
@@ -741,6 +813,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-cluster-networks-services",
 					description: "cluster networks contains all services",
 					hintAnchor:  "l5d-cluster-networks-pods",
 					check: func(ctx context.Context) error {
@@ -754,6 +827,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdConfigChecks,
 			[]Checker{
 				{
+					id:          "l5d-existence-ns",
 					description: "control plane Namespace exists",
 					hintAnchor:  "l5d-existence-ns",
 					fatal:       true,
@@ -762,6 +836,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-existence-cr",
 					description: "control plane ClusterRoles exist",
 					hintAnchor:  "l5d-existence-cr",
 					fatal:       true,
@@ -770,6 +845,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-existence-crb",
 					description: "control plane ClusterRoleBindings exist",
 					hintAnchor:  "l5d-existence-crb",
 					fatal:       true,
@@ -778,6 +854,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-existence-sa",
 					description: "control plane ServiceAccounts exist",
 					hintAnchor:  "l5d-existence-sa",
 					fatal:       true,
@@ -786,6 +863,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-existence-crd",
 					description: "control plane CustomResourceDefinitions exist",
 					hintAnchor:  "l5d-existence-crd",
 					fatal:       true,
@@ -794,6 +872,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-existence-mwc",
 					description: "control plane MutatingWebhookConfigurations exist",
 					hintAnchor:  "l5d-existence-mwc",
 					fatal:       true,
@@ -802,6 +881,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-existence-vwc",
 					description: "control plane ValidatingWebhookConfigurations exist",
 					hintAnchor:  "l5d-existence-vwc",
 					fatal:       true,
@@ -810,6 +890,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-proxy-init-run-as-root",
 					description: "proxy-init container runs as root user if docker container runtime is used",
 					hintAnchor:  "l5d-proxy-init-run-as-root",
 					fatal:       false,
@@ -838,6 +919,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdCNIPluginChecks,
 			[]Checker{
 				{
+					id:          "cni-plugin-cm-exists",
 					description: "cni plugin ConfigMap exists",
 					hintAnchor:  "cni-plugin-cm-exists",
 					fatal:       true,
@@ -850,6 +932,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "cni-plugin-cr-exists",
 					description: "cni plugin ClusterRole exists",
 					hintAnchor:  "cni-plugin-cr-exists",
 					fatal:       true,
@@ -865,6 +948,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "cni-plugin-crb-exists",
 					description: "cni plugin ClusterRoleBinding exists",
 					hintAnchor:  "cni-plugin-crb-exists",
 					fatal:       true,
@@ -880,6 +964,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "cni-plugin-sa-exists",
 					description: "cni plugin ServiceAccount exists",
 					hintAnchor:  "cni-plugin-sa-exists",
 					fatal:       true,
@@ -895,6 +980,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "cni-plugin-ds-exists",
 					description: "cni plugin DaemonSet exists",
 					hintAnchor:  "cni-plugin-ds-exists",
 					fatal:       true,
@@ -910,6 +996,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:                  "cni-plugin-ready",
 					description:         "cni plugin pod is running on all nodes",
 					hintAnchor:          "cni-plugin-ready",
 					retryDeadline:       hc.RetryDeadline,
@@ -938,6 +1025,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdIdentity,
 			[]Checker{
 				{
+					id:          "l5d-identity-cert-config-valid",
 					description: "certificate config is valid",
 					hintAnchor:  "l5d-identity-cert-config-valid",
 					fatal:       true,
@@ -947,6 +1035,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-identity-trustAnchors-use-supported-crypto",
 					description: "trust anchors are using supported crypto algorithm",
 					hintAnchor:  "l5d-identity-trustAnchors-use-supported-crypto",
 					fatal:       true,
@@ -964,6 +1053,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-identity-trustAnchors-are-time-valid",
 					description: "trust anchors are within their validity period",
 					hintAnchor:  "l5d-identity-trustAnchors-are-time-valid",
 					fatal:       true,
@@ -982,13 +1072,14 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
-					description: "trust anchors are valid for at least 60 days",
+					id:          "l5d-identity-trustAnchors-not-expiring-soon",
+					description: fmt.Sprintf("trust anchors are valid for at least %d days", hc.CertExpiryWarningDays()),
 					hintAnchor:  "l5d-identity-trustAnchors-not-expiring-soon",
 					warning:     true,
 					check: func(ctx context.Context) error {
 						var expiringAnchors []string
 						for _, anchor := range hc.trustAnchors {
-							if err := issuercerts.CheckExpiringSoon(anchor); err != nil {
+							if err := issuercerts.CheckExpiringWithin(anchor, hc.CertExpiryWarningDays()); err != nil {
 								expiringAnchors = append(expiringAnchors, fmt.Sprintf("* %v %s %s", anchor.SerialNumber, anchor.Subject.CommonName, err))
 							}
 						}
@@ -999,6 +1090,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-identity-issuer-cert-uses-supported-crypto",
 					description: "issuer cert is using supported crypto algorithm",
 					hintAnchor:  "l5d-identity-issuer-cert-uses-supported-crypto",
 					fatal:       true,
@@ -1010,6 +1102,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-identity-issuer-cert-is-time-valid",
 					description: "issuer cert is within its validity period",
 					hintAnchor:  "l5d-identity-issuer-cert-is-time-valid",
 					fatal:       true,
@@ -1021,23 +1114,43 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
-					description: "issuer cert is valid for at least 60 days",
+					id:          "l5d-identity-issuer-cert-not-expiring-soon",
+					description: fmt.Sprintf("issuer cert is valid for at least %d days", hc.CertExpiryWarningDays()),
 					warning:     true,
 					hintAnchor:  "l5d-identity-issuer-cert-not-expiring-soon",
 					check: func(context.Context) error {
-						if err := issuercerts.CheckExpiringSoon(hc.issuerCert.Certificate); err != nil {
+						if err := issuercerts.CheckExpiringWithin(hc.issuerCert.Certificate, hc.CertExpiryWarningDays()); err != nil {
 							return fmt.Errorf("issuer certificate %w", err)
 						}
 						return nil
 					},
 				},
 				{
+					id:          "l5d-identity-issuer-cert-issued-by-trust-anchor",
 					description: "issuer cert is issued by the trust anchor",
 					hintAnchor:  "l5d-identity-issuer-cert-issued-by-trust-anchor",
 					check: func(ctx context.Context) error {
 						return hc.issuerCert.Verify(tls.CertificatesToPool(hc.trustAnchors), "", time.Time{})
 					},
 				},
+				{
+					id:          "l5d-identity-replicas-same-issuer",
+					description: "identity replicas use the same issuer",
+					hintAnchor:  "l5d-identity-replicas-same-issuer",
+					warning:     true,
+					check: func(ctx context.Context) error {
+						return hc.checkIdentityReplicasIssuer(ctx)
+					},
+				},
+				{
+					id:          "l5d-node-clock-skew",
+					description: "node clocks are synchronized with the API server",
+					hintAnchor:  "l5d-node-clock-skew",
+					warning:     true,
+					check: func(ctx context.Context) error {
+						return hc.checkNodeClockSkew(ctx)
+					},
+				},
 			},
 			false,
 		),
@@ -1045,6 +1158,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdWebhooksAndAPISvcTLS,
 			[]Checker{
 				{
+					id:          "l5d-proxy-injector-webhook-cert-valid",
 					description: "proxy-injector webhook has valid cert",
 					hintAnchor:  "l5d-proxy-injector-webhook-cert-valid",
 					fatal:       true,
@@ -1066,7 +1180,8 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
-					description: "proxy-injector cert is valid for at least 60 days",
+					id:          "l5d-proxy-injector-webhook-cert-not-expiring-soon",
+					description: fmt.Sprintf("proxy-injector cert is valid for at least %d days", hc.CertExpiryWarningDays()),
 					warning:     true,
 					hintAnchor:  "l5d-proxy-injector-webhook-cert-not-expiring-soon",
 					check: func(ctx context.Context) error {
@@ -1080,8 +1195,12 @@ func (hc *HealthChecker) allCategories() []*Category {
 						return hc.CheckCertAndAnchorsExpiringSoon(cert)
 
 					},
+					remediation: func(ctx context.Context) ([]*Fix, error) {
+						return hc.renewWebhookCertFixes(ctx, proxyInjectorWebhook)
+					},
 				},
 				{
+					id:          "l5d-sp-validator-webhook-cert-valid",
 					description: "sp-validator webhook has valid cert",
 					hintAnchor:  "l5d-sp-validator-webhook-cert-valid",
 					fatal:       true,
@@ -1102,7 +1221,8 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
-					description: "sp-validator cert is valid for at least 60 days",
+					id:          "l5d-sp-validator-webhook-cert-not-expiring-soon",
+					description: fmt.Sprintf("sp-validator cert is valid for at least %d days", hc.CertExpiryWarningDays()),
 					warning:     true,
 					hintAnchor:  "l5d-sp-validator-webhook-cert-not-expiring-soon",
 					check: func(ctx context.Context) error {
@@ -1116,8 +1236,12 @@ func (hc *HealthChecker) allCategories() []*Category {
 						return hc.CheckCertAndAnchorsExpiringSoon(cert)
 
 					},
+					remediation: func(ctx context.Context) ([]*Fix, error) {
+						return hc.renewWebhookCertFixes(ctx, spValidatorWebhook)
+					},
 				},
 				{
+					id:          "l5d-policy-validator-webhook-cert-valid",
 					description: "policy-validator webhook has valid cert",
 					hintAnchor:  "l5d-policy-validator-webhook-cert-valid",
 					fatal:       true,
@@ -1141,7 +1265,8 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
-					description: "policy-validator cert is valid for at least 60 days",
+					id:          "l5d-policy-validator-webhook-cert-not-expiring-soon",
+					description: fmt.Sprintf("policy-validator cert is valid for at least %d days", hc.CertExpiryWarningDays()),
 					warning:     true,
 					hintAnchor:  "l5d-policy-validator-webhook-cert-not-expiring-soon",
 					check: func(ctx context.Context) error {
@@ -1155,6 +1280,9 @@ func (hc *HealthChecker) allCategories() []*Category {
 						return hc.CheckCertAndAnchorsExpiringSoon(cert)
 
 					},
+					remediation: func(ctx context.Context) ([]*Fix, error) {
+						return hc.renewWebhookCertFixes(ctx, policyValidatorWebhook)
+					},
 				},
 			},
 			false,
@@ -1163,6 +1291,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdIdentityDataPlane,
 			[]Checker{
 				{
+					id:          "l5d-identity-data-plane-proxies-certs-match-ca",
 					description: "data plane proxies certificate match CA",
 					hintAnchor:  "l5d-identity-data-plane-proxies-certs-match-ca",
 					warning:     true,
@@ -1177,6 +1306,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdVersionChecks,
 			[]Checker{
 				{
+					id:          "l5d-version-latest",
 					description: "can determine the latest version",
 					hintAnchor:  "l5d-version-latest",
 					warning:     true,
@@ -1194,6 +1324,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-version-cli",
 					description: "cli is up-to-date",
 					hintAnchor:  "l5d-version-cli",
 					warning:     true,
@@ -1208,6 +1339,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdControlPlaneVersionChecks,
 			[]Checker{
 				{
+					id:            "l5d-version-control-retrieved",
 					description:   "can retrieve the control plane version",
 					hintAnchor:    "l5d-version-control",
 					retryDeadline: hc.RetryDeadline,
@@ -1218,6 +1350,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-version-control",
 					description: "control plane is up-to-date",
 					hintAnchor:  "l5d-version-control",
 					warning:     true,
@@ -1226,6 +1359,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-version-control-cli",
 					description: "control plane and cli versions match",
 					hintAnchor:  "l5d-version-control",
 					warning:     true,
@@ -1243,6 +1377,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdControlPlaneProxyChecks,
 			[]Checker{
 				{
+					id:                  "l5d-cp-proxy-healthy",
 					description:         "control plane proxies are healthy",
 					hintAnchor:          "l5d-cp-proxy-healthy",
 					retryDeadline:       hc.RetryDeadline,
@@ -1253,6 +1388,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-cp-proxy-version",
 					description: "control plane proxies are up-to-date",
 					hintAnchor:  "l5d-cp-proxy-version",
 					warning:     true,
@@ -1266,6 +1402,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-cp-proxy-cli-version",
 					description: "control plane proxies and cli versions match",
 					hintAnchor:  "l5d-cp-proxy-cli-version",
 					warning:     true,
@@ -1285,6 +1422,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdDataPlaneChecks,
 			[]Checker{
 				{
+					id:          "l5d-data-plane-exists",
 					description: "data plane namespace exists",
 					hintAnchor:  "l5d-data-plane-exists",
 					fatal:       true,
@@ -1297,6 +1435,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:            "l5d-data-plane-ready",
 					description:   "data plane proxies are ready",
 					hintAnchor:    "l5d-data-plane-ready",
 					retryDeadline: hc.RetryDeadline,
@@ -1310,6 +1449,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-data-plane-version",
 					description: "data plane is up-to-date",
 					hintAnchor:  "l5d-data-plane-version",
 					warning:     true,
@@ -1321,8 +1461,12 @@ func (hc *HealthChecker) allCategories() []*Category {
 
 						return hc.CheckProxyVersionsUpToDate(pods)
 					},
+					remediation: func(ctx context.Context) ([]*Fix, error) {
+						return hc.restartOutdatedProxiesFixes(ctx)
+					},
 				},
 				{
+					id:          "l5d-data-plane-cli-version",
 					description: "data plane and cli versions match",
 					hintAnchor:  "l5d-data-plane-cli-version",
 					warning:     true,
@@ -1336,6 +1480,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-data-plane-pod-labels",
 					description: "data plane pod labels are configured correctly",
 					hintAnchor:  "l5d-data-plane-pod-labels",
 					warning:     true,
@@ -1349,6 +1494,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-data-plane-services-labels",
 					description: "data plane service labels are configured correctly",
 					hintAnchor:  "l5d-data-plane-services-labels",
 					warning:     true,
@@ -1362,6 +1508,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "l5d-data-plane-services-annotations",
 					description: "data plane service annotations are configured correctly",
 					hintAnchor:  "l5d-data-plane-services-annotations",
 					warning:     true,
@@ -1375,12 +1522,16 @@ func (hc *HealthChecker) allCategories() []*Category {
 					},
 				},
 				{
+					id:          "linkerd-opaque-ports-definition",
 					description: "opaque ports are properly annotated",
 					hintAnchor:  "linkerd-opaque-ports-definition",
 					warning:     true,
 					check: func(ctx context.Context) error {
 						return hc.checkMisconfiguredOpaquePortAnnotations(ctx)
 					},
+					remediation: func(ctx context.Context) ([]*Fix, error) {
+						return hc.opaquePortsFixes(ctx)
+					},
 				},
 			},
 			false,
@@ -1389,6 +1540,7 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdHAChecks,
 			[]Checker{
 				{
+					id:            "l5d-control-plane-replicas",
 					description:   "multiple replicas of control plane pods",
 					hintAnchor:    "l5d-control-plane-replicas",
 					retryDeadline: hc.RetryDeadline,
@@ -1407,16 +1559,25 @@ func (hc *HealthChecker) allCategories() []*Category {
 			LinkerdExtensionChecks,
 			[]Checker{
 				{
+					id:          "l5d-extension-namespaces",
 					description: "namespace configuration for extensions",
 					warning:     true,
 					hintAnchor:  "l5d-extension-namespaces",
 					check: func(ctx context.Context) error {
 						return hc.checkExtensionNsLabels(ctx)
 					},
+					remediation: func(ctx context.Context) ([]*Fix, error) {
+						return hc.extensionNsLabelsFixes(ctx)
+					},
 				},
 			},
 			false,
 		),
+		NewCategory(
+			LinkerdConnectivityChecks,
+			hc.connectivityCheckers(),
+			false,
+		),
 	}
 }
 
@@ -1431,15 +1592,8 @@ func (hc *HealthChecker) CheckProxyVersionsUpToDate(pods []corev1.Pod) error {
 func CheckProxyVersionsUpToDate(pods []corev1.Pod, versions version.Channels) error {
 	outdatedPods := []string{}
 	for _, pod := range pods {
-		status := k8s.GetPodStatus(pod)
-		if status == string(corev1.PodRunning) {
-			proxyVersion := k8s.GetProxyVersion(pod)
-			if proxyVersion == "" {
-				continue
-			}
-			if err := versions.Match(proxyVersion); err != nil {
-				outdatedPods = append(outdatedPods, fmt.Sprintf("\t* %s (%s)", pod.Name, proxyVersion))
-			}
+		if isProxyOutdated(pod, versions) {
+			outdatedPods = append(outdatedPods, fmt.Sprintf("\t* %s (%s)", pod.Name, k8s.GetProxyVersion(pod)))
 		}
 	}
 	if versions.Empty() {
@@ -1452,6 +1606,16 @@ func CheckProxyVersionsUpToDate(pods []corev1.Pod, versions version.Channels) er
 	return nil
 }
 
+// isProxyOutdated returns true if pod is running a proxy whose version
+// doesn't match versions
+func isProxyOutdated(pod corev1.Pod, versions version.Channels) bool {
+	if k8s.GetPodStatus(pod) != string(corev1.PodRunning) {
+		return false
+	}
+	proxyVersion := k8s.GetProxyVersion(pod)
+	return proxyVersion != "" && versions.Match(proxyVersion) != nil
+}
+
 // CheckIfProxyVersionsMatchWithCLI checks if the latest proxy version
 // matches that of the CLI
 func CheckIfProxyVersionsMatchWithCLI(pods []corev1.Pod) error {
@@ -1516,7 +1680,7 @@ func (hc *HealthChecker) CheckCertAndAnchorsExpiringSoon(cert *tls.Cred) error {
 	var expiringAnchors []string
 	for _, anchor := range cert.TrustChain {
 		anchor := anchor
-		if err := issuercerts.CheckExpiringSoon(anchor); err != nil {
+		if err := issuercerts.CheckExpiringWithin(anchor, hc.CertExpiryWarningDays()); err != nil {
 			expiringAnchors = append(expiringAnchors, fmt.Sprintf("* %v %s %s", anchor.SerialNumber, anchor.Subject.CommonName, err))
 		}
 	}
@@ -1525,7 +1689,7 @@ func (hc *HealthChecker) CheckCertAndAnchorsExpiringSoon(cert *tls.Cred) error {
 	}
 
 	// check cert not expiring soon
-	if err := issuercerts.CheckExpiringSoon(cert.Certificate); err != nil {
+	if err := issuercerts.CheckExpiringWithin(cert.Certificate, hc.CertExpiryWarningDays()); err != nil {
 		return fmt.Errorf("certificate %w", err)
 	}
 	return nil
@@ -1584,9 +1748,15 @@ func (hc *HealthChecker) RunChecks(observer CheckObserver) (bool, bool) {
 	success := true
 	warning := false
 	for _, c := range hc.categories {
-		if c.enabled {
+		if hc.CheckConfig.categoryEnabled(c) {
 			for _, checker := range c.checkers {
 				checker := checker // pin
+				if !hc.CheckConfig.checkEnabled(&checker) {
+					continue
+				}
+				if hc.CheckConfig.warningAsError(c, &checker) {
+					checker.warning = false
+				}
 				if checker.check != nil {
 					if !hc.runCheck(c, &checker, observer) {
 						if !checker.warning {
@@ -1646,6 +1816,7 @@ func (hc *HealthChecker) runCheck(category *Category, c *Checker, observer Check
 
 		checkResult := &CheckResult{
 			Category:    category.ID,
+			ID:          c.id,
 			Description: c.description,
 			Warning:     c.warning,
 			HintURL:     fmt.Sprintf("%s%s", category.hintBaseURL, c.hintAnchor),
@@ -1669,6 +1840,9 @@ func (hc *HealthChecker) runCheck(category *Category, c *Checker, observer Check
 			continue
 		}
 
+		if checkResult.Err != nil && c.remediation != nil && hc.Remediate {
+			checkResult.Fixes = hc.findFixes(c)
+		}
 		observer(checkResult)
 		return checkResult.Err == nil
 	}
@@ -1734,6 +1908,32 @@ func (hc *HealthChecker) checkCertificatesConfig(ctx context.Context) (*tls.Cred
 	return issuerCreds, anchors, nil
 }
 
+// checkIdentityReplicasIssuer reads the issuer status Leases published by the
+// identity controller replicas and fails if they don't all sign with the same
+// issuer. Control planes that don't publish issuer statuses pass.
+func (hc *HealthChecker) checkIdentityReplicasIssuer(ctx context.Context) error {
+	selector := labels.Set{k8s.ControllerComponentLabel: "identity"}.AsSelector().String()
+	leases, err := hc.kubeAPI.CoordinationV1().Leases(hc.ControlPlaneNamespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return err
+	}
+
+	byFingerprint := map[string][]string{}
+	for _, status := range identity.LiveIssuerStatuses(leases.Items, time.Now()) {
+		byFingerprint[status.Fingerprint] = append(byFingerprint[status.Fingerprint], status.Replica)
+	}
+	if len(byFingerprint) <= 1 {
+		return nil
+	}
+
+	var groups []string
+	for fingerprint, replicas := range byFingerprint {
+		groups = append(groups, fmt.Sprintf("%s (%s)", strings.Join(replicas, ", "), fingerprint))
+	}
+	sort.Strings(groups)
+	return fmt.Errorf("identity replicas sign with different issuers:\n\t%s", strings.Join(groups, "\n\t"))
+}
+
 // FetchCurrentConfiguration retrieves the current Linkerd configuration
 func FetchCurrentConfiguration(ctx context.Context, k kubernetes.Interface, controlPlaneNamespace string) (*corev1.ConfigMap, *l5dcharts.Values, error) {
 	// Get the linkerd-config values if present.
@@ -2365,6 +2565,26 @@ func checkResources(resourceName string, objects []runtime.Object, expectedNames
 // Check if there's a pod with the "opaque ports" annotation defined but a
 // service selecting the aforementioned pod doesn't define it
 func (hc *HealthChecker) checkMisconfiguredOpaquePortAnnotations(ctx context.Context) error {
+	var errStrings []string
+	err := hc.forEachServicePod(ctx, func(service *corev1.Service, pod *corev1.Pod) {
+		if err := misconfiguredOpaqueAnnotation(service, pod); err != nil {
+			errStrings = append(errStrings, fmt.Sprintf("\t* %s", err.Error()))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(errStrings) >= 1 {
+		return errors.New(strings.Join(errStrings, "\n    "))
+	}
+
+	return nil
+}
+
+// forEachServicePod calls fn with every pod targeted by every non-headless
+// service of the data plane namespace.
+func (hc *HealthChecker) forEachServicePod(ctx context.Context, fn func(*corev1.Service, *corev1.Pod)) error {
 	// Initialize and sync the kubernetes API
 	// This is used instead of `hc.kubeAPI` to limit multiple k8s API requests
 	// and use the caching logic in the shared informers
@@ -2377,7 +2597,6 @@ func (hc *HealthChecker) checkMisconfiguredOpaquePortAnnotations(ctx context.Con
 		return err
 	}
 
-	var errStrings []string
 	for _, service := range services {
 		if service.Spec.ClusterIP == "None" {
 			// skip headless services; they're handled differently
@@ -2395,17 +2614,9 @@ func (hc *HealthChecker) checkMisconfiguredOpaquePortAnnotations(ctx context.Con
 		}
 
 		for pod := range pods {
-			err := misconfiguredOpaqueAnnotation(service, pod)
-			if err != nil {
-				errStrings = append(errStrings, fmt.Sprintf("\t* %s", err.Error()))
-			}
+			fn(service, pod)
 		}
 	}
-
-	if len(errStrings) >= 1 {
-		return errors.New(strings.Join(errStrings, "\n    "))
-	}
-
 	return nil
 }
 
@@ -2670,7 +2881,7 @@ func (hc *HealthChecker) checkClockSkew(ctx context.Context) error {
 			// we want to check only KubeletReady condition and only execute if the node is ready
 			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
 				since := time.Since(condition.LastHeartbeatTime.Time)
-				if (since > AllowedClockSkew) || (since < -AllowedClockSkew) {
+				if allowed := hc.allowedClockSkew(); (since > allowed) || (since < -allowed) {
 					clockSkewNodes = append(clockSkewNodes, node.Name)
 				}
 			}
@@ -2713,6 +2924,16 @@ func (hc *HealthChecker) checkExtensionNsLabels(ctx context.Context) error {
 		errs = append(errs, fmt.Sprintf("\t* label \"%s=%s\" is present on more than one namespace:\n%s", k8s.LinkerdExtensionLabel, ext, strings.Join(namespaces, "\n")))
 	}
 
+	// Extensions installed in a namespace lacking the label aren't found by
+	// `linkerd check`
+	unlabeled, err := hc.unlabeledExtensionNamespaces(ctx)
+	if err != nil {
+		return fmt.Errorf("unexpected error when retrieving extension pods: %w", err)
+	}
+	for _, ns := range sortedNamespaces(unlabeled) {
+		errs = append(errs, fmt.Sprintf("\t* namespace %s runs the %s extension but lacks the \"%s\" label", ns, strings.Join(unlabeled[ns], ", "), k8s.LinkerdExtensionLabel))
+	}
+
 	if len(errs) > 0 {
 		return errors.New(strings.Join(
 			append([]string{"some extensions have invalid configuration"}, errs...), "\n"))
@@ -2721,6 +2942,38 @@ func (hc *HealthChecker) checkExtensionNsLabels(ctx context.Context) error {
 	return nil
 }
 
+// unlabeledExtensionNamespaces returns the namespaces running pods of an
+// extension but lacking the extension label, along with the names of the
+// extensions they run.
+func (hc *HealthChecker) unlabeledExtensionNamespaces(ctx context.Context) (map[string][]string, error) {
+	pods, err := hc.kubeAPI.CoreV1().Pods("").List(ctx, metav1.ListOptions{LabelSelector: k8s.LinkerdExtensionLabel})
+	if err != nil {
+		return nil, err
+	}
+
+	unlabeled := map[string][]string{}
+	checked := map[string]bool{}
+	for _, pod := range pods.Items {
+		if _, ok := checked[pod.Namespace]; !ok {
+			ns, err := hc.kubeAPI.CoreV1().Namespaces().Get(ctx, pod.Namespace, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			_, labeled := ns.Labels[k8s.LinkerdExtensionLabel]
+			checked[pod.Namespace] = labeled
+		}
+		ext := pod.Labels[k8s.LinkerdExtensionLabel]
+		if checked[pod.Namespace] || util.ContainsString(ext, unlabeled[pod.Namespace]) {
+			continue
+		}
+		unlabeled[pod.Namespace] = append(unlabeled[pod.Namespace], ext)
+	}
+	for _, exts := range unlabeled {
+		sort.Strings(exts)
+	}
+	return unlabeled, nil
+}
+
 // CheckRoles checks that the expected roles exist.
 func CheckRoles(ctx context.Context, kubeAPI *k8s.KubernetesAPI, shouldExist bool, namespace string, expectedNames []string, labelSelector string) error {
 	options := metav1.ListOptions{
@@ -0,0 +1,135 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	l5dcharts "github.com/linkerd/linkerd2/pkg/charts/linkerd2"
+	"github.com/linkerd/linkerd2/pkg/tls"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// nodeLeaseNamespace holds the leases renewed by the kubelets, with
+	// their own clocks
+	nodeLeaseNamespace = "kube-node-lease"
+
+	// defaultNodeLeaseDurationSeconds is the default duration of the node
+	// leases; kubelets renew them every quarter of their duration
+	defaultNodeLeaseDurationSeconds = 40
+)
+
+// checkNodeClockSkew measures the skew between the clock of each ready node
+// and the clock of the API server, through the renew time of the node leases,
+// and reports the nodes whose skew exceeds the clock skew allowance of the
+// identity certificates.
+func (hc *HealthChecker) checkNodeClockSkew(ctx context.Context) error {
+	nodes, err := hc.kubeAPI.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	leases, err := hc.kubeAPI.CoordinationV1().Leases(nodeLeaseNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	serverTime, err := apiServerTime(ctx, hc.kubeAPI.Config)
+	if err != nil {
+		return fmt.Errorf("failed to get the time of the API server: %w", err)
+	}
+
+	allowance := hc.identityClockSkewAllowance()
+	skewed := nodeClockSkews(nodes.Items, leases.Items, serverTime, allowance)
+	if len(skewed) > 0 {
+		return fmt.Errorf("the clock of some nodes is skewed by more than the %s allowance of the identity certificates:\n\t%s", allowance, strings.Join(skewed, "\n\t"))
+	}
+	return nil
+}
+
+// nodeClockSkews returns a description of the ready nodes whose clock is
+// skewed by more than allowance compared to serverTime. A lease is renewed
+// periodically, so the clock of its node is ahead if its renew time is after
+// serverTime, and behind if it's older than the renew interval.
+func nodeClockSkews(nodes []corev1.Node, leases []coordinationv1.Lease, serverTime time.Time, allowance time.Duration) []string {
+	ready := map[string]struct{}{}
+	for _, node := range nodes {
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+				ready[node.Name] = struct{}{}
+			}
+		}
+	}
+
+	var skewed []string
+	for _, lease := range leases {
+		if _, ok := ready[lease.Name]; !ok || lease.Spec.RenewTime == nil {
+			continue
+		}
+		durationSeconds := int32(defaultNodeLeaseDurationSeconds)
+		if lease.Spec.LeaseDurationSeconds != nil {
+			durationSeconds = *lease.Spec.LeaseDurationSeconds
+		}
+		renewInterval := time.Duration(durationSeconds) * time.Second / 4
+
+		age := serverTime.Sub(lease.Spec.RenewTime.Time)
+		if ahead := -age; ahead > allowance {
+			skewed = append(skewed, fmt.Sprintf("* %s is ahead by %s", lease.Name, ahead.Round(time.Second)))
+		} else if behind := age - renewInterval; behind > allowance {
+			skewed = append(skewed, fmt.Sprintf("* %s is behind by at least %s", lease.Name, behind.Round(time.Second)))
+		}
+	}
+	sort.Strings(skewed)
+	return skewed
+}
+
+// apiServerTime returns the time of the API server, from the Date header of
+// its responses. The header has a one second precision, so the middle of that
+// second is returned.
+func apiServerTime(ctx context.Context, config *rest.Config) (time.Time, error) {
+	client, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return time.Time{}, err
+	}
+	url, _, err := rest.DefaultServerUrlFor(config)
+	if err != nil {
+		return time.Time{}, err
+	}
+	url.Path = "/version"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer rsp.Body.Close()
+
+	date, err := http.ParseTime(rsp.Header.Get("Date"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid Date header: %w", err)
+	}
+	return date.Add(500 * time.Millisecond), nil
+}
+
+// identityClockSkewAllowance returns the clock skew allowance of the identity
+// certificates, as configured in the values of the control plane, or in the
+// values to install it with.
+func (hc *HealthChecker) identityClockSkewAllowance() time.Duration {
+	for _, values := range []*l5dcharts.Values{hc.linkerdConfig, hc.ChartValues} {
+		if values == nil || values.Identity == nil || values.Identity.Issuer == nil {
+			continue
+		}
+		if allowance, err := time.ParseDuration(values.Identity.Issuer.ClockSkewAllowance); err == nil {
+			return allowance
+		}
+	}
+	return tls.DefaultClockSkewAllowance
+}
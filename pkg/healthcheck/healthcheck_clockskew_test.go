@@ -0,0 +1,110 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	l5dcharts "github.com/linkerd/linkerd2/pkg/charts/linkerd2"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeClockSkews(t *testing.T) {
+	serverTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	node := func(name string, ready corev1.ConditionStatus) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: ready},
+			}},
+		}
+	}
+	lease := func(name string, renewed time.Duration) coordinationv1.Lease {
+		duration := int32(40)
+		return coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: coordinationv1.LeaseSpec{
+				LeaseDurationSeconds: &duration,
+				RenewTime:            &metav1.MicroTime{Time: serverTime.Add(renewed)},
+			},
+		}
+	}
+
+	nodes := []corev1.Node{
+		node("synced", corev1.ConditionTrue),
+		node("renewing", corev1.ConditionTrue),
+		node("ahead", corev1.ConditionTrue),
+		node("behind", corev1.ConditionTrue),
+		node("not-ready", corev1.ConditionFalse),
+	}
+	leases := []coordinationv1.Lease{
+		lease("synced", -time.Second),
+		// Renewed at the end of the 10s renew interval, within the allowance
+		lease("renewing", -15*time.Second),
+		lease("ahead", time.Minute),
+		lease("behind", -time.Minute),
+		lease("not-ready", -time.Hour),
+	}
+
+	expected := []string{
+		"* ahead is ahead by 1m0s",
+		"* behind is behind by at least 50s",
+	}
+	skewed := nodeClockSkews(nodes, leases, serverTime, 10*time.Second)
+	if diff := deep.Equal(skewed, expected); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+}
+
+func TestCheckNodeClockSkew(t *testing.T) {
+	serverTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", serverTime.UTC().Format(http.TimeFormat))
+	}))
+	defer apiServer.Close()
+
+	renewTime := serverTime.Add(30 * time.Second).UTC().Format(metav1.RFC3339Micro)
+	hc := NewHealthChecker([]CategoryID{}, &Options{})
+	var err error
+	hc.kubeAPI, err = k8s.NewFakeAPI(`
+apiVersion: v1
+kind: Node
+metadata:
+  name: node-1
+status:
+  conditions:
+  - type: Ready
+    status: "True"
+`, `
+apiVersion: coordination.k8s.io/v1
+kind: Lease
+metadata:
+  name: node-1
+  namespace: kube-node-lease
+spec:
+  leaseDurationSeconds: 40
+  renewTime: "`+renewTime+`"
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	hc.kubeAPI.Config.Host = apiServer.URL
+	hc.linkerdConfig = &l5dcharts.Values{Identity: &l5dcharts.Identity{Issuer: &l5dcharts.Issuer{ClockSkewAllowance: "20s"}}}
+
+	err = hc.checkNodeClockSkew(context.Background())
+	expected := "the clock of some nodes is skewed by more than the 20s allowance of the identity certificates:\n\t* node-1 is ahead by 30s"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected error %q, got %v", expected, err)
+	}
+
+	hc.linkerdConfig.Identity.Issuer.ClockSkewAllowance = "1m"
+	if err := hc.checkNodeClockSkew(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,153 @@
+package healthcheck
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/issuercerts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// CheckConfigEnvVar is the environment variable holding the path of the
+	// check-config file, when it isn't set through a flag. It's how the
+	// config is passed on to the extension checks run by `linkerd check`.
+	CheckConfigEnvVar = "LINKERD_CHECK_CONFIG"
+
+	// allChecks matches every check and category in warningsAsErrors
+	allChecks = "*"
+)
+
+type (
+	// CheckConfig configures the checks of a HealthChecker, e.g.:
+	//
+	//	categories:
+	//	  linkerd-ha-checks: false
+	//	checks:
+	//	  l5d-identity-replicas-same-issuer: false
+	//	warningsAsErrors:
+	//	- linkerd-version
+	//	thresholds:
+	//	  certExpiryWarningDays: 120
+	//	  allowedClockSkew: 1m
+	//
+	// Checks are identified by their ID, found in the JSON output of
+	// `linkerd check`.
+	CheckConfig struct {
+		// Categories enables or disables categories by ID, regardless of
+		// whether they're run by default
+		Categories map[CategoryID]bool `json:"categories,omitempty"`
+		// Checks disables checks by ID when set to false
+		Checks map[string]bool `json:"checks,omitempty"`
+		// WarningsAsErrors lists the checks, or categories, whose warnings
+		// are failures, or "*" for all of them
+		WarningsAsErrors []string `json:"warningsAsErrors,omitempty"`
+		// Thresholds overrides the time-based thresholds of the checks
+		Thresholds CheckThresholds `json:"thresholds,omitempty"`
+	}
+
+	// CheckThresholds are the time-based thresholds of the checks.
+	CheckThresholds struct {
+		// CertExpiryWarningDays is the number of days before the expiration
+		// of a certificate after which its check warns (default: 60)
+		CertExpiryWarningDays int `json:"certExpiryWarningDays,omitempty"`
+		// AllowedClockSkew is the maximum skew between the clocks of the
+		// nodes and the clock of the CLI (default: 5m + the TLS allowance)
+		AllowedClockSkew *metav1.Duration `json:"allowedClockSkew,omitempty"`
+	}
+)
+
+// LoadCheckConfigOrEnv loads the check-config file at path, or at the path
+// set in the LINKERD_CHECK_CONFIG environment variable if path is empty. It
+// returns nil if neither is set.
+func LoadCheckConfigOrEnv(path string) (*CheckConfig, error) {
+	if path == "" {
+		path = os.Getenv(CheckConfigEnvVar)
+	}
+	if path == "" {
+		return nil, nil
+	}
+	return LoadCheckConfig(path)
+}
+
+// LoadCheckConfig reads and validates the check-config file at path.
+func LoadCheckConfig(path string) (*CheckConfig, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var config CheckConfig
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid check config %s: %w", path, err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid check config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+func (c *CheckConfig) validate() error {
+	if c.Thresholds.CertExpiryWarningDays < 0 {
+		return errors.New("thresholds.certExpiryWarningDays can't be negative")
+	}
+	if c.Thresholds.AllowedClockSkew != nil && c.Thresholds.AllowedClockSkew.Duration < 0 {
+		return errors.New("thresholds.allowedClockSkew can't be negative")
+	}
+	return nil
+}
+
+// categoryEnabled returns whether category is to be run.
+func (c *CheckConfig) categoryEnabled(category *Category) bool {
+	if c == nil {
+		return category.enabled
+	}
+	if enabled, ok := c.Categories[category.ID]; ok {
+		return enabled
+	}
+	return category.enabled
+}
+
+// checkEnabled returns whether the checker is to be run.
+func (c *CheckConfig) checkEnabled(checker *Checker) bool {
+	if c == nil || checker.id == "" {
+		return true
+	}
+	if enabled, ok := c.Checks[checker.id]; ok {
+		return enabled
+	}
+	return true
+}
+
+// warningAsError returns whether the warnings of the checker of category are
+// failures.
+func (c *CheckConfig) warningAsError(category *Category, checker *Checker) bool {
+	if c == nil {
+		return false
+	}
+	for _, id := range c.WarningsAsErrors {
+		if id == allChecks || id == string(category.ID) || (checker.id != "" && id == checker.id) {
+			return true
+		}
+	}
+	return false
+}
+
+// CertExpiryWarningDays returns the number of days before the expiration of
+// a certificate after which its check warns.
+func (hc *HealthChecker) CertExpiryWarningDays() int {
+	if hc.CheckConfig != nil && hc.CheckConfig.Thresholds.CertExpiryWarningDays > 0 {
+		return hc.CheckConfig.Thresholds.CertExpiryWarningDays
+	}
+	return issuercerts.ExpirationWarningThresholdInDays
+}
+
+func (hc *HealthChecker) allowedClockSkew() time.Duration {
+	if hc.CheckConfig != nil && hc.CheckConfig.Thresholds.AllowedClockSkew != nil {
+		return hc.CheckConfig.Thresholds.AllowedClockSkew.Duration
+	}
+	return AllowedClockSkew
+}
@@ -0,0 +1,197 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLoadCheckConfig(t *testing.T) {
+	testCases := []struct {
+		description string
+		config      string
+		expected    *CheckConfig
+		err         string
+	}{
+		{
+			description: "valid config",
+			config: `
+categories:
+  linkerd-ha-checks: false
+checks:
+  l5d-identity-replicas-same-issuer: false
+warningsAsErrors:
+- "*"
+thresholds:
+  certExpiryWarningDays: 120
+  allowedClockSkew: 1m`,
+			expected: &CheckConfig{
+				Categories:       map[CategoryID]bool{LinkerdHAChecks: false},
+				Checks:           map[string]bool{"l5d-identity-replicas-same-issuer": false},
+				WarningsAsErrors: []string{"*"},
+				Thresholds: CheckThresholds{
+					CertExpiryWarningDays: 120,
+					AllowedClockSkew:      &metav1.Duration{Duration: time.Minute},
+				},
+			},
+		},
+		{
+			description: "unknown field",
+			config:      "threshold:\n  certExpiryWarningDays: 120",
+			err:         `error unmarshaling JSON: while decoding JSON: json: unknown field "threshold"`,
+		},
+		{
+			description: "negative threshold",
+			config:      "thresholds:\n  certExpiryWarningDays: -1",
+			err:         "thresholds.certExpiryWarningDays can't be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.description, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "check-config.yaml")
+			if err := os.WriteFile(path, []byte(tc.config), 0600); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			config, err := LoadCheckConfig(path)
+			if tc.err != "" {
+				expected := "invalid check config " + path + ": " + tc.err
+				if err == nil || err.Error() != expected {
+					t.Fatalf("Expected error %q, got %v", expected, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if diff := deep.Equal(config, tc.expected); diff != nil {
+				t.Errorf("%+v", diff)
+			}
+		})
+	}
+}
+
+func TestHealthCheckerWithCheckConfig(t *testing.T) {
+	// Checks are selected by their ID, regardless of their hint anchor
+	checker := func(id string, warning bool) Checker {
+		return Checker{
+			id:          id,
+			description: id,
+			hintAnchor:  "shared-anchor",
+			warning:     warning,
+			check: func(context.Context) error {
+				return errors.New("failed")
+			},
+		}
+	}
+	categories := func() []*Category {
+		return []*Category{
+			NewCategory("cat1", []Checker{checker("check1", true), checker("check2", true)}, true),
+			NewCategory("cat2", []Checker{checker("check3", true)}, true),
+			NewCategory("cat3", []Checker{checker("check4", false)}, false),
+		}
+	}
+
+	testCases := []struct {
+		description string
+		config      *CheckConfig
+		expected    []string
+		success     bool
+	}{
+		{
+			description: "no config",
+			expected:    []string{"cat1 check1 warning", "cat1 check2 warning", "cat2 check3 warning"},
+			success:     true,
+		},
+		{
+			description: "disabled check and category",
+			config: &CheckConfig{
+				Categories: map[CategoryID]bool{"cat2": false},
+				Checks:     map[string]bool{"check1": false},
+			},
+			expected: []string{"cat1 check2 warning"},
+			success:  true,
+		},
+		{
+			description: "enabled category",
+			config: &CheckConfig{
+				Categories: map[CategoryID]bool{"cat3": true},
+			},
+			expected: []string{"cat1 check1 warning", "cat1 check2 warning", "cat2 check3 warning", "cat3 check4 error"},
+		},
+		{
+			description: "warnings as errors",
+			config: &CheckConfig{
+				WarningsAsErrors: []string{"check1", "cat2"},
+			},
+			expected: []string{"cat1 check1 error", "cat1 check2 warning", "cat2 check3 error"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.description, func(t *testing.T) {
+			hc := NewHealthChecker([]CategoryID{}, &Options{CheckConfig: tc.config})
+			hc.categories = categories()
+
+			var results []string
+			success, _ := hc.RunChecks(func(result *CheckResult) {
+				status := "error"
+				if result.Warning {
+					status = "warning"
+				}
+				results = append(results, string(result.Category)+" "+result.Description+" "+status)
+			})
+			if diff := deep.Equal(results, tc.expected); diff != nil {
+				t.Errorf("%+v", diff)
+			}
+			if success != tc.success {
+				t.Errorf("Expected success to be %t", tc.success)
+			}
+		})
+	}
+}
+
+func TestCertExpiryWarningDays(t *testing.T) {
+	hc := NewHealthChecker([]CategoryID{LinkerdIdentity}, &Options{
+		CheckConfig: &CheckConfig{Thresholds: CheckThresholds{CertExpiryWarningDays: 120}},
+	})
+	found := false
+	for _, category := range hc.categories {
+		for _, checker := range category.checkers {
+			if checker.id == "l5d-identity-issuer-cert-not-expiring-soon" {
+				found = true
+				if expected := "issuer cert is valid for at least 120 days"; checker.description != expected {
+					t.Errorf("Expected description %q, got %q", expected, checker.description)
+				}
+			}
+		}
+	}
+	if !found {
+		t.Error("Issuer cert expiry check not found")
+	}
+}
+
+func TestCheckerIDs(t *testing.T) {
+	hc := NewHealthChecker([]CategoryID{}, &Options{})
+	ids := map[string]CategoryID{}
+	for _, category := range hc.categories {
+		for _, checker := range category.checkers {
+			if checker.id == "" {
+				t.Errorf("Expected check %q of %s to have an ID", checker.description, category.ID)
+				continue
+			}
+			if other, ok := ids[checker.id]; ok {
+				t.Errorf("Expected check ID %q of %s to be unique, already used in %s", checker.id, category.ID, other)
+			}
+			ids[checker.id] = category.ID
+		}
+	}
+}
@@ -0,0 +1,476 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/connectivityprobe"
+	l5dcharts "github.com/linkerd/linkerd2/pkg/charts/linkerd2"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+const (
+	// ConnectivityProbeLabel is the label of the resources created by the
+	// connectivity checks, whose value identifies the run of the checks
+	ConnectivityProbeLabel = "linkerd.io/connectivity-probe"
+
+	connectivityProbeRoleLabel = "linkerd.io/connectivity-probe-role"
+	connectivityProbeContainer = "probe"
+
+	connectivityServerRole         = "server"
+	connectivityClientRole         = "client"
+	connectivityUnmeshedClientRole = "unmeshed-client"
+
+	// connectivityProbeDeadline bounds the lifetime of the probe pods, in
+	// case they aren't cleaned up
+	connectivityProbeDeadline = int64(600)
+)
+
+type (
+	// ConnectivityOptions configures the LinkerdConnectivityChecks.
+	ConnectivityOptions struct {
+		// Namespace of the probe server; it's created, and deleted afterwards,
+		// if it doesn't exist
+		Namespace string
+		// ClientNamespace is the namespace of the probe clients, which
+		// defaults to Namespace
+		ClientNamespace string
+		// ServerNode and ClientNode are the nodes on which the probe server
+		// and clients are scheduled (default: any node)
+		ServerNode string
+		ClientNode string
+		// Image is the image of the probe pods, which defaults to the
+		// controller image
+		Image string
+	}
+
+	// connectivityProbe holds the state of a run of the connectivity checks
+	connectivityProbe struct {
+		runID             string
+		namespace         string
+		clientNamespace   string
+		serverNode        string
+		clientNode        string
+		image             string
+		clusterDomain     string
+		imagePullSecrets  []corev1.LocalObjectReference
+		createdNamespaces []string
+	}
+)
+
+func (hc *HealthChecker) connectivityCheckers() []Checker {
+	return []Checker{
+		{
+			id:          "l5d-connectivity-probe-deployed",
+			description: "can deploy the connectivity probe",
+			hintAnchor:  "l5d-connectivity-probe-deployed",
+			fatal:       true,
+			check: func(ctx context.Context) error {
+				return hc.deployConnectivityProbe(ctx)
+			},
+		},
+		{
+			id:                  "l5d-connectivity-probe-server-ready",
+			description:         "connectivity probe server is ready",
+			hintAnchor:          "l5d-connectivity-probe-server-ready",
+			retryDeadline:       hc.RetryDeadline,
+			surfaceErrorOnRetry: true,
+			check: func(ctx context.Context) error {
+				return hc.checkConnectivityServerReady(ctx)
+			},
+		},
+		{
+			id:            "l5d-connectivity-mtls",
+			description:   "meshed clients reach the server over mTLS",
+			hintAnchor:    "l5d-connectivity-mtls",
+			retryDeadline: hc.RetryDeadline,
+			check: func(ctx context.Context) error {
+				result, err := hc.connectivityClientResult(ctx, connectivityClientRole)
+				if err != nil {
+					return err
+				}
+				return hc.checkConnectivityMTLS(result)
+			},
+		},
+		{
+			id:            "l5d-connectivity-opaque-ports",
+			description:   "opaque port traffic passes through",
+			hintAnchor:    "l5d-connectivity-opaque-ports",
+			retryDeadline: hc.RetryDeadline,
+			check: func(ctx context.Context) error {
+				result, err := hc.connectivityClientResult(ctx, connectivityClientRole)
+				if err != nil {
+					return err
+				}
+				if result.TCP == nil {
+					return errors.New("the client didn't send TCP traffic")
+				}
+				if result.TCP.Error != "" {
+					return fmt.Errorf("TCP traffic to opaque port %d failed: %s", connectivityprobe.TCPPort, result.TCP.Error)
+				}
+				return nil
+			},
+		},
+		{
+			id:            "l5d-connectivity-policy",
+			description:   "policy denies unauthenticated clients",
+			hintAnchor:    "l5d-connectivity-policy",
+			retryDeadline: hc.RetryDeadline,
+			check: func(ctx context.Context) error {
+				// The unmeshed client is only deployed once the server is
+				// ready, as requests failing before that would pass as denied
+				if err := hc.createConnectivityPod(ctx, connectivityUnmeshedClientRole); err != nil {
+					return err
+				}
+				result, err := hc.connectivityClientResult(ctx, connectivityUnmeshedClientRole)
+				if err != nil {
+					return err
+				}
+				var errs []string
+				for protocol, outcome := range map[string]*connectivityprobe.Outcome{"HTTP": result.HTTP, "TCP": result.TCP} {
+					if outcome != nil && outcome.Error != "" {
+						errs = append(errs, fmt.Sprintf("\t* %s: %s", protocol, outcome.Error))
+					}
+				}
+				if len(errs) > 0 {
+					sort.Strings(errs)
+					return fmt.Errorf("an unmeshed client wasn't denied by the %s policy of the server:\n%s", k8s.AllAuthenticated, strings.Join(errs, "\n"))
+				}
+				return nil
+			},
+		},
+		{
+			id:          "l5d-connectivity-probe-cleanup",
+			description: "connectivity probe resources are cleaned up",
+			hintAnchor:  "l5d-connectivity-probe-cleanup",
+			warning:     true,
+			check: func(ctx context.Context) error {
+				return hc.cleanupConnectivityProbe(ctx)
+			},
+		},
+	}
+}
+
+func newConnectivityProbe(options ConnectivityOptions, values *l5dcharts.Values) *connectivityProbe {
+	probe := &connectivityProbe{
+		runID:           rand.String(5),
+		namespace:       options.Namespace,
+		clientNamespace: options.ClientNamespace,
+		serverNode:      options.ServerNode,
+		clientNode:      options.ClientNode,
+		image:           options.Image,
+		clusterDomain:   values.ClusterDomain,
+	}
+	if probe.clientNamespace == "" {
+		probe.clientNamespace = probe.namespace
+	}
+	if probe.image == "" {
+		probe.image = fmt.Sprintf("%s:%s", values.ControllerImage, values.LinkerdVersion)
+	}
+	if probe.clusterDomain == "" {
+		probe.clusterDomain = "cluster.local"
+	}
+	for _, secret := range values.ImagePullSecrets {
+		probe.imagePullSecrets = append(probe.imagePullSecrets, corev1.LocalObjectReference{Name: secret["name"]})
+	}
+	return probe
+}
+
+// name returns the name of the pod of role, which is also the name of the
+// server's service.
+func (p *connectivityProbe) name(role string) string {
+	return fmt.Sprintf("linkerd-connectivity-%s-%s", role, p.runID)
+}
+
+func (p *connectivityProbe) labels(role string) map[string]string {
+	return map[string]string{
+		ConnectivityProbeLabel:     p.runID,
+		connectivityProbeRoleLabel: role,
+	}
+}
+
+func (p *connectivityProbe) namespaces() []string {
+	if p.clientNamespace == p.namespace {
+		return []string{p.namespace}
+	}
+	return []string{p.namespace, p.clientNamespace}
+}
+
+func (p *connectivityProbe) serverService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      p.name(connectivityServerRole),
+			Namespace: p.namespace,
+			Labels:    p.labels(connectivityServerRole),
+			Annotations: map[string]string{
+				k8s.ProxyOpaquePortsAnnotation: fmt.Sprintf("%d", connectivityprobe.TCPPort),
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: p.labels(connectivityServerRole),
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: connectivityprobe.HTTPPort, TargetPort: intstr.FromInt(connectivityprobe.HTTPPort)},
+				{Name: "tcp", Port: connectivityprobe.TCPPort, TargetPort: intstr.FromInt(connectivityprobe.TCPPort)},
+			},
+		},
+	}
+}
+
+// pod returns the pod of role. The server only accepts authenticated
+// clients, and marks its TCP port as opaque.
+func (p *connectivityProbe) pod(role string) *corev1.Pod {
+	namespace, node := p.clientNamespace, p.clientNode
+	annotations := map[string]string{k8s.ProxyInjectAnnotation: k8s.ProxyInjectEnabled}
+	args := []string{"connectivity-probe"}
+	var ports []corev1.ContainerPort
+
+	serverHost := fmt.Sprintf("%s.%s.svc.%s", p.name(connectivityServerRole), p.namespace, p.clusterDomain)
+	clientArgs := []string{
+		"-mode=client",
+		fmt.Sprintf("-server-http-addr=%s:%d", serverHost, connectivityprobe.HTTPPort),
+		fmt.Sprintf("-server-tcp-addr=%s:%d", serverHost, connectivityprobe.TCPPort),
+	}
+	switch role {
+	case connectivityServerRole:
+		namespace, node = p.namespace, p.serverNode
+		annotations[k8s.ProxyOpaquePortsAnnotation] = fmt.Sprintf("%d", connectivityprobe.TCPPort)
+		annotations[k8s.ProxyDefaultInboundPolicyAnnotation] = k8s.AllAuthenticated
+		args = append(args, "-mode=server")
+		ports = []corev1.ContainerPort{
+			{Name: "http", ContainerPort: connectivityprobe.HTTPPort},
+			{Name: "tcp", ContainerPort: connectivityprobe.TCPPort},
+		}
+	case connectivityClientRole:
+		args = append(args, clientArgs...)
+	case connectivityUnmeshedClientRole:
+		annotations[k8s.ProxyInjectAnnotation] = k8s.ProxyInjectDisabled
+		args = append(append(args, clientArgs...), "-expect-denied")
+	}
+
+	deadline := connectivityProbeDeadline
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        p.name(role),
+			Namespace:   namespace,
+			Labels:      p.labels(role),
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:         corev1.RestartPolicyNever,
+			ActiveDeadlineSeconds: &deadline,
+			ImagePullSecrets:      p.imagePullSecrets,
+			Containers: []corev1.Container{{
+				Name:  connectivityProbeContainer,
+				Image: p.image,
+				Args:  args,
+				Ports: ports,
+			}},
+		},
+	}
+	if node != "" {
+		pod.Spec.Affinity = &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchFields: []corev1.NodeSelectorRequirement{{
+							Key:      "metadata.name",
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{node},
+						}},
+					}},
+				},
+			},
+		}
+	}
+	return pod
+}
+
+// deployConnectivityProbe creates the namespaces of the probe if they don't
+// exist, along with the server and the meshed client, which retries its
+// requests until the server is ready. Everything is cleaned up on failure.
+func (hc *HealthChecker) deployConnectivityProbe(ctx context.Context) error {
+	if hc.linkerdConfig == nil {
+		return errors.New("the Linkerd configuration is required to deploy the connectivity probe")
+	}
+	hc.connectivityProbe = newConnectivityProbe(hc.Connectivity, hc.linkerdConfig)
+
+	err := hc.createConnectivityProbe(ctx)
+	if err != nil {
+		if cleanupErr := hc.cleanupConnectivityProbe(ctx); cleanupErr != nil {
+			return fmt.Errorf("%w; cleanup failed: %s", err, cleanupErr)
+		}
+	}
+	return err
+}
+
+func (hc *HealthChecker) createConnectivityProbe(ctx context.Context) error {
+	probe := hc.connectivityProbe
+	for _, name := range probe.namespaces() {
+		_, err := hc.kubeAPI.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			continue
+		}
+		if !kerrors.IsNotFound(err) {
+			return err
+		}
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{ConnectivityProbeLabel: probe.runID},
+		}}
+		if _, err := hc.kubeAPI.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+		probe.createdNamespaces = append(probe.createdNamespaces, name)
+		if err := hc.waitForDefaultServiceAccount(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	if _, err := hc.kubeAPI.CoreV1().Services(probe.namespace).Create(ctx, probe.serverService(), metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	for _, role := range []string{connectivityServerRole, connectivityClientRole} {
+		if err := hc.createConnectivityPod(ctx, role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForDefaultServiceAccount waits for the default service account of a
+// new namespace, without which pods can't be created.
+func (hc *HealthChecker) waitForDefaultServiceAccount(ctx context.Context, namespace string) error {
+	for {
+		_, err := hc.kubeAPI.CoreV1().ServiceAccounts(namespace).Get(ctx, "default", metav1.GetOptions{})
+		if !kerrors.IsNotFound(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("the default service account of namespace %s wasn't created: %w", namespace, ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// createConnectivityPod creates the pod of role, unless it already exists.
+func (hc *HealthChecker) createConnectivityPod(ctx context.Context, role string) error {
+	pod := hc.connectivityProbe.pod(role)
+	_, err := hc.kubeAPI.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if kerrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func (hc *HealthChecker) checkConnectivityServerReady(ctx context.Context) error {
+	probe := hc.connectivityProbe
+	pod, err := hc.kubeAPI.CoreV1().Pods(probe.namespace).Get(ctx, probe.name(connectivityServerRole), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
+		return fmt.Errorf("pod %s/%s terminated: %s", pod.Namespace, pod.Name, pod.Status.Phase)
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			return nil
+		}
+	}
+	return fmt.Errorf("pod %s/%s isn't ready", pod.Namespace, pod.Name)
+}
+
+// connectivityClientResult returns the result of the client of role, read
+// from the termination message of its probe container.
+func (hc *HealthChecker) connectivityClientResult(ctx context.Context, role string) (*connectivityprobe.Result, error) {
+	probe := hc.connectivityProbe
+	pod, err := hc.kubeAPI.CoreV1().Pods(probe.clientNamespace).Get(ctx, probe.name(role), metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != connectivityProbeContainer {
+			continue
+		}
+		if status.State.Terminated == nil {
+			break
+		}
+		var result connectivityprobe.Result
+		if err := json.Unmarshal([]byte(status.State.Terminated.Message), &result); err != nil {
+			return nil, fmt.Errorf("invalid result of pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+		return &result, nil
+	}
+	return nil, fmt.Errorf("pod %s/%s didn't complete", pod.Namespace, pod.Name)
+}
+
+// checkConnectivityMTLS checks that the meshed client reached the server, and
+// that the server saw the identity of the client, which is only known to the
+// server's proxy over mTLS.
+func (hc *HealthChecker) checkConnectivityMTLS(result *connectivityprobe.Result) error {
+	if result.HTTP == nil {
+		return errors.New("the client didn't send HTTP traffic")
+	}
+	if result.HTTP.Error != "" {
+		return fmt.Errorf("HTTP request failed: %s", result.HTTP.Error)
+	}
+	if result.HTTP.ClientID == "" {
+		return errors.New("the connection isn't mTLS: the server didn't get the identity of the client")
+	}
+	expected := fmt.Sprintf("default.%s.serviceaccount.identity.%s.%s", hc.connectivityProbe.clientNamespace, hc.ControlPlaneNamespace, hc.linkerdConfig.IdentityTrustDomain)
+	if result.HTTP.ClientID != expected {
+		return fmt.Errorf("the server got the identity %s instead of %s", result.HTTP.ClientID, expected)
+	}
+	return nil
+}
+
+// cleanupConnectivityProbe deletes the resources created by the connectivity
+// checks, including the namespaces they created.
+func (hc *HealthChecker) cleanupConnectivityProbe(ctx context.Context) error {
+	probe := hc.connectivityProbe
+	if probe == nil {
+		return nil
+	}
+	background := metav1.DeletePropagationBackground
+	options := metav1.DeleteOptions{PropagationPolicy: &background}
+	selector := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", ConnectivityProbeLabel, probe.runID)}
+
+	var errs []string
+	for _, ns := range probe.namespaces() {
+		pods, err := hc.kubeAPI.CoreV1().Pods(ns).List(ctx, selector)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("\t* pods in namespace %s: %s", ns, err))
+			continue
+		}
+		for _, pod := range pods.Items {
+			if err := hc.kubeAPI.CoreV1().Pods(ns).Delete(ctx, pod.Name, options); err != nil && !kerrors.IsNotFound(err) {
+				errs = append(errs, fmt.Sprintf("\t* pod %s/%s: %s", ns, pod.Name, err))
+			}
+		}
+	}
+	err := hc.kubeAPI.CoreV1().Services(probe.namespace).Delete(ctx, probe.name(connectivityServerRole), options)
+	if err != nil && !kerrors.IsNotFound(err) {
+		errs = append(errs, fmt.Sprintf("\t* service %s/%s: %s", probe.namespace, probe.name(connectivityServerRole), err))
+	}
+	for _, ns := range probe.createdNamespaces {
+		if err := hc.kubeAPI.CoreV1().Namespaces().Delete(ctx, ns, options); err != nil && !kerrors.IsNotFound(err) {
+			errs = append(errs, fmt.Sprintf("\t* namespace %s: %s", ns, err))
+		}
+	}
+	hc.connectivityProbe = nil
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to delete the resources labeled %s=%s:\n%s", ConnectivityProbeLabel, probe.runID, strings.Join(errs, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/linkerd/linkerd2/controller/connectivityprobe"
+	l5dcharts "github.com/linkerd/linkerd2/pkg/charts/linkerd2"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConnectivityProbePod(t *testing.T) {
+	probe := newConnectivityProbe(ConnectivityOptions{
+		Namespace:       "probe",
+		ClientNamespace: "probe-clients",
+		ServerNode:      "node-1",
+	}, &l5dcharts.Values{
+		ControllerImage:  "cr.l5d.io/linkerd/controller",
+		LinkerdVersion:   "stable-2.14.0",
+		ImagePullSecrets: []map[string]string{{"name": "registry"}},
+	})
+	probe.runID = "abcde"
+
+	server := probe.pod(connectivityServerRole)
+	if server.Namespace != "probe" || server.Name != "linkerd-connectivity-server-abcde" {
+		t.Errorf("Unexpected server pod %s/%s", server.Namespace, server.Name)
+	}
+	expectedAnnotations := map[string]string{
+		k8s.ProxyInjectAnnotation:               k8s.ProxyInjectEnabled,
+		k8s.ProxyOpaquePortsAnnotation:          "8081",
+		k8s.ProxyDefaultInboundPolicyAnnotation: k8s.AllAuthenticated,
+	}
+	if diff := deep.Equal(server.Annotations, expectedAnnotations); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+	if diff := deep.Equal(server.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchFields[0].Values, []string{"node-1"}); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+	container := server.Spec.Containers[0]
+	if container.Image != "cr.l5d.io/linkerd/controller:stable-2.14.0" {
+		t.Errorf("Unexpected image %s", container.Image)
+	}
+	if diff := deep.Equal(server.Spec.ImagePullSecrets, []corev1.LocalObjectReference{{Name: "registry"}}); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+
+	client := probe.pod(connectivityUnmeshedClientRole)
+	if client.Namespace != "probe-clients" || client.Spec.Affinity != nil {
+		t.Errorf("Unexpected client pod %s/%s", client.Namespace, client.Name)
+	}
+	if client.Annotations[k8s.ProxyInjectAnnotation] != k8s.ProxyInjectDisabled {
+		t.Errorf("Expected the unmeshed client not to be injected")
+	}
+	expectedArgs := []string{
+		"connectivity-probe",
+		"-mode=client",
+		"-server-http-addr=linkerd-connectivity-server-abcde.probe.svc.cluster.local:8080",
+		"-server-tcp-addr=linkerd-connectivity-server-abcde.probe.svc.cluster.local:8081",
+		"-expect-denied",
+	}
+	if diff := deep.Equal(client.Spec.Containers[0].Args, expectedArgs); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+}
+
+func TestConnectivityChecks(t *testing.T) {
+	testCases := []struct {
+		description string
+		result      connectivityprobe.Result
+		expected    string
+	}{
+		{
+			description: "mTLS",
+			result: connectivityprobe.Result{
+				HTTP: &connectivityprobe.Outcome{ClientID: "default.probe.serviceaccount.identity.linkerd.cluster.local"},
+			},
+		},
+		{
+			description: "failed request",
+			result: connectivityprobe.Result{
+				HTTP: &connectivityprobe.Outcome{Error: "connection refused"},
+			},
+			expected: "HTTP request failed: connection refused",
+		},
+		{
+			description: "plaintext",
+			result: connectivityprobe.Result{
+				HTTP: &connectivityprobe.Outcome{},
+			},
+			expected: "the connection isn't mTLS: the server didn't get the identity of the client",
+		},
+		{
+			description: "unexpected identity",
+			result: connectivityprobe.Result{
+				HTTP: &connectivityprobe.Outcome{ClientID: "default.emojivoto.serviceaccount.identity.linkerd.cluster.local"},
+			},
+			expected: "the server got the identity default.emojivoto.serviceaccount.identity.linkerd.cluster.local instead of default.probe.serviceaccount.identity.linkerd.cluster.local",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.description, func(t *testing.T) {
+			hc := NewHealthChecker([]CategoryID{}, &Options{
+				ControlPlaneNamespace: "linkerd",
+				Connectivity:          ConnectivityOptions{Namespace: "probe"},
+			})
+			var err error
+			hc.kubeAPI, err = k8s.NewFakeAPI(`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: probe
+`)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			hc.linkerdConfig = &l5dcharts.Values{IdentityTrustDomain: "cluster.local"}
+
+			ctx := context.Background()
+			if err := hc.deployConnectivityProbe(ctx); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			probe := hc.connectivityProbe
+
+			_, err = hc.connectivityClientResult(ctx, connectivityClientRole)
+			expectedErr := fmt.Sprintf("pod probe/%s didn't complete", probe.name(connectivityClientRole))
+			if err == nil || err.Error() != expectedErr {
+				t.Fatalf("Expected error %q, got %v", expectedErr, err)
+			}
+
+			message, err := json.Marshal(tc.result)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			pods := hc.kubeAPI.CoreV1().Pods("probe")
+			pod, err := pods.Get(ctx, probe.name(connectivityClientRole), metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name:  connectivityProbeContainer,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: string(message)}},
+			}}
+			if _, err := pods.UpdateStatus(ctx, pod, metav1.UpdateOptions{}); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			result, err := hc.connectivityClientResult(ctx, connectivityClientRole)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			err = hc.checkConnectivityMTLS(result)
+			if tc.expected == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if tc.expected != "" && (err == nil || err.Error() != tc.expected) {
+				t.Errorf("Expected error %q, got %v", tc.expected, err)
+			}
+
+			if err := hc.cleanupConnectivityProbe(ctx); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			remaining, err := pods.List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if len(remaining.Items) != 0 {
+				t.Errorf("Expected the probe pods to be deleted, got %d", len(remaining.Items))
+			}
+			if _, err := hc.kubeAPI.CoreV1().Namespaces().Get(ctx, "probe", metav1.GetOptions{}); err != nil {
+				t.Errorf("Expected the existing namespace to be kept: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,498 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/tls"
+	"github.com/linkerd/linkerd2/pkg/util"
+	log "github.com/sirupsen/logrus"
+	admissionRegistration "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// restartedAtAnnotation is the pod template annotation set by
+	// `kubectl rollout restart`
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+	// webhookCertValidity is the validity of the renewed webhook certificates,
+	// which is the one of the certificates generated by the chart
+	webhookCertValidity = 365 * 24 * time.Hour
+
+	certManagerInjectCAFromAnnotation       = "cert-manager.io/inject-ca-from"
+	certManagerInjectCAFromSecretAnnotation = "cert-manager.io/inject-ca-from-secret"
+)
+
+var (
+	servicesResource                        = corev1.SchemeGroupVersion.WithResource("services")
+	namespacesResource                      = corev1.SchemeGroupVersion.WithResource("namespaces")
+	secretsResource                         = corev1.SchemeGroupVersion.WithResource("secrets")
+	deploymentsResource                     = appsv1.SchemeGroupVersion.WithResource("deployments")
+	statefulSetsResource                    = appsv1.SchemeGroupVersion.WithResource("statefulsets")
+	daemonSetsResource                      = appsv1.SchemeGroupVersion.WithResource("daemonsets")
+	mutatingWebhookConfigurationsResource   = admissionRegistration.SchemeGroupVersion.WithResource("mutatingwebhookconfigurations")
+	validatingWebhookConfigurationsResource = admissionRegistration.SchemeGroupVersion.WithResource("validatingwebhookconfigurations")
+
+	proxyInjectorWebhook = webhook{
+		secretName:  proxyInjectorTLSSecretName,
+		serviceName: k8s.ProxyInjectorWebhookServiceName,
+		configs: []webhookConfig{
+			{mutatingWebhookConfigurationsResource, k8s.ProxyInjectorWebhookConfigName},
+			{validatingWebhookConfigurationsResource, "linkerd-proxy-injector-enforcement-webhook-config"},
+		},
+	}
+	spValidatorWebhook = webhook{
+		secretName:  spValidatorTLSSecretName,
+		serviceName: k8s.SPValidatorWebhookServiceName,
+		configs: []webhookConfig{
+			{validatingWebhookConfigurationsResource, k8s.SPValidatorWebhookConfigName},
+		},
+	}
+	policyValidatorWebhook = webhook{
+		secretName:  policyValidatorTLSSecretName,
+		serviceName: "linkerd-policy-validator",
+		configs: []webhookConfig{
+			{validatingWebhookConfigurationsResource, k8s.PolicyValidatorWebhookConfigName},
+		},
+		// the policy controller runs in the destination pod, which is
+		// restarted so that it serves the renewed certificate
+		deployment: "linkerd-destination",
+	}
+)
+
+type (
+	// Fix is a change to a Kubernetes resource remediating a failed check.
+	Fix struct {
+		// Description is the short description of the change that's printed
+		// to the command line
+		Description string
+
+		Resource  schema.GroupVersionResource
+		Namespace string
+		Name      string
+		PatchType types.PatchType
+		Patch     []byte
+
+		// sensitive indicates that the patch holds secrets and must not be
+		// printed
+		sensitive bool
+	}
+
+	// Remediation returns the fixes of a failed check.
+	Remediation func(context.Context) ([]*Fix, error)
+
+	webhook struct {
+		secretName  string
+		serviceName string
+		configs     []webhookConfig
+		deployment  string
+	}
+
+	webhookConfig struct {
+		resource schema.GroupVersionResource
+		name     string
+	}
+)
+
+// Target returns the patched resource, in resource.group namespace/name
+// format.
+func (f *Fix) Target() string {
+	name := f.Name
+	if f.Namespace != "" {
+		name = fmt.Sprintf("%s/%s", f.Namespace, f.Name)
+	}
+	return fmt.Sprintf("%s %s", f.Resource.GroupResource(), name)
+}
+
+// PrintablePatch returns the patch of the fix, unless it holds secrets.
+func (f *Fix) PrintablePatch() string {
+	if f.sensitive {
+		return "<redacted>"
+	}
+	return string(f.Patch)
+}
+
+// Apply patches the resource of the fix.
+func (f *Fix) Apply(ctx context.Context, kubeAPI *k8s.KubernetesAPI) error {
+	client := kubeAPI.DynamicClient.Resource(f.Resource)
+	var err error
+	if f.Namespace == "" {
+		_, err = client.Patch(ctx, f.Name, f.PatchType, f.Patch, metav1.PatchOptions{})
+	} else {
+		_, err = client.Namespace(f.Namespace).Patch(ctx, f.Name, f.PatchType, f.Patch, metav1.PatchOptions{})
+	}
+	return err
+}
+
+func newMergePatchFix(description string, resource schema.GroupVersionResource, namespace, name string, patch map[string]interface{}) (*Fix, error) {
+	bytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	return &Fix{
+		Description: description,
+		Resource:    resource,
+		Namespace:   namespace,
+		Name:        name,
+		PatchType:   types.MergePatchType,
+		Patch:       bytes,
+	}, nil
+}
+
+func newRestartFix(description string, resource schema.GroupVersionResource, namespace, name string, now time.Time) (*Fix, error) {
+	return newMergePatchFix(description, resource, namespace, name, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						restartedAtAnnotation: now.Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	})
+}
+
+// findFixes runs the remediation of a failed check. Failing to find fixes
+// doesn't change the outcome of the check, so errors are only logged.
+func (hc *HealthChecker) findFixes(c *Checker) []*Fix {
+	ctx, cancel := context.WithTimeout(context.Background(), RequestTimeout)
+	defer cancel()
+	fixes, err := c.remediation(ctx)
+	if err != nil {
+		log.Debugf("Unable to find fixes for check %q: %s", c.description, err)
+		return nil
+	}
+	return fixes
+}
+
+// opaquePortsFixes adds to the opaque ports annotation of the services the
+// ports targeting opaque ports of their pods. Pods marking fewer ports as
+// opaque than their services aren't fixed, as they're managed by workloads.
+func (hc *HealthChecker) opaquePortsFixes(ctx context.Context) ([]*Fix, error) {
+	var services []*corev1.Service
+	missing := map[*corev1.Service]map[int32]struct{}{}
+	err := hc.forEachServicePod(ctx, func(service *corev1.Service, pod *corev1.Pod) {
+		for _, port := range missingOpaqueServicePorts(service, pod) {
+			if _, ok := missing[service]; !ok {
+				services = append(services, service)
+				missing[service] = map[int32]struct{}{}
+			}
+			missing[service][port] = struct{}{}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fixes := []*Fix{}
+	for _, service := range services {
+		ports := []int{}
+		for port := range missing[service] {
+			ports = append(ports, int(port))
+		}
+		sort.Ints(ports)
+		added := []string{}
+		for _, port := range ports {
+			added = append(added, strconv.Itoa(port))
+		}
+
+		value := strings.Join(added, ",")
+		if current := service.Annotations[k8s.ProxyOpaquePortsAnnotation]; current != "" {
+			value = fmt.Sprintf("%s,%s", current, value)
+		}
+		fix, err := newMergePatchFix(
+			fmt.Sprintf("add %s to the %s annotation of service %s/%s", value, k8s.ProxyOpaquePortsAnnotation, service.Namespace, service.Name),
+			servicesResource, service.Namespace, service.Name,
+			map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						k8s.ProxyOpaquePortsAnnotation: value,
+					},
+				},
+			})
+		if err != nil {
+			return nil, err
+		}
+		fixes = append(fixes, fix)
+	}
+	return fixes, nil
+}
+
+// missingOpaqueServicePorts returns the ports of service targeting an opaque
+// port of pod, which aren't marked as opaque on service.
+func missingOpaqueServicePorts(service *corev1.Service, pod *corev1.Pod) []int32 {
+	podPorts := util.ParsePorts(pod.Annotations[k8s.ProxyOpaquePortsAnnotation])
+	svcPorts := util.ParsePorts(service.Annotations[k8s.ProxyOpaquePortsAnnotation])
+	namedPorts := util.GetNamedPorts(append(pod.Spec.InitContainers, pod.Spec.Containers...))
+
+	var missing []int32
+	for _, sp := range service.Spec.Ports {
+		if _, ok := svcPorts[uint32(sp.Port)]; ok {
+			continue
+		}
+		target := sp.Port
+		if sp.TargetPort.Type == intstr.String {
+			target = namedPorts[sp.TargetPort.StrVal]
+		} else if sp.TargetPort.IntVal != 0 {
+			target = sp.TargetPort.IntVal
+		}
+		if _, ok := podPorts[uint32(target)]; ok && target != 0 {
+			missing = append(missing, sp.Port)
+		}
+	}
+	return missing
+}
+
+// restartOutdatedProxiesFixes restarts the workloads of the pods running
+// outdated proxies, so that they get injected with the current proxy.
+func (hc *HealthChecker) restartOutdatedProxiesFixes(ctx context.Context) ([]*Fix, error) {
+	pods, err := hc.GetDataPlanePods(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	fixes := []*Fix{}
+	restarted := map[string]struct{}{}
+	for _, pod := range pods {
+		if !isProxyOutdated(pod, hc.LatestVersions) {
+			continue
+		}
+		resource, name, err := hc.podWorkload(ctx, &pod)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			log.Debugf("Pod %s/%s isn't owned by a workload that can be restarted", pod.Namespace, pod.Name)
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%s", resource.Resource, pod.Namespace, name)
+		if _, ok := restarted[key]; ok {
+			continue
+		}
+		restarted[key] = struct{}{}
+
+		description := fmt.Sprintf("restart %s %s/%s to update its proxies", strings.TrimSuffix(resource.Resource, "s"), pod.Namespace, name)
+		fix, err := newRestartFix(description, resource, pod.Namespace, name, now)
+		if err != nil {
+			return nil, err
+		}
+		fixes = append(fixes, fix)
+	}
+	return fixes, nil
+}
+
+// podWorkload returns the resource and name of the deployment, statefulset
+// or daemonset owning pod; the name is empty if pod isn't owned by any.
+func (hc *HealthChecker) podWorkload(ctx context.Context, pod *corev1.Pod) (schema.GroupVersionResource, string, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return schema.GroupVersionResource{}, "", nil
+	}
+	switch owner.Kind {
+	case "ReplicaSet":
+		rs, err := hc.kubeAPI.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return schema.GroupVersionResource{}, "", err
+		}
+		if rsOwner := metav1.GetControllerOf(rs); rsOwner != nil && rsOwner.Kind == "Deployment" {
+			return deploymentsResource, rsOwner.Name, nil
+		}
+	case "StatefulSet":
+		return statefulSetsResource, owner.Name, nil
+	case "DaemonSet":
+		return daemonSetsResource, owner.Name, nil
+	}
+	return schema.GroupVersionResource{}, "", nil
+}
+
+// extensionNsLabelsFixes labels the namespaces running an extension but
+// lacking the extension label. Namespaces running several extensions, or an
+// extension whose label is already on another namespace, aren't fixed.
+func (hc *HealthChecker) extensionNsLabelsFixes(ctx context.Context) ([]*Fix, error) {
+	unlabeled, err := hc.unlabeledExtensionNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	labeled, err := hc.kubeAPI.GetAllNamespacesWithExtensionLabel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	taken := map[string]struct{}{}
+	for _, ns := range labeled {
+		taken[ns.Labels[k8s.LinkerdExtensionLabel]] = struct{}{}
+	}
+
+	fixes := []*Fix{}
+	for _, ns := range sortedNamespaces(unlabeled) {
+		exts := unlabeled[ns]
+		if len(exts) != 1 {
+			continue
+		}
+		if _, ok := taken[exts[0]]; ok {
+			continue
+		}
+		taken[exts[0]] = struct{}{}
+
+		fix, err := newMergePatchFix(
+			fmt.Sprintf("add the %s=%s label to namespace %s", k8s.LinkerdExtensionLabel, exts[0], ns),
+			namespacesResource, "", ns,
+			map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{
+						k8s.LinkerdExtensionLabel: exts[0],
+					},
+				},
+			})
+		if err != nil {
+			return nil, err
+		}
+		fixes = append(fixes, fix)
+	}
+	return fixes, nil
+}
+
+// renewWebhookCertFixes replaces the certificate of a webhook with a new
+// self-signed one, like the one generated by the chart, and updates the CA
+// bundle of its webhook configurations. Certificates that aren't managed by
+// Linkerd, e.g. issued by cert-manager, aren't renewed.
+func (hc *HealthChecker) renewWebhookCertFixes(ctx context.Context, wh webhook) ([]*Fix, error) {
+	secret, err := hc.kubeAPI.CoreV1().Secrets(hc.ControlPlaneNamespace).Get(ctx, wh.secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := secret.Annotations[k8s.CreatedByAnnotation]; !ok {
+		return nil, fmt.Errorf("secret %s isn't managed by Linkerd", wh.secretName)
+	}
+
+	host := fmt.Sprintf("%s.%s.svc", wh.serviceName, hc.ControlPlaneNamespace)
+	crtPEM, keyPEM, err := generateWebhookCert(host, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	secretFix, err := newMergePatchFix(
+		fmt.Sprintf("renew the certificate in secret %s/%s, valid until %s", hc.ControlPlaneNamespace, wh.secretName, time.Now().Add(webhookCertValidity).Format(time.RFC3339)),
+		secretsResource, hc.ControlPlaneNamespace, wh.secretName,
+		map[string]interface{}{
+			"data": map[string]interface{}{
+				corev1.TLSCertKey:       crtPEM,
+				corev1.TLSPrivateKeyKey: keyPEM,
+			},
+		})
+	if err != nil {
+		return nil, err
+	}
+	secretFix.sensitive = true
+	fixes := []*Fix{secretFix}
+
+	for _, cfg := range wh.configs {
+		config, err := hc.kubeAPI.DynamicClient.Resource(cfg.resource).Get(ctx, cfg.name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		annotations := config.GetAnnotations()
+		if annotations[certManagerInjectCAFromAnnotation] != "" || annotations[certManagerInjectCAFromSecretAnnotation] != "" {
+			return nil, fmt.Errorf("the CA bundle of %s is injected by cert-manager", cfg.name)
+		}
+
+		webhooks, _ := config.Object["webhooks"].([]interface{})
+		ops := []map[string]interface{}{}
+		for i := range webhooks {
+			ops = append(ops, map[string]interface{}{
+				"op":    "replace",
+				"path":  fmt.Sprintf("/webhooks/%d/clientConfig/caBundle", i),
+				"value": crtPEM,
+			})
+		}
+		patch, err := json.Marshal(ops)
+		if err != nil {
+			return nil, err
+		}
+		fixes = append(fixes, &Fix{
+			Description: fmt.Sprintf("update the CA bundle of %s", cfg.name),
+			Resource:    cfg.resource,
+			Name:        cfg.name,
+			PatchType:   types.JSONPatchType,
+			Patch:       patch,
+		})
+	}
+
+	if wh.deployment != "" {
+		description := fmt.Sprintf("restart deployment %s/%s to serve the renewed certificate", hc.ControlPlaneNamespace, wh.deployment)
+		fix, err := newRestartFix(description, deploymentsResource, hc.ControlPlaneNamespace, wh.deployment, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		fixes = append(fixes, fix)
+	}
+	return fixes, nil
+}
+
+// generateWebhookCert returns a PEM encoded self-signed certificate for host,
+// along with its key.
+func generateWebhookCert(host string, now time.Time) ([]byte, []byte, error) {
+	key, err := tls.GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: host},
+		DNSNames:              []string{host},
+		NotBefore:             now.Add(-tls.DefaultClockSkewAllowance),
+		NotAfter:              now.Add(webhookCertValidity),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	crt, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := tls.EncodePrivateKeyPEM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []byte(tls.EncodeCertificatesPEM(crt)), keyPEM, nil
+}
+
+// sortedNamespaces returns the namespaces of unlabeled in order.
+func sortedNamespaces(unlabeled map[string][]string) []string {
+	names := []string{}
+	for ns := range unlabeled {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+	return names
+}
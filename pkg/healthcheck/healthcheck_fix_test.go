@@ -0,0 +1,359 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/tls"
+	"github.com/linkerd/linkerd2/pkg/version"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestMissingOpaqueServicePorts(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pod",
+			Annotations: map[string]string{k8s.ProxyOpaquePortsAnnotation: "9000,9100-9101"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "app",
+				Ports: []corev1.ContainerPort{{Name: "tcp", ContainerPort: 9100}},
+			}},
+		},
+	}
+
+	testCases := []struct {
+		description string
+		annotation  string
+		ports       []corev1.ServicePort
+		expected    []int32
+	}{
+		{
+			description: "port without target port",
+			ports:       []corev1.ServicePort{{Port: 9000}},
+			expected:    []int32{9000},
+		},
+		{
+			description: "integer target port",
+			ports:       []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(9101)}},
+			expected:    []int32{80},
+		},
+		{
+			description: "named target port",
+			ports:       []corev1.ServicePort{{Port: 81, TargetPort: intstr.FromString("tcp")}},
+			expected:    []int32{81},
+		},
+		{
+			description: "already opaque",
+			annotation:  "80",
+			ports:       []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(9000)}},
+		},
+		{
+			description: "not targeting an opaque port",
+			ports:       []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8080)}, {Port: 82, TargetPort: intstr.FromString("http")}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.description, func(t *testing.T) {
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "svc",
+					Annotations: map[string]string{k8s.ProxyOpaquePortsAnnotation: tc.annotation},
+				},
+				Spec: corev1.ServiceSpec{Ports: tc.ports},
+			}
+			if diff := deep.Equal(missingOpaqueServicePorts(service, pod), tc.expected); diff != nil {
+				t.Errorf("%+v", diff)
+			}
+		})
+	}
+}
+
+func TestRestartOutdatedProxiesFixes(t *testing.T) {
+	k8sConfigs := []string{`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: emojivoto
+spec:
+  template:
+    metadata:
+      annotations:
+        linkerd.io/inject: enabled
+`, `
+apiVersion: apps/v1
+kind: ReplicaSet
+metadata:
+  name: web-5f7f9b7d4
+  namespace: emojivoto
+  ownerReferences:
+  - apiVersion: apps/v1
+    kind: Deployment
+    name: web
+    controller: true
+`}
+	for _, name := range []string{"web-5f7f9b7d4-abcde", "web-5f7f9b7d4-fghij"} {
+		k8sConfigs = append(k8sConfigs, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: `+name+`
+  namespace: emojivoto
+  labels:
+    linkerd.io/control-plane-ns: linkerd
+  ownerReferences:
+  - apiVersion: apps/v1
+    kind: ReplicaSet
+    name: web-5f7f9b7d4
+    controller: true
+spec:
+  containers:
+  - name: linkerd-proxy
+    image: cr.l5d.io/linkerd/proxy:stable-2.0.0
+status:
+  phase: Running
+`)
+	}
+	k8sConfigs = append(k8sConfigs, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: standalone
+  namespace: emojivoto
+  labels:
+    linkerd.io/control-plane-ns: linkerd
+spec:
+  containers:
+  - name: linkerd-proxy
+    image: cr.l5d.io/linkerd/proxy:stable-2.0.0
+status:
+  phase: Running
+`)
+
+	hc := NewHealthChecker([]CategoryID{}, &Options{ControlPlaneNamespace: "linkerd"})
+	var err error
+	hc.kubeAPI, err = k8s.NewFakeAPI(k8sConfigs...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	hc.LatestVersions, err = version.NewChannels("stable-2.1.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	fixes, err := hc.restartOutdatedProxiesFixes(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(fixes) != 1 {
+		t.Fatalf("Expected a single fix, got %d", len(fixes))
+	}
+	if fixes[0].Description != "restart deployment emojivoto/web to update its proxies" {
+		t.Errorf("Unexpected description: %s", fixes[0].Description)
+	}
+	if fixes[0].Target() != "deployments.apps emojivoto/web" {
+		t.Errorf("Unexpected target: %s", fixes[0].Target())
+	}
+
+	if err := fixes[0].Apply(context.Background(), hc.kubeAPI); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	deploy, err := hc.kubeAPI.DynamicClient.Resource(deploymentsResource).Namespace("emojivoto").Get(context.Background(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	annotations, _, _ := unstructured.NestedStringMap(deploy.Object, "spec", "template", "metadata", "annotations")
+	if annotations[restartedAtAnnotation] == "" || annotations[k8s.ProxyInjectAnnotation] != "enabled" {
+		t.Errorf("Unexpected pod template annotations: %v", annotations)
+	}
+}
+
+func TestExtensionNsLabelsFixes(t *testing.T) {
+	k8sConfigs := []string{`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: viz
+`, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: mc-1
+  labels:
+    linkerd.io/extension: multicluster
+`, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: mc-2
+`}
+	for _, pod := range [][]string{{"web", "viz", "viz"}, {"gateway", "mc-1", "multicluster"}, {"service-mirror", "mc-2", "multicluster"}} {
+		k8sConfigs = append(k8sConfigs, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: `+pod[0]+`
+  namespace: `+pod[1]+`
+  labels:
+    linkerd.io/extension: `+pod[2]+`
+`)
+	}
+
+	hc := NewHealthChecker([]CategoryID{LinkerdExtensionChecks}, &Options{
+		ControlPlaneNamespace: "linkerd",
+		Remediate:             true,
+	})
+	var err error
+	hc.kubeAPI, err = k8s.NewFakeAPI(k8sConfigs...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var results []*CheckResult
+	hc.RunChecks(func(result *CheckResult) {
+		results = append(results, result)
+	})
+	if len(results) != 1 {
+		t.Fatalf("Expected a single result, got %d", len(results))
+	}
+	expectedErr := "some extensions have invalid configuration\n" +
+		"\t* namespace mc-2 runs the multicluster extension but lacks the \"linkerd.io/extension\" label\n" +
+		"\t* namespace viz runs the viz extension but lacks the \"linkerd.io/extension\" label"
+	if results[0].Err == nil || results[0].Err.Error() != expectedErr {
+		t.Fatalf("Unexpected error: %v", results[0].Err)
+	}
+
+	// mc-2 isn't labeled, as mc-1 already is
+	fixes := results[0].Fixes
+	if len(fixes) != 1 {
+		t.Fatalf("Expected a single fix, got %d", len(fixes))
+	}
+	expected := &Fix{
+		Description: "add the linkerd.io/extension=viz label to namespace viz",
+		Resource:    namespacesResource,
+		Name:        "viz",
+		PatchType:   "application/merge-patch+json",
+		Patch:       []byte(`{"metadata":{"labels":{"linkerd.io/extension":"viz"}}}`),
+	}
+	if diff := deep.Equal(fixes[0], expected); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+}
+
+func TestRenewWebhookCertFixes(t *testing.T) {
+	k8sConfigs := []string{`
+apiVersion: v1
+kind: Secret
+metadata:
+  name: linkerd-proxy-injector-k8s-tls
+  namespace: linkerd
+  annotations:
+    linkerd.io/created-by: linkerd/cli dev-undefined
+type: kubernetes.io/tls
+data:
+  tls.crt: ""
+  tls.key: ""
+`, `
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: linkerd-proxy-injector-webhook-config
+webhooks:
+- name: linkerd-proxy-injector.linkerd.io
+  clientConfig:
+    caBundle: ""
+`}
+
+	t.Run("renews the certificate", func(t *testing.T) {
+		hc := NewHealthChecker([]CategoryID{}, &Options{ControlPlaneNamespace: "linkerd"})
+		var err error
+		hc.kubeAPI, err = k8s.NewFakeAPI(k8sConfigs...)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		fixes, err := hc.renewWebhookCertFixes(context.Background(), proxyInjectorWebhook)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		// the enforcement webhook configuration doesn't exist
+		if len(fixes) != 2 {
+			t.Fatalf("Expected 2 fixes, got %d", len(fixes))
+		}
+		if fixes[0].PrintablePatch() != "<redacted>" {
+			t.Errorf("Expected the secret patch to be redacted, got %s", fixes[0].PrintablePatch())
+		}
+		for _, fix := range fixes {
+			if err := fix.Apply(context.Background(), hc.kubeAPI); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+		}
+
+		secret, err := hc.kubeAPI.DynamicClient.Resource(secretsResource).Namespace("linkerd").Get(context.Background(), proxyInjectorTLSSecretName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		var typed corev1.Secret
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(secret.Object, &typed); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		cred, err := tls.ValidateAndCreateCreds(string(typed.Data[corev1.TLSCertKey]), string(typed.Data[corev1.TLSPrivateKeyKey]))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		config, err := hc.kubeAPI.DynamicClient.Resource(mutatingWebhookConfigurationsResource).Get(context.Background(), k8s.ProxyInjectorWebhookConfigName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		webhooks, _, _ := unstructured.NestedSlice(config.Object, "webhooks")
+		caBundle, _, _ := unstructured.NestedString(webhooks[0].(map[string]interface{}), "clientConfig", "caBundle")
+		caBundlePEM, err := base64.StdEncoding.DecodeString(caBundle)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		anchors, err := tls.DecodePEMCertificates(string(caBundlePEM))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if err := hc.CheckCertAndAnchors(cred, anchors, "linkerd-proxy-injector.linkerd.svc"); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if err := hc.CheckCertAndAnchorsExpiringSoon(cred); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("skips certificates not managed by Linkerd", func(t *testing.T) {
+		hc := NewHealthChecker([]CategoryID{}, &Options{ControlPlaneNamespace: "linkerd"})
+		var err error
+		hc.kubeAPI, err = k8s.NewFakeAPI(`
+apiVersion: v1
+kind: Secret
+metadata:
+  name: linkerd-sp-validator-k8s-tls
+  namespace: linkerd
+type: kubernetes.io/tls
+`)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		_, err = hc.renewWebhookCertFixes(context.Background(), spValidatorWebhook)
+		if err == nil || err.Error() != "secret linkerd-sp-validator-k8s-tls isn't managed by Linkerd" {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
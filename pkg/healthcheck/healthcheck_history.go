@@ -0,0 +1,201 @@
+package healthcheck
+
+type (
+	// Recorder records the final results of the checks of the Runners it
+	// wraps, so that they can be saved as a CheckOutput and compared with
+	// later runs.
+	Recorder struct {
+		results []CheckResult
+	}
+
+	recordingRunner struct {
+		Runner
+		recorder *Recorder
+	}
+
+	// CheckComparison lists the checks whose result changed between runs.
+	CheckComparison struct {
+		// NewlyFailing are the checks whose result is worse than in the
+		// previous run, or that are failing and weren't run before
+		NewlyFailing []*CheckChange `json:"newlyFailing"`
+		// NewlyPassing are the checks that succeed, and didn't in the
+		// previous run
+		NewlyPassing []*CheckChange `json:"newlyPassing"`
+		// Flapping are the checks whose result changed, and then changed back
+		// across the runs; they aren't listed as newly failing or passing
+		Flapping []*CheckChange `json:"flapping"`
+	}
+
+	// CheckChange describes the results of a check across runs.
+	CheckChange struct {
+		Category    CategoryID `json:"categoryName"`
+		Description string     `json:"description"`
+		// Results are the results of the check in the runs it was part of,
+		// from the oldest to the current one
+		Results []CheckResultStr `json:"results"`
+		// Error is the error of the check in the current run
+		Error string `json:"error,omitempty"`
+	}
+
+	// checkKey identifies a check across runs. The description is only used
+	// for checks without an ID, as it can depend on the options, such as the
+	// certificate expiry threshold.
+	checkKey struct {
+		category    CategoryID
+		id          string
+		description string
+	}
+)
+
+// Record returns a Runner that runs the checks of runner, and records their
+// results. A nil Recorder returns runner as is.
+func (r *Recorder) Record(runner Runner) Runner {
+	if r == nil {
+		return runner
+	}
+	return &recordingRunner{Runner: runner, recorder: r}
+}
+
+// Output returns the recorded results, as rendered by `linkerd check -o json`.
+func (r *Recorder) Output() *CheckOutput {
+	success := true
+	for _, result := range r.results {
+		if result.Err != nil && !result.Warning {
+			success = false
+		}
+	}
+	return newCheckOutput(r.results, success)
+}
+
+// RunChecks implements the Runner interface
+func (r *recordingRunner) RunChecks(observer CheckObserver) (bool, bool) {
+	return r.Runner.RunChecks(func(result *CheckResult) {
+		if !result.Retry {
+			r.recorder.results = append(r.recorder.results, *result)
+		}
+		observer(result)
+	})
+}
+
+// newCheckOutput groups results by category into a CheckOutput.
+func newCheckOutput(results []CheckResult, success bool) *CheckOutput {
+	var categories []*CheckCategory
+	for _, result := range results {
+		if categories == nil || categories[len(categories)-1].Name != result.Category {
+			categories = append(categories, &CheckCategory{
+				Name:   result.Category,
+				Checks: []*Check{},
+			})
+		}
+
+		check := &Check{
+			ID:          result.ID,
+			Description: result.Description,
+			Result:      resultStr(&result),
+		}
+		if result.Err != nil {
+			check.Error = result.Err.Error()
+			check.Hint = result.HintURL
+		}
+		current := categories[len(categories)-1]
+		current.Checks = append(current.Checks, check)
+	}
+	return &CheckOutput{
+		Success:    success,
+		Categories: categories,
+	}
+}
+
+func resultStr(result *CheckResult) CheckResultStr {
+	if result.Err == nil {
+		return CheckSuccess
+	}
+	if result.Warning {
+		return CheckWarn
+	}
+	return CheckErr
+}
+
+// CompareCheckOutputs compares the current results of the checks with their
+// previous results, ordered from the oldest to the most recent run. Checks
+// that aren't part of the current run are ignored.
+func CompareCheckOutputs(previous []*CheckOutput, current *CheckOutput) *CheckComparison {
+	history := map[checkKey][]CheckResultStr{}
+	for _, output := range previous {
+		for _, category := range output.Categories {
+			for _, check := range category.Checks {
+				key := newCheckKey(category.Name, check)
+				history[key] = append(history[key], check.Result)
+			}
+		}
+	}
+
+	comparison := &CheckComparison{}
+	for _, category := range current.Categories {
+		for _, check := range category.Checks {
+			key := newCheckKey(category.Name, check)
+			change := &CheckChange{
+				Category:    category.Name,
+				Description: check.Description,
+				Results:     append(append([]CheckResultStr{}, history[key]...), check.Result),
+				Error:       check.Error,
+			}
+
+			// Absent checks are considered successful in the previous run
+			previousResult := CheckSuccess
+			if len(change.Results) > 1 {
+				previousResult = change.Results[len(change.Results)-2]
+			}
+
+			switch {
+			case flapping(change.Results):
+				comparison.Flapping = append(comparison.Flapping, change)
+			case severity(check.Result) > severity(previousResult):
+				comparison.NewlyFailing = append(comparison.NewlyFailing, change)
+			case check.Result == CheckSuccess && previousResult != CheckSuccess:
+				comparison.NewlyPassing = append(comparison.NewlyPassing, change)
+			}
+		}
+	}
+	return comparison
+}
+
+func newCheckKey(category CategoryID, check *Check) checkKey {
+	if check.ID != "" {
+		return checkKey{category: category, id: check.ID}
+	}
+	return checkKey{category: category, description: check.Description}
+}
+
+// Empty returns whether no check changed between runs.
+func (c *CheckComparison) Empty() bool {
+	return len(c.NewlyFailing) == 0 && len(c.NewlyPassing) == 0 && len(c.Flapping) == 0
+}
+
+// flapping returns whether a result comes back after changing, e.g. success,
+// error and success again, unlike steady changes such as success, warning and
+// error.
+func flapping(results []CheckResultStr) bool {
+	seen := map[CheckResultStr]struct{}{}
+	for i, result := range results {
+		if i > 0 && result == results[i-1] {
+			continue
+		}
+		if _, ok := seen[result]; ok {
+			return true
+		}
+		seen[result] = struct{}{}
+	}
+	return false
+}
+
+func severity(result CheckResultStr) int {
+	switch result {
+	case CheckWarn:
+		return 1
+	case CheckErr:
+		return 2
+	default:
+		return 0
+	}
+}
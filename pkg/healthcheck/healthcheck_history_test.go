@@ -0,0 +1,120 @@
+package healthcheck
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestRecorder(t *testing.T) {
+	results := CheckResults{Results: []CheckResult{
+		{Category: "cat1", Description: "retried", Retry: true, Err: errors.New("not yet")},
+		{Category: "cat1", Description: "retried"},
+		{Category: "cat1", Description: "warns", Warning: true, Err: errors.New("warning"), HintURL: "https://linkerd.io/checks/#warns"},
+		{Category: "cat2", Description: "fails", Err: errors.New("error")},
+	}}
+
+	recorder := &Recorder{}
+	recorder.Record(results).RunChecks(func(*CheckResult) {})
+
+	expected := &CheckOutput{
+		Success: false,
+		Categories: []*CheckCategory{
+			{
+				Name: "cat1",
+				Checks: []*Check{
+					{Description: "retried", Result: CheckSuccess},
+					{Description: "warns", Result: CheckWarn, Error: "warning", Hint: "https://linkerd.io/checks/#warns"},
+				},
+			},
+			{
+				Name: "cat2",
+				Checks: []*Check{
+					{Description: "fails", Result: CheckErr, Error: "error"},
+				},
+			},
+		},
+	}
+	if diff := deep.Equal(recorder.Output(), expected); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+
+	var nilRecorder *Recorder
+	if runner := nilRecorder.Record(results); runner == nil {
+		t.Error("Expected a nil Recorder to return the runner")
+	}
+}
+
+func TestCompareCheckOutputs(t *testing.T) {
+	output := func(results ...CheckResultStr) *CheckOutput {
+		descriptions := []string{"stable", "fails", "passes", "flaps", "worsens", "improves", "new"}
+		category := &CheckCategory{Name: "cat"}
+		for i, result := range results {
+			if result != "" {
+				category.Checks = append(category.Checks, &Check{Description: descriptions[i], Result: result})
+			}
+		}
+		return &CheckOutput{Categories: []*CheckCategory{category}}
+	}
+
+	previous := []*CheckOutput{
+		output(CheckSuccess, CheckSuccess, CheckErr, CheckErr, CheckSuccess, CheckErr),
+		output(CheckSuccess, CheckSuccess, CheckErr, CheckSuccess, CheckWarn, CheckErr),
+	}
+	current := output(CheckSuccess, CheckErr, CheckSuccess, CheckErr, CheckErr, CheckWarn, CheckWarn)
+	current.Categories[0].Checks[1].Error = "failed"
+
+	expected := &CheckComparison{
+		NewlyFailing: []*CheckChange{
+			{Category: "cat", Description: "fails", Results: []CheckResultStr{CheckSuccess, CheckSuccess, CheckErr}, Error: "failed"},
+			{Category: "cat", Description: "worsens", Results: []CheckResultStr{CheckSuccess, CheckWarn, CheckErr}},
+			{Category: "cat", Description: "new", Results: []CheckResultStr{CheckWarn}},
+		},
+		NewlyPassing: []*CheckChange{
+			{Category: "cat", Description: "passes", Results: []CheckResultStr{CheckErr, CheckErr, CheckSuccess}},
+		},
+		Flapping: []*CheckChange{
+			{Category: "cat", Description: "flaps", Results: []CheckResultStr{CheckErr, CheckSuccess, CheckErr}},
+		},
+	}
+	comparison := CompareCheckOutputs(previous, current)
+	if diff := deep.Equal(comparison, expected); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+
+	if !CompareCheckOutputs([]*CheckOutput{current}, current).Empty() {
+		t.Error("Expected no changes between identical runs")
+	}
+}
+
+func TestCompareCheckOutputsByID(t *testing.T) {
+	output := func(days int, result CheckResultStr) *CheckOutput {
+		return &CheckOutput{Categories: []*CheckCategory{{
+			Name: LinkerdIdentity,
+			Checks: []*Check{{
+				ID:          "l5d-identity-issuer-cert-not-expiring-soon",
+				Description: fmt.Sprintf("issuer cert is valid for at least %d days", days),
+				Result:      result,
+			}},
+		}}}
+	}
+
+	// The description changes with the expiry threshold, but the check is
+	// still matched with its previous results through its ID.
+	previous := []*CheckOutput{output(60, CheckWarn)}
+	if comparison := CompareCheckOutputs(previous, output(90, CheckWarn)); !comparison.Empty() {
+		t.Errorf("Expected no changes, got %+v", comparison)
+	}
+
+	comparison := CompareCheckOutputs(previous, output(90, CheckSuccess))
+	expected := &CheckComparison{
+		NewlyPassing: []*CheckChange{
+			{Category: LinkerdIdentity, Description: "issuer cert is valid for at least 90 days", Results: []CheckResultStr{CheckWarn, CheckSuccess}},
+		},
+	}
+	if diff := deep.Equal(comparison, expected); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+}
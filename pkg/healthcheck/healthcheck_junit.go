@@ -0,0 +1,92 @@
+package healthcheck
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JUnit report, with a testsuite per category and a testcase per check.
+// Warnings don't fail the testcases, as they don't fail `linkerd check`;
+// they're reported in the testcase's system-err instead.
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+
+	junitTestSuite struct {
+		Name     string          `xml:"name,attr"`
+		Tests    int             `xml:"tests,attr"`
+		Failures int             `xml:"failures,attr"`
+		Cases    []junitTestCase `xml:"testcase"`
+	}
+
+	junitTestCase struct {
+		ClassName string        `xml:"classname,attr"`
+		Name      string        `xml:"name,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+		SystemErr string        `xml:"system-err,omitempty"`
+	}
+
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+func runChecksJUnit(wout io.Writer, werr io.Writer, hc Runner) (bool, bool) {
+	results, success, warning := collectResults(hc)
+
+	report := junitTestSuites{Name: "linkerd check"}
+	for _, result := range results {
+		if len(report.Suites) == 0 || report.Suites[len(report.Suites)-1].Name != string(result.Category) {
+			report.Suites = append(report.Suites, junitTestSuite{Name: string(result.Category)})
+		}
+		suite := &report.Suites[len(report.Suites)-1]
+
+		testCase := junitTestCase{
+			ClassName: string(result.Category),
+			Name:      result.Description,
+		}
+		if result.Err != nil {
+			details := resultDetails(&result)
+			if result.Warning {
+				testCase.SystemErr = details
+			} else {
+				testCase.Failure = &junitFailure{
+					Message: result.Err.Error(),
+					Type:    string(CheckErr),
+					Text:    details,
+				}
+				suite.Failures++
+				report.Failures++
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+		report.Tests++
+	}
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Fprintf(werr, "JUnit serialization of the check result failed with %s", err)
+		return success, warning
+	}
+	fmt.Fprintf(wout, "%s%s\n", xml.Header, out)
+	return success, warning
+}
+
+// resultDetails returns the error of a failed check, followed by its hint.
+func resultDetails(result *CheckResult) string {
+	details := []string{result.Err.Error()}
+	if result.HintURL != "" {
+		details = append(details, fmt.Sprintf("see %s for hints", result.HintURL))
+	}
+	return strings.Join(details, "\n")
+}
@@ -23,6 +23,10 @@ const (
 	WideOutput = "wide"
 	// ShortOutput is used to specify the short output format
 	ShortOutput = "short"
+	// JUnitOutput is used to specify the JUnit XML output format
+	JUnitOutput = "junit"
+	// SARIFOutput is used to specify the SARIF output format
+	SARIFOutput = "sarif"
 
 	// DefaultHintBaseURL is the default base URL on the linkerd.io website
 	// that all check hints for the latest linkerd version point to. Each
@@ -82,6 +86,7 @@ type CheckCategory struct {
 // Check is a user-facing version of `healthcheck.CheckResult`, for output via
 // `linkerd check -o json`.
 type Check struct {
+	ID          string         `json:"id,omitempty"`
 	Description string         `json:"description"`
 	Hint        string         `json:"hint,omitempty"`
 	Error       string         `json:"error,omitempty"`
@@ -107,9 +112,31 @@ func (cr CheckResults) RunChecks(observer CheckObserver) (bool, bool) {
 	return success, warning
 }
 
+// Runners runs several Runners in order as a single one, so that their
+// results are rendered together, e.g. in a single JUnit or SARIF report.
+type Runners []Runner
+
+// RunChecks runs the checks of every Runner, and aggregates their results.
+func (runners Runners) RunChecks(observer CheckObserver) (bool, bool) {
+	success := true
+	warning := false
+	for _, r := range runners {
+		s, w := r.RunChecks(observer)
+		success = success && s
+		warning = warning || w
+	}
+	return success, warning
+}
+
+// IsReportOutput returns whether output is a report format, whose results
+// must be rendered at once in a single document.
+func IsReportOutput(output string) bool {
+	return output == JUnitOutput || output == SARIFOutput
+}
+
 // PrintChecksResult writes the checks result.
 func PrintChecksResult(wout io.Writer, output string, success bool, warning bool) {
-	if output == JSONOutput {
+	if output == JSONOutput || IsReportOutput(output) {
 		return
 	}
 
@@ -123,8 +150,13 @@ func PrintChecksResult(wout io.Writer, output string, success bool, warning bool
 
 // RunChecks runs the checks that are part of hc
 func RunChecks(wout io.Writer, werr io.Writer, hc Runner, output string) (bool, bool) {
-	if output == JSONOutput {
+	switch output {
+	case JSONOutput:
 		return runChecksJSON(wout, werr, hc)
+	case JUnitOutput:
+		return runChecksJUnit(wout, werr, hc)
+	case SARIFOutput:
+		return runChecksSARIF(wout, werr, hc)
 	}
 
 	return runChecksTable(wout, hc, output)
@@ -203,49 +235,8 @@ const (
 )
 
 func runChecksJSON(wout io.Writer, werr io.Writer, hc Runner) (bool, bool) {
-	var categories []*CheckCategory
-
-	collectJSONOutput := func(result *CheckResult) {
-		if categories == nil || categories[len(categories)-1].Name != result.Category {
-			categories = append(categories, &CheckCategory{
-				Name:   result.Category,
-				Checks: []*Check{},
-			})
-		}
-
-		if !result.Retry {
-			currentCategory := categories[len(categories)-1]
-			// ignore checks that are going to be retried, we want only final results
-			status := CheckSuccess
-			if result.Err != nil {
-				status = CheckErr
-				if result.Warning {
-					status = CheckWarn
-				}
-			}
-
-			currentCheck := &Check{
-				Description: result.Description,
-				Result:      status,
-			}
-
-			if result.Err != nil {
-				currentCheck.Error = result.Err.Error()
-
-				if result.HintURL != "" {
-					currentCheck.Hint = result.HintURL
-				}
-			}
-			currentCategory.Checks = append(currentCategory.Checks, currentCheck)
-		}
-	}
-
-	success, warning := hc.RunChecks(collectJSONOutput)
-
-	outputJSON := CheckOutput{
-		Success:    success,
-		Categories: categories,
-	}
+	results, success, warning := collectResults(hc)
+	outputJSON := newCheckOutput(results, success)
 
 	resultJSON, err := json.MarshalIndent(outputJSON, "", "  ")
 	if err == nil {
@@ -256,6 +247,18 @@ func runChecksJSON(wout io.Writer, werr io.Writer, hc Runner) (bool, bool) {
 	return success, warning
 }
 
+// collectResults runs the checks of hc and returns their final results,
+// leaving out those that are going to be retried.
+func collectResults(hc Runner) ([]CheckResult, bool, bool) {
+	var results []CheckResult
+	success, warning := hc.RunChecks(func(result *CheckResult) {
+		if !result.Retry {
+			results = append(results, *result)
+		}
+	})
+	return results, success, warning
+}
+
 func printResultDescription(wout io.Writer, status string, result *CheckResult) {
 	fmt.Fprintf(wout, "%s %s\n", status, result.Description)
 
@@ -0,0 +1,139 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/linkerd/linkerd2/pkg/version"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIF log with a single run, where every check is a rule. Passed checks
+// are reported as results of kind "pass", so that scanners can tell them
+// apart from checks that didn't run. Failed checks are results, rather than
+// a failed execution of the tool.
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID               string       `json:"id"`
+		Name             string       `json:"name"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+		HelpURI          string       `json:"helpUri,omitempty"`
+		Properties       sarifProps   `json:"properties"`
+	}
+
+	sarifProps struct {
+		Category string `json:"category"`
+	}
+
+	sarifResult struct {
+		RuleID    string       `json:"ruleId"`
+		RuleIndex int          `json:"ruleIndex"`
+		Kind      string       `json:"kind"`
+		Level     string       `json:"level"`
+		Message   sarifMessage `json:"message"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+)
+
+func runChecksSARIF(wout io.Writer, werr io.Writer, hc Runner) (bool, bool) {
+	results, success, warning := collectResults(hc)
+
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "linkerd-check",
+				Version:        version.Version,
+				InformationURI: HintBaseURL(version.Version),
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+	ruleIndexes := map[string]int{}
+	for _, result := range results {
+		id := sarifRuleID(&result)
+		index, ok := ruleIndexes[id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndexes[id] = index
+			rule := sarifRule{
+				ID:               id,
+				Name:             result.Description,
+				ShortDescription: sarifMessage{Text: result.Description},
+				Properties:       sarifProps{Category: string(result.Category)},
+			}
+			// checks without a hint anchor only point to the checks page
+			if !strings.HasSuffix(result.HintURL, "#") {
+				rule.HelpURI = result.HintURL
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		sr := sarifResult{
+			RuleID:    id,
+			RuleIndex: index,
+			Kind:      "pass",
+			Level:     "none",
+			Message:   sarifMessage{Text: result.Description},
+		}
+		if result.Err != nil {
+			sr.Kind = "fail"
+			sr.Level = "error"
+			if result.Warning {
+				sr.Level = "warning"
+			}
+			sr.Message.Text = resultDetails(&result)
+		}
+		run.Results = append(run.Results, sr)
+	}
+
+	enc := json.NewEncoder(wout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	})
+	if err != nil {
+		fmt.Fprintf(werr, "SARIF serialization of the check result failed with %s", err)
+	}
+	return success, warning
+}
+
+// sarifRuleID identifies a check by its category and description, as hint
+// anchors are shared by several checks.
+func sarifRuleID(result *CheckResult) string {
+	return fmt.Sprintf("%s/%s", result.Category, result.Description)
+}
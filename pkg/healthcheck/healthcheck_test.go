@@ -3104,3 +3104,64 @@ func multiappend(slices ...[]string) []string {
 	}
 	return res
 }
+
+func TestLinkerdIdentityCheckReplicasIssuer(t *testing.T) {
+	lease := func(replica, fingerprint string) string {
+		return fmt.Sprintf(`
+apiVersion: coordination.k8s.io/v1
+kind: Lease
+metadata:
+  name: linkerd-identity-issuer-%s
+  namespace: linkerd
+  labels:
+    linkerd.io/control-plane-component: identity
+  annotations:
+    identity.linkerd.io/issuer-fingerprint: %s
+    identity.linkerd.io/issuer-not-before: "2026-01-01T00:00:00Z"
+spec:
+  holderIdentity: %s
+`, replica, fingerprint, replica)
+	}
+
+	testCases := []struct {
+		desc           string
+		leases         []string
+		expectedOutput []string
+	}{
+		{
+			desc:           "no issuer status leases",
+			expectedOutput: []string{"linkerd-identity-test-cat identity replicas use the same issuer"},
+		},
+		{
+			desc:           "replicas agree",
+			leases:         []string{lease("identity-a", "aaaa"), lease("identity-b", "aaaa")},
+			expectedOutput: []string{"linkerd-identity-test-cat identity replicas use the same issuer"},
+		},
+		{
+			desc:   "replicas diverge",
+			leases: []string{lease("identity-a", "aaaa"), lease("identity-b", "bbbb"), lease("identity-c", "bbbb")},
+			expectedOutput: []string{"linkerd-identity-test-cat identity replicas use the same issuer: identity replicas sign with different issuers:\n" +
+				"\tidentity-a (aaaa)\n\tidentity-b, identity-c (bbbb)"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			hc := NewHealthChecker([]CategoryID{}, &Options{})
+			hc.addCheckAsCategory("linkerd-identity-test-cat", LinkerdIdentity, "identity replicas use the same issuer")
+			hc.ControlPlaneNamespace = "linkerd"
+			var err error
+			hc.kubeAPI, err = k8s.NewFakeAPI(tc.leases...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			obs := newObserver()
+			hc.RunChecks(obs.resultFn)
+			if diff := deep.Equal(obs.results, tc.expectedOutput); diff != nil {
+				t.Fatalf("%+v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package identity
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	coordinationv1 "k8s.io/api/coordination/v1"
+)
+
+// IssuerStatus describes the issuer certificate an identity controller
+// replica is signing with, as published in its issuer status Lease.
+type IssuerStatus struct {
+	Replica     string
+	Fingerprint string
+	NotBefore   time.Time
+}
+
+// IssuerFingerprint returns the hex-encoded SHA-256 fingerprint of a
+// certificate.
+func IssuerFingerprint(crt *x509.Certificate) string {
+	sum := sha256.Sum256(crt.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// LiveIssuerStatuses extracts the issuer statuses from a list of Leases,
+// skipping Leases that aren't issuer status Leases and Leases that have not
+// been renewed within their lease duration. The result is sorted by replica.
+func LiveIssuerStatuses(leases []coordinationv1.Lease, now time.Time) []IssuerStatus {
+	var statuses []IssuerStatus
+	for _, lease := range leases {
+		if !strings.HasPrefix(lease.GetName(), k8s.IdentityIssuerStatusLeasePrefix) {
+			continue
+		}
+		fingerprint := lease.GetAnnotations()[k8s.IdentityIssuerFingerprintAnnotation]
+		if fingerprint == "" || lease.Spec.HolderIdentity == nil {
+			continue
+		}
+		if lease.Spec.RenewTime != nil && lease.Spec.LeaseDurationSeconds != nil {
+			expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+			if now.After(expiry) {
+				continue
+			}
+		}
+		// An unparseable NotBefore is treated as the zero time, so that such a
+		// replica never causes others to stop signing.
+		notBefore, _ := time.Parse(time.RFC3339, lease.GetAnnotations()[k8s.IdentityIssuerNotBeforeAnnotation])
+		statuses = append(statuses, IssuerStatus{
+			Replica:     *lease.Spec.HolderIdentity,
+			Fingerprint: fingerprint,
+			NotBefore:   notBefore,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Replica < statuses[j].Replica })
+	return statuses
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
@@ -52,6 +53,31 @@ type (
 
 		expectedName, issuerPathCrt, issuerPathKey string
 		issuerCertTTL                              time.Time
+
+		// spiffeTrustDomain, when set, causes issued certificates to carry a
+		// SPIFFE ID URI SAN in addition to the DNS-form identity.
+		spiffeTrustDomain string
+
+		// lifetimePolicy, when set, may override the lifetime of the
+		// certificates issued to individual identities.
+		lifetimePolicy LifetimePolicy
+
+		// issuerFingerprint and issuerNotBefore describe the current issuer
+		// certificate. Both are guarded by issuerMutex.
+		issuerFingerprint string
+		issuerNotBefore   time.Time
+
+		// signingSuspended holds the reason signing is suspended, if it is.
+		// It is guarded by issuerMutex.
+		signingSuspended string
+	}
+
+	// LifetimePolicy implementors determine the lifetime of the certificate
+	// issued to a given identity.
+	LifetimePolicy interface {
+		// Lifetime returns the lifetime to use for the provided DNS-form
+		// identity, and false if the default lifetime should be used.
+		Lifetime(identity string) (time.Duration, bool)
 	}
 
 	// Validator implementors accept a bearer token, validates it, and returns a
@@ -90,10 +116,49 @@ func (svc *Service) Initialize() error {
 func (svc *Service) updateIssuer(newIssuer tls.Issuer) {
 	svc.issuerMutex.Lock()
 	svc.issuer = &newIssuer
+	if ca, ok := newIssuer.(*tls.CA); ok {
+		fingerprint := IssuerFingerprint(ca.Cred.Certificate)
+		if fingerprint != svc.issuerFingerprint && svc.signingSuspended != "" {
+			log.Info("Resuming signing with the updated issuer")
+			svc.signingSuspended = ""
+		}
+		svc.issuerFingerprint = fingerprint
+		svc.issuerNotBefore = ca.Cred.Certificate.NotBefore
+	}
 	log.Debug("Issuer has been updated")
 	svc.issuerMutex.Unlock()
 }
 
+// IssuerStatus returns the SHA-256 fingerprint and NotBefore of the issuer
+// certificate currently used to sign certificates.
+func (svc *Service) IssuerStatus() (string, time.Time) {
+	svc.issuerMutex.RLock()
+	defer svc.issuerMutex.RUnlock()
+	return svc.issuerFingerprint, svc.issuerNotBefore
+}
+
+// SuspendSigning causes Certify to fail with codes.Unavailable, so that
+// proxies retry against another replica, until ResumeSigning is called or a
+// different issuer is loaded.
+func (svc *Service) SuspendSigning(reason string) {
+	svc.issuerMutex.Lock()
+	defer svc.issuerMutex.Unlock()
+	if svc.signingSuspended == "" {
+		log.Warnf("Suspending signing: %s", reason)
+	}
+	svc.signingSuspended = reason
+}
+
+// ResumeSigning reverts the effect of SuspendSigning.
+func (svc *Service) ResumeSigning() {
+	svc.issuerMutex.Lock()
+	defer svc.issuerMutex.Unlock()
+	if svc.signingSuspended != "" {
+		log.Info("Resuming signing")
+	}
+	svc.signingSuspended = ""
+}
+
 func (svc *Service) getIssuerCertTTL() float64 {
 	if svc.issuerCertTTL.IsZero() {
 		log.Warn("Issuer certificate not ready: cannot get TTL")
@@ -184,11 +249,28 @@ func NewService(validator Validator, trustAnchors *x509.CertPool, validity *tls.
 		issuerPathCrt,
 		issuerPathKey,
 		time.Time{},
+		"",
+		nil,
+		"",
+		time.Time{},
+		"",
 	}
 	svc.registerCertExpirationMetrics()
 	return svc
 }
 
+// EnableSpiffeURISAN configures the service to include a
+// `spiffe://<trustDomain>/ns/<ns>/sa/<sa>` URI SAN in issued certificates.
+func (svc *Service) EnableSpiffeURISAN(trustDomain string) {
+	svc.spiffeTrustDomain = trustDomain
+}
+
+// SetLifetimePolicy configures the policy used to override the lifetime of
+// issued certificates per identity.
+func (svc *Service) SetLifetimePolicy(policy LifetimePolicy) {
+	svc.lifetimePolicy = policy
+}
+
 // Register registers an identity service implementation in the provided gRPC
 // server.
 func Register(g *grpc.Server, s *Service) {
@@ -218,6 +300,11 @@ func (svc *Service) Certify(ctx context.Context, req *pb.CertifyRequest) (*pb.Ce
 		return nil, status.Error(codes.Unavailable, "cert issuer not ready yet")
 	}
 
+	if svc.signingSuspended != "" {
+		log.Debugf("Not signing CSR while signing is suspended: %s", svc.signingSuspended)
+		return nil, status.Error(codes.Unavailable, svc.signingSuspended)
+	}
+
 	// Extract the relevant info from the request.
 	reqIdentity, tok, csr, err := checkRequest(req)
 	if err != nil {
@@ -264,9 +351,21 @@ func (svc *Service) Certify(ctx context.Context, req *pb.CertifyRequest) (*pb.Ce
 		return nil, status.Error(codes.FailedPrecondition, msg)
 	}
 
+	if svc.spiffeTrustDomain != "" {
+		id, err := SpiffeID(svc.spiffeTrustDomain, tokIdentity)
+		if err != nil {
+			msg := fmt.Sprintf("could not build SPIFFE ID for %s: %s", tokIdentity, err)
+			log.Error(msg)
+			return nil, status.Error(codes.Internal, msg)
+		}
+		// Copy the CSR so that the parsed request isn't mutated.
+		spiffeCSR := *csr
+		spiffeCSR.URIs = []*url.URL{id}
+		csr = &spiffeCSR
+	}
+
 	// Create a certificate
-	issuer := *svc.issuer
-	crt, err := issuer.IssueEndEntityCrt(csr)
+	crt, err := svc.issue(csr, tokIdentity)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -301,6 +400,22 @@ func (svc *Service) Certify(ctx context.Context, req *pb.CertifyRequest) (*pb.Ce
 	return rsp, nil
 }
 
+// issue signs the CSR with the current issuer, honoring the lifetime policy
+// when one is configured.
+func (svc *Service) issue(csr *x509.CertificateRequest, identity string) (tls.Crt, error) {
+	issuer := *svc.issuer
+	if svc.lifetimePolicy != nil {
+		if lifetime, ok := svc.lifetimePolicy.Lifetime(identity); ok {
+			if ca, ok := issuer.(*tls.CA); ok {
+				log.Debugf("Issuing certificate for %s with lifetime %s", identity, lifetime)
+				return ca.IssueEndEntityCrtWithLifetime(csr, lifetime)
+			}
+			log.Warnf("Issuer does not support lifetime overrides; using the default lifetime for %s", identity)
+		}
+	}
+	return issuer.IssueEndEntityCrt(csr)
+}
+
 func checkRequest(req *pb.CertifyRequest) (string, []byte, *x509.CertificateRequest, error) {
 	reqIdentity := req.GetIdentity()
 	if reqIdentity == "" {
@@ -2,6 +2,7 @@ package identity
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	pb "github.com/linkerd/linkerd2-proxy-api/go/identity"
@@ -78,3 +79,26 @@ func TestInvalidRequestArguments(t *testing.T) {
 	}
 
 }
+
+func TestSuspendSigning(t *testing.T) {
+	svc := NewService(&fakeValidator{"successful-result", nil}, nil, nil, nil, "", "", "")
+	svc.updateIssuer(&fakeIssuer{tls.Crt{}, nil})
+	req := &pb.CertifyRequest{
+		Identity:                  "some-identity",
+		Token:                     []byte("token"),
+		CertificateSigningRequest: []byte("csr"),
+	}
+
+	svc.SuspendSigning("a quorum of replicas uses a newer issuer")
+	_, err := svc.Certify(context.TODO(), req)
+	expectedError := "rpc error: code = Unavailable desc = a quorum of replicas uses a newer issuer"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf("Expected error string \"%s\", got \"%v\"", expectedError, err)
+	}
+
+	svc.ResumeSigning()
+	_, err = svc.Certify(context.TODO(), req)
+	if err == nil || strings.Contains(err.Error(), "Unavailable") {
+		t.Fatalf("Expected request to be processed after resuming, got \"%v\"", err)
+	}
+}
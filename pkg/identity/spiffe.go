@@ -0,0 +1,157 @@
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// SpiffeScheme is the URI scheme used by SPIFFE IDs.
+	SpiffeScheme = "spiffe"
+
+	// DefaultBundleRefreshHint is the default interval after which consumers
+	// of the SPIFFE bundle endpoint are advised to fetch it again.
+	DefaultBundleRefreshHint = 5 * time.Minute
+
+	spiffeX509SVIDUse = "x509-svid"
+)
+
+type (
+	// BundleHandler serves the trust anchors as a SPIFFE trust bundle in JWKS
+	// format, as described by the SPIFFE Trust Domain and Bundle
+	// specification.
+	BundleHandler struct {
+		body []byte
+	}
+
+	spiffeBundle struct {
+		Keys        []jwk `json:"keys"`
+		RefreshHint int64 `json:"spiffe_refresh_hint,omitempty"`
+	}
+
+	jwk struct {
+		Use string   `json:"use"`
+		Kty string   `json:"kty"`
+		Crv string   `json:"crv,omitempty"`
+		X   string   `json:"x,omitempty"`
+		Y   string   `json:"y,omitempty"`
+		N   string   `json:"n,omitempty"`
+		E   string   `json:"e,omitempty"`
+		X5c []string `json:"x5c"`
+	}
+)
+
+// SpiffeID converts a DNS-form proxy identity (e.g.
+// `sa.ns.serviceaccount.identity.linkerd.cluster.local`) into a SPIFFE ID of
+// the form `spiffe://<trust-domain>/ns/<ns>/sa/<sa>`.
+func SpiffeID(trustDomain, dnsIdentity string) (*url.URL, error) {
+	if trustDomain == "" {
+		return nil, fmt.Errorf("SPIFFE trust domain must not be empty")
+	}
+	segments := strings.Split(dnsIdentity, ".")
+	if len(segments) < 4 || segments[2] != "serviceaccount" || segments[3] != "identity" {
+		return nil, fmt.Errorf("identity is not a service account identity: %s", dnsIdentity)
+	}
+	if segments[0] == "" || segments[1] == "" {
+		return nil, fmt.Errorf("identity is missing a name or namespace: %s", dnsIdentity)
+	}
+
+	return &url.URL{
+		Scheme: SpiffeScheme,
+		Host:   trustDomain,
+		Path:   fmt.Sprintf("/ns/%s/sa/%s", segments[1], segments[0]),
+	}, nil
+}
+
+// NewBundleHandler builds an http.Handler that serves the provided trust
+// anchors as a SPIFFE bundle. The response body is computed once, as the
+// trust anchors are only read at startup.
+func NewBundleHandler(trustAnchors []*x509.Certificate, refreshHint time.Duration) (*BundleHandler, error) {
+	if len(trustAnchors) == 0 {
+		return nil, fmt.Errorf("no trust anchors provided")
+	}
+
+	bundle := spiffeBundle{
+		Keys:        make([]jwk, 0, len(trustAnchors)),
+		RefreshHint: int64(refreshHint.Seconds()),
+	}
+	for _, crt := range trustAnchors {
+		key, err := toJWK(crt)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Keys = append(bundle.Keys, key)
+	}
+
+	body, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+	return &BundleHandler{body}, nil
+}
+
+func (h *BundleHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(h.body); err != nil {
+		log.Debugf("failed to write SPIFFE bundle: %s", err)
+	}
+}
+
+func toJWK(crt *x509.Certificate) (jwk, error) {
+	key := jwk{
+		Use: spiffeX509SVIDUse,
+		X5c: []string{base64.StdEncoding.EncodeToString(crt.Raw)},
+	}
+
+	switch pub := crt.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		key.Kty = "EC"
+		key.Crv = pub.Curve.Params().Name
+		key.X = base64.RawURLEncoding.EncodeToString(padBytes(pub.X, size))
+		key.Y = base64.RawURLEncoding.EncodeToString(padBytes(pub.Y, size))
+		switch pub.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		default:
+			return jwk{}, fmt.Errorf("unsupported curve for trust anchor %s: %s", crt.Subject, key.Crv)
+		}
+	case *rsa.PublicKey:
+		key.Kty = "RSA"
+		key.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		key.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	default:
+		return jwk{}, fmt.Errorf("unsupported public key type for trust anchor %s: %T", crt.Subject, pub)
+	}
+
+	return key, nil
+}
+
+// padBytes returns the big-endian representation of n, left-padded with zeros
+// to size bytes, as required for JWK EC coordinates.
+func padBytes(n *big.Int, size int) []byte {
+	b := n.Bytes()
+	if len(b) >= size {
+		return b
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return padded
+}
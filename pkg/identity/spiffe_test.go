@@ -0,0 +1,157 @@
+package identity
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/linkerd/linkerd2-proxy-api/go/identity"
+	"github.com/linkerd/linkerd2/pkg/tls"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestSpiffeID(t *testing.T) {
+	testCases := []struct {
+		trustDomain string
+		identity    string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			trustDomain: "cluster.local",
+			identity:    "default.emojivoto.serviceaccount.identity.linkerd.cluster.local",
+			expected:    "spiffe://cluster.local/ns/emojivoto/sa/default",
+		},
+		{
+			trustDomain: "cluster.local",
+			identity:    "web.emojivoto.deployment.identity.linkerd.cluster.local",
+			expectedErr: true,
+		},
+		{
+			trustDomain: "cluster.local",
+			identity:    "default",
+			expectedErr: true,
+		},
+		{
+			trustDomain: "",
+			identity:    "default.emojivoto.serviceaccount.identity.linkerd.cluster.local",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.identity, func(t *testing.T) {
+			id, err := SpiffeID(tc.trustDomain, tc.identity)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected error, got %s", id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if id.String() != tc.expected {
+				t.Fatalf("Expected %s, got %s", tc.expected, id)
+			}
+		})
+	}
+}
+
+func TestBundleHandler(t *testing.T) {
+	ca, err := tls.GenerateRootCAWithDefaults("identity.linkerd.cluster.local")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	handler, err := NewBundleHandler([]*x509.Certificate{ca.Cred.Crt.Certificate}, DefaultBundleRefreshHint)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	rsp := httptest.NewRecorder()
+	handler.ServeHTTP(rsp, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rsp.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rsp.Code)
+	}
+
+	var bundle spiffeBundle
+	if err := json.Unmarshal(rsp.Body.Bytes(), &bundle); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(bundle.Keys) != 1 {
+		t.Fatalf("Expected 1 key, got %d", len(bundle.Keys))
+	}
+	key := bundle.Keys[0]
+	if key.Use != spiffeX509SVIDUse || key.Kty != "EC" || key.Crv != "P-256" {
+		t.Fatalf("Unexpected key parameters: %+v", key)
+	}
+	if len(key.X) != 43 || len(key.Y) != 43 {
+		t.Fatalf("Expected unpadded 32-byte coordinates, got x=%s y=%s", key.X, key.Y)
+	}
+	if bundle.RefreshHint != int64(DefaultBundleRefreshHint.Seconds()) {
+		t.Fatalf("Expected refresh hint %v, got %d", DefaultBundleRefreshHint, bundle.RefreshHint)
+	}
+
+	rsp = httptest.NewRecorder()
+	handler.ServeHTTP(rsp, httptest.NewRequest(http.MethodPost, "/", nil))
+	if rsp.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected status %d, got %d", http.StatusMethodNotAllowed, rsp.Code)
+	}
+}
+
+func TestCertifyWithSpiffeURISAN(t *testing.T) {
+	const id = "default.emojivoto.serviceaccount.identity.linkerd.cluster.local"
+
+	root, err := tls.GenerateRootCAWithDefaults("identity.linkerd.cluster.local")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	ca, err := root.GenerateCA("identity.linkerd.cluster.local", -1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	recordEvent := func(runtime.Object, string, string, string) {}
+	svc := NewService(&fakeValidator{id, nil}, root.Cred.CertPool(), nil, recordEvent, "", "", "")
+	svc.EnableSpiffeURISAN("cluster.local")
+	svc.updateIssuer(ca)
+
+	key, err := tls.GenerateKey()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	csr, err := x509.CreateCertificateRequest(nil, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: id},
+		DNSNames: []string{id},
+	}, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	rsp, err := svc.Certify(context.Background(), &pb.CertifyRequest{
+		Identity:                  id,
+		Token:                     []byte("token"),
+		CertificateSigningRequest: csr,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	crt, err := x509.ParseCertificate(rsp.GetLeafCertificate())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(crt.DNSNames) != 1 || crt.DNSNames[0] != id {
+		t.Fatalf("Expected DNS SAN %s, got %v", id, crt.DNSNames)
+	}
+	expected := "spiffe://cluster.local/ns/emojivoto/sa/default"
+	if len(crt.URIs) != 1 || crt.URIs[0].String() != expected {
+		t.Fatalf("Expected URI SAN %s, got %v", expected, crt.URIs)
+	}
+}
@@ -0,0 +1,252 @@
+package inject
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	l5dcharts "github.com/linkerd/linkerd2/pkg/charts/linkerd2"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// RuleServerSpeaksFirst flags ports serving a server-speaks-first
+	// protocol that are neither opaque nor skipped. The proxy waits for the
+	// client to speak first to detect the protocol, so connections to these
+	// ports stall until protocol detection times out.
+	RuleServerSpeaksFirst = "server-speaks-first-port"
+
+	// RuleSkippedProbePort flags probed ports that are listed in
+	// skip-inbound-ports. Skipping them isn't needed for the probes to pass,
+	// and all the other inbound traffic to these ports bypasses the proxy
+	// too, so it's neither secured nor observed.
+	RuleSkippedProbePort = "skipped-probe-port"
+
+	// RuleProxyPortCollision flags container ports that collide with the
+	// ports the proxy listens on.
+	RuleProxyPortCollision = "proxy-port-collision"
+)
+
+// serverSpeaksFirstPorts are the well-known ports of server-speaks-first
+// protocols, and serverSpeaksFirstNames the keywords of their port names.
+var (
+	serverSpeaksFirstPorts = map[int32]string{
+		25:    "SMTP",
+		587:   "SMTP",
+		3306:  "MySQL",
+		4444:  "Galera",
+		5432:  "PostgreSQL",
+		6379:  "Redis",
+		9300:  "Elasticsearch",
+		11211: "Memcached",
+	}
+
+	serverSpeaksFirstNames = []struct{ keyword, protocol string }{
+		{"smtp", "SMTP"},
+		{"mysql", "MySQL"},
+		{"galera", "Galera"},
+		{"postgres", "PostgreSQL"},
+		{"redis", "Redis"},
+		{"elasticsearch", "Elasticsearch"},
+		{"memcache", "Memcached"},
+		{"nats", "NATS"},
+		{"ftp", "FTP"},
+	}
+)
+
+// PortFinding is a port or protocol misconfiguration of a workload, found by
+// one of the rules of AnalyzePorts.
+type PortFinding struct {
+	Rule      string `json:"rule"`
+	Container string `json:"container"`
+	Port      int32  `json:"port"`
+	Message   string `json:"message"`
+}
+
+// portRule checks the pod spec against the effective proxy configuration.
+type portRule func(spec *corev1.PodSpec, values *l5dcharts.Values, namedPorts map[string]int32) []PortFinding
+
+var portRules = []portRule{
+	checkServerSpeaksFirstPorts,
+	checkSkippedProbePorts,
+	checkProxyPortCollisions,
+}
+
+// AnalyzePorts runs the port and protocol rules against the pod spec of
+// conf, with the proxy configuration returned by overrider. Findings don't
+// prevent injection; they're reported as warnings.
+func (conf *ResourceConfig) AnalyzePorts(overrider ValueOverrider) ([]PortFinding, error) {
+	if !conf.HasPodTemplate() {
+		return nil, nil
+	}
+	values, err := overrider(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := conf.pod.spec
+	namedPorts := util.GetNamedPorts(append(spec.InitContainers, spec.Containers...))
+	var findings []PortFinding
+	for _, rule := range portRules {
+		findings = append(findings, rule(spec, values, namedPorts)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Container != findings[j].Container {
+			return findings[i].Container < findings[j].Container
+		}
+		return findings[i].Port < findings[j].Port
+	})
+	return findings, nil
+}
+
+// Warnings returns the messages of the findings, as returned in admission
+// responses.
+func Warnings(findings []PortFinding) []string {
+	var warnings []string
+	for _, f := range findings {
+		warnings = append(warnings, f.Message)
+	}
+	return warnings
+}
+
+func checkServerSpeaksFirstPorts(spec *corev1.PodSpec, values *l5dcharts.Values, namedPorts map[string]int32) []PortFinding {
+	opaque := portSet(values.Proxy.OpaquePorts, namedPorts)
+	skipped := portSet(values.ProxyInit.IgnoreInboundPorts, namedPorts)
+
+	var findings []PortFinding
+	for _, c := range spec.Containers {
+		for _, p := range c.Ports {
+			if p.Protocol != "" && p.Protocol != corev1.ProtocolTCP {
+				continue
+			}
+			protocol := serverSpeaksFirstProtocol(p)
+			if protocol == "" {
+				continue
+			}
+			if _, ok := opaque[uint32(p.ContainerPort)]; ok {
+				continue
+			}
+			if _, ok := skipped[uint32(p.ContainerPort)]; ok {
+				continue
+			}
+			findings = append(findings, PortFinding{
+				Rule:      RuleServerSpeaksFirst,
+				Container: c.Name,
+				Port:      p.ContainerPort,
+				Message: fmt.Sprintf("port %d of container %q looks like %s, a server-speaks-first protocol, but isn't opaque: add it to the \"%s\" annotation",
+					p.ContainerPort, c.Name, protocol, k8s.ProxyOpaquePortsAnnotation),
+			})
+		}
+	}
+	return findings
+}
+
+func serverSpeaksFirstProtocol(p corev1.ContainerPort) string {
+	name := strings.ToLower(p.Name)
+	for _, n := range serverSpeaksFirstNames {
+		if strings.Contains(name, n.keyword) {
+			return n.protocol
+		}
+	}
+	return serverSpeaksFirstPorts[p.ContainerPort]
+}
+
+func checkSkippedProbePorts(spec *corev1.PodSpec, values *l5dcharts.Values, namedPorts map[string]int32) []PortFinding {
+	skipped := portSet(values.ProxyInit.IgnoreInboundPorts, namedPorts)
+
+	var findings []PortFinding
+	for _, c := range spec.Containers {
+		reported := map[int32]struct{}{}
+		for _, probe := range []*corev1.Probe{c.LivenessProbe, c.ReadinessProbe, c.StartupProbe} {
+			port, ok := probePort(probe, namedPorts)
+			if !ok {
+				continue
+			}
+			if _, ok := skipped[uint32(port)]; !ok {
+				continue
+			}
+			if _, ok := reported[port]; ok {
+				continue
+			}
+			reported[port] = struct{}{}
+			findings = append(findings, PortFinding{
+				Rule:      RuleSkippedProbePort,
+				Container: c.Name,
+				Port:      port,
+				Message: fmt.Sprintf("port %d of container %q is probed but listed in the \"%s\" annotation: the probes, and all the other inbound traffic to this port, bypass the proxy",
+					port, c.Name, k8s.ProxyIgnoreInboundPortsAnnotation),
+			})
+		}
+	}
+	return findings
+}
+
+// probePort returns the port targeted by an httpGet, tcpSocket or grpc
+// probe, resolving named ports.
+func probePort(probe *corev1.Probe, namedPorts map[string]int32) (int32, bool) {
+	if probe == nil {
+		return 0, false
+	}
+	var port intstr.IntOrString
+	switch {
+	case probe.HTTPGet != nil:
+		port = probe.HTTPGet.Port
+	case probe.TCPSocket != nil:
+		port = probe.TCPSocket.Port
+	case probe.GRPC != nil:
+		return probe.GRPC.Port, true
+	default:
+		return 0, false
+	}
+	if port.Type == intstr.String {
+		p, ok := namedPorts[port.StrVal]
+		return p, ok
+	}
+	return port.IntVal, true
+}
+
+func checkProxyPortCollisions(spec *corev1.PodSpec, values *l5dcharts.Values, _ map[string]int32) []PortFinding {
+	proxyPorts := map[int32]string{
+		values.Proxy.Ports.Admin:    "admin",
+		values.Proxy.Ports.Control:  "control",
+		values.Proxy.Ports.Inbound:  "inbound",
+		values.Proxy.Ports.Outbound: "outbound",
+	}
+
+	var findings []PortFinding
+	for _, c := range append(spec.InitContainers, spec.Containers...) {
+		if c.Name == k8s.ProxyContainerName || c.Name == k8s.InitContainerName {
+			continue
+		}
+		for _, p := range c.Ports {
+			name, ok := proxyPorts[p.ContainerPort]
+			if !ok || p.ContainerPort == 0 {
+				continue
+			}
+			findings = append(findings, PortFinding{
+				Rule:      RuleProxyPortCollision,
+				Container: c.Name,
+				Port:      p.ContainerPort,
+				Message: fmt.Sprintf("port %d of container %q collides with the proxy's %s port",
+					p.ContainerPort, c.Name, name),
+			})
+		}
+	}
+	return findings
+}
+
+func portSet(ports string, namedPorts map[string]int32) map[uint32]struct{} {
+	set := map[uint32]struct{}{}
+	if ports == "" {
+		return set
+	}
+	for _, pr := range util.ParseContainerOpaquePorts(ports, namedPorts) {
+		for _, p := range pr.Ports() {
+			set[uint32(p)] = struct{}{}
+		}
+	}
+	return set
+}
@@ -0,0 +1,125 @@
+package inject
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	l5dcharts "github.com/linkerd/linkerd2/pkg/charts/linkerd2"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestAnalyzePorts(t *testing.T) {
+	values, err := l5dcharts.NewValues()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	testCases := []struct {
+		desc        string
+		annotations map[string]string
+		containers  []corev1.Container
+		expected    []string
+	}{
+		{
+			desc: "well-known opaque port",
+			containers: []corev1.Container{
+				{Name: "db", Ports: []corev1.ContainerPort{{Name: "mysql", ContainerPort: 3306}}},
+			},
+		},
+		{
+			desc: "server-speaks-first port by name",
+			containers: []corev1.Container{
+				{Name: "db", Ports: []corev1.ContainerPort{{Name: "mysql", ContainerPort: 3307}}},
+			},
+			expected: []string{RuleServerSpeaksFirst},
+		},
+		{
+			desc:        "server-speaks-first port made opaque",
+			annotations: map[string]string{k8s.ProxyOpaquePortsAnnotation: "mysql"},
+			containers: []corev1.Container{
+				{Name: "db", Ports: []corev1.ContainerPort{{Name: "mysql", ContainerPort: 3307}}},
+			},
+		},
+		{
+			desc:        "server-speaks-first port no longer opaque",
+			annotations: map[string]string{k8s.ProxyOpaquePortsAnnotation: "8080"},
+			containers: []corev1.Container{
+				{Name: "cache", Ports: []corev1.ContainerPort{{ContainerPort: 6379}}},
+			},
+			expected: []string{RuleServerSpeaksFirst},
+		},
+		{
+			desc:        "server-speaks-first port skipped",
+			annotations: map[string]string{k8s.ProxyOpaquePortsAnnotation: "8080", k8s.ProxyIgnoreInboundPortsAnnotation: "6379"},
+			containers: []corev1.Container{
+				{Name: "cache", Ports: []corev1.ContainerPort{{ContainerPort: 6379}}},
+			},
+		},
+		{
+			desc:        "probed port skipped",
+			annotations: map[string]string{k8s.ProxyIgnoreInboundPortsAnnotation: "8081"},
+			containers: []corev1.Container{
+				{
+					Name:  "app",
+					Ports: []corev1.ContainerPort{{Name: "health", ContainerPort: 8081}},
+					LivenessProbe: &corev1.Probe{ProbeHandler: corev1.ProbeHandler{
+						TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(8081)},
+					}},
+					ReadinessProbe: &corev1.Probe{ProbeHandler: corev1.ProbeHandler{
+						HTTPGet: &corev1.HTTPGetAction{Port: intstr.FromString("health")},
+					}},
+				},
+			},
+			expected: []string{RuleSkippedProbePort},
+		},
+		{
+			desc: "probed port not skipped",
+			containers: []corev1.Container{
+				{
+					Name: "app",
+					ReadinessProbe: &corev1.Probe{ProbeHandler: corev1.ProbeHandler{
+						GRPC: &corev1.GRPCAction{Port: 9090},
+					}},
+				},
+			},
+		},
+		{
+			desc: "proxy port collision",
+			containers: []corev1.Container{
+				{Name: "app", Ports: []corev1.ContainerPort{{ContainerPort: 4191}, {ContainerPort: 4143}}},
+			},
+			expected: []string{RuleProxyPortCollision, RuleProxyPortCollision},
+		},
+		{
+			desc:        "proxy port moved",
+			annotations: map[string]string{k8s.ProxyAdminPortAnnotation: "4192"},
+			containers: []corev1.Container{
+				{Name: "app", Ports: []corev1.ContainerPort{{ContainerPort: 4191}}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			conf := NewResourceConfig(values, OriginWebhook, "linkerd")
+			conf.pod.spec = &corev1.PodSpec{Containers: tc.containers}
+			conf.pod.meta = &metav1.ObjectMeta{Annotations: tc.annotations}
+
+			findings, err := conf.AnalyzePorts(GetOverriddenValues)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			var rules []string
+			for _, f := range findings {
+				rules = append(rules, f.Rule)
+			}
+			if diff := deep.Equal(rules, tc.expected); diff != nil {
+				t.Fatalf("Unexpected findings %+v: %+v", findings, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package inject
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	l5dcharts "github.com/linkerd/linkerd2/pkg/charts/linkerd2"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+)
+
+// Source is the configuration layer an effective proxy setting comes from.
+type Source string
+
+const (
+	// SourceChartDefault is the default value shipped with the linkerd2 chart
+	SourceChartDefault Source = "chart-default"
+
+	// SourceConfig is the value stored in the linkerd-config ConfigMap
+	SourceConfig Source = "linkerd-config"
+
+	// SourceFlag is a value set through `linkerd inject` flags
+	SourceFlag Source = "inject-flag"
+
+	// SourceNamespace is an annotation on the workload's namespace
+	SourceNamespace Source = "namespace-annotation"
+
+	// SourceProxyConfig is a setting of a ProxyConfig resource applying to
+	// the workload
+	SourceProxyConfig Source = "proxy-config"
+
+	// SourceWorkload is an annotation on the pod, or on the workload's pod
+	// template
+	SourceWorkload Source = "workload-annotation"
+)
+
+// Explanation describes where each effective proxy setting of a workload
+// comes from.
+type Explanation struct {
+	Settings []ExplainedSetting  `json:"settings"`
+	Ignored  []IgnoredAnnotation `json:"ignored,omitempty"`
+}
+
+// ExplainedSetting is the effective value of a proxy setting and the layer
+// it comes from. Annotation is set when the value comes from an annotation,
+// and ProxyConfig, as namespace/name, when it comes from a ProxyConfig.
+type ExplainedSetting struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Source      Source `json:"source"`
+	Annotation  string `json:"annotation,omitempty"`
+	ProxyConfig string `json:"proxyConfig,omitempty"`
+}
+
+// IgnoredAnnotation is a config annotation that has no effect on the
+// injected proxy, along with the reason why.
+type IgnoredAnnotation struct {
+	Annotation  string `json:"annotation"`
+	Value       string `json:"value"`
+	Source      Source `json:"source"`
+	ProxyConfig string `json:"proxyConfig,omitempty"`
+	Reason      string `json:"reason"`
+}
+
+type explainedSetting struct {
+	// name is the setting the annotations applied by the injector are
+	// recorded against
+	name string
+	get  func(*l5dcharts.Values) any
+}
+
+var (
+	explainedSettings = []explainedSetting{
+		{name: "proxy.image.name", get: func(v *l5dcharts.Values) any { return v.Proxy.Image.Name }},
+		{name: "proxy.image.version", get: func(v *l5dcharts.Values) any { return v.Proxy.Image.Version }},
+		{name: "proxy.image.pullPolicy", get: func(v *l5dcharts.Values) any { return v.Proxy.Image.PullPolicy }},
+		{name: "proxy.isGateway", get: func(v *l5dcharts.Values) any { return v.Proxy.IsGateway }},
+		{name: "proxy.ports.admin", get: func(v *l5dcharts.Values) any { return v.Proxy.Ports.Admin }},
+		{name: "proxy.ports.control", get: func(v *l5dcharts.Values) any { return v.Proxy.Ports.Control }},
+		{name: "proxy.ports.inbound", get: func(v *l5dcharts.Values) any { return v.Proxy.Ports.Inbound }},
+		{name: "proxy.ports.outbound", get: func(v *l5dcharts.Values) any { return v.Proxy.Ports.Outbound }},
+		{name: "proxy.podInboundPorts", get: func(v *l5dcharts.Values) any { return v.Proxy.PodInboundPorts }},
+		{name: "proxy.opaquePorts", get: func(v *l5dcharts.Values) any { return v.Proxy.OpaquePorts }},
+		{name: "proxy.enableShutdownEndpoint", get: func(v *l5dcharts.Values) any { return v.Proxy.EnableShutdownEndpoint }},
+		{name: "proxy.logLevel", get: func(v *l5dcharts.Values) any { return v.Proxy.LogLevel }},
+		{name: "proxy.logFormat", get: func(v *l5dcharts.Values) any { return v.Proxy.LogFormat }},
+		{name: "proxy.logHTTPHeaders", get: func(v *l5dcharts.Values) any { return v.Proxy.LogHTTPHeaders }},
+		{name: "proxy.accessLog", get: func(v *l5dcharts.Values) any { return v.Proxy.AccessLog }},
+		{name: "proxy.requireIdentityOnInboundPorts", get: func(v *l5dcharts.Values) any { return v.Proxy.RequireIdentityOnInboundPorts }},
+		{name: "proxy.defaultInboundPolicy", get: func(v *l5dcharts.Values) any { return v.Proxy.DefaultInboundPolicy }},
+		{name: "proxy.metrics.hostnameLabels", get: func(v *l5dcharts.Values) any { return v.Proxy.Metrics.HostnameLabels }},
+		{name: "proxy.enableExternalProfiles", get: func(v *l5dcharts.Values) any { return v.Proxy.EnableExternalProfiles }},
+		{name: "proxy.outboundConnectTimeout", get: func(v *l5dcharts.Values) any { return v.Proxy.OutboundConnectTimeout }},
+		{name: "proxy.inboundConnectTimeout", get: func(v *l5dcharts.Values) any { return v.Proxy.InboundConnectTimeout }},
+		{name: "proxy.outboundDiscoveryCacheUnusedTimeout", get: func(v *l5dcharts.Values) any { return v.Proxy.OutboundDiscoveryCacheUnusedTimeout }},
+		{name: "proxy.inboundDiscoveryCacheUnusedTimeout", get: func(v *l5dcharts.Values) any { return v.Proxy.InboundDiscoveryCacheUnusedTimeout }},
+		{name: "proxy.disableOutboundProtocolDetectTimeout", get: func(v *l5dcharts.Values) any { return v.Proxy.DisableOutboundProtocolDetectTimeout }},
+		{name: "proxy.disableInboundProtocolDetectTimeout", get: func(v *l5dcharts.Values) any { return v.Proxy.DisableInboundProtocolDetectTimeout }},
+		{name: "proxy.shutdownGracePeriod", get: func(v *l5dcharts.Values) any { return v.Proxy.ShutdownGracePeriod }},
+		{name: "proxy.waitBeforeExitSeconds", get: func(v *l5dcharts.Values) any { return v.Proxy.WaitBeforeExitSeconds }},
+		{name: "proxy.nativeSidecar", get: func(v *l5dcharts.Values) any { return v.Proxy.NativeSidecar }},
+		{name: "proxy.enableJobSupervisor", get: func(v *l5dcharts.Values) any { return v.Proxy.EnableJobSupervisor }},
+		{name: "proxy.await", get: func(v *l5dcharts.Values) any { return v.Proxy.Await }},
+		{name: "proxy.resources.cpu.request", get: func(v *l5dcharts.Values) any { return v.Proxy.Resources.CPU.Request }},
+		{name: "proxy.resources.cpu.limit", get: func(v *l5dcharts.Values) any { return v.Proxy.Resources.CPU.Limit }},
+		{name: "proxy.runtime.workers.maximumCPURatio", get: func(v *l5dcharts.Values) any { return v.Proxy.Runtime.Workers.MaximumCPURatio }},
+		{name: "proxy.resources.memory.request", get: func(v *l5dcharts.Values) any { return v.Proxy.Resources.Memory.Request }},
+		{name: "proxy.resources.memory.limit", get: func(v *l5dcharts.Values) any { return v.Proxy.Resources.Memory.Limit }},
+		{name: "proxy.resources.ephemeral-storage.request", get: func(v *l5dcharts.Values) any { return v.Proxy.Resources.EphemeralStorage.Request }},
+		{name: "proxy.resources.ephemeral-storage.limit", get: func(v *l5dcharts.Values) any { return v.Proxy.Resources.EphemeralStorage.Limit }},
+		{name: "proxy.uid", get: func(v *l5dcharts.Values) any { return v.Proxy.UID }},
+		{name: "proxy.gid", get: func(v *l5dcharts.Values) any { return v.Proxy.GID }},
+		{name: "proxy.additionalEnv", get: func(v *l5dcharts.Values) any { return envString(v) }},
+		{name: "proxyInit.ignoreInboundPorts", get: func(v *l5dcharts.Values) any { return v.ProxyInit.IgnoreInboundPorts }},
+		{name: "proxyInit.ignoreOutboundPorts", get: func(v *l5dcharts.Values) any { return v.ProxyInit.IgnoreOutboundPorts }},
+		{name: "proxyInit.skipSubnets", get: func(v *l5dcharts.Values) any { return v.ProxyInit.SkipSubnets }},
+		{name: "debugContainer.image.name", get: func(v *l5dcharts.Values) any { return v.DebugContainer.Image.Name }},
+		{name: "debugContainer.image.version", get: func(v *l5dcharts.Values) any { return v.DebugContainer.Image.Version }},
+		{name: "debugContainer.image.pullPolicy", get: func(v *l5dcharts.Values) any { return v.DebugContainer.Image.PullPolicy }},
+	}
+
+	// knownConfigAnnotations are the config annotations that are not proxy
+	// settings but are still understood by the injector
+	knownConfigAnnotations = []string{
+		k8s.ProxyInjectAnnotation,
+		k8s.ProxyEnableDebugAnnotation,
+		k8s.CloseWaitTimeoutAnnotation,
+	}
+)
+
+// Explain returns the effective value of every proxy setting of the
+// workload in conf along with the layer it comes from, and the config
+// annotations that have no effect. The annotations are attributed as the
+// overrider applies them; defaults are the chart's default values and config
+// the values from linkerd-config, and settings where conf's values differ
+// from config are attributed to inject flags. It returns nil for resources
+// without a pod template.
+func (conf *ResourceConfig) Explain(overrider ValueOverrider, defaults, config *l5dcharts.Values) (*Explanation, error) {
+	if !conf.HasPodTemplate() {
+		return nil, nil
+	}
+
+	type layered struct {
+		value       string
+		source      Source
+		proxyConfig string
+	}
+	explanation := &Explanation{}
+	ignore := func(annotation string, ann layered, reason string) {
+		explanation.Ignored = append(explanation.Ignored, IgnoredAnnotation{annotation, ann.value, ann.source, ann.proxyConfig, reason})
+	}
+
+	// Layer the annotations the way the injector does: namespace annotations
+	// are only inherited for proxy annotations the workload doesn't set.
+	workloadAnn := conf.pod.meta.Annotations
+	inherited := map[string]struct{}{k8s.ProxyInjectAnnotation: {}}
+	for _, ann := range append(ProxyAnnotations, ProxyAlphaConfigAnnotations...) {
+		inherited[ann] = struct{}{}
+	}
+	namespace := map[string]layered{}
+	annotations := map[string]layered{}
+	for k, v := range conf.nsAnnotations {
+		if !isConfigAnnotation(k) {
+			continue
+		}
+		ann := layered{value: v, source: SourceNamespace}
+		if pc, ok := conf.nsAnnotationSources[k]; ok {
+			ann = layered{value: v, source: SourceProxyConfig, proxyConfig: pc}
+		}
+		if _, ok := inherited[k]; !ok {
+			ignore(k, ann, "not inherited from the namespace")
+			continue
+		}
+		namespace[k] = ann
+		// proxy-additional-env values are merged rather than replaced
+		if _, ok := workloadAnn[k]; ok && k != k8s.ProxyAdditionalEnvAnnotation {
+			ignore(k, ann, "overridden by workload annotation")
+			continue
+		}
+		annotations[k] = ann
+	}
+	for k, v := range workloadAnn {
+		if isConfigAnnotation(k) {
+			annotations[k] = layered{value: v, source: SourceWorkload}
+		}
+	}
+
+	type applied struct {
+		layered
+		annotation string
+	}
+	settings := map[string]applied{}
+	read := map[string]struct{}{}
+	conf.recordOverride = func(setting, annotation, value string, err error) {
+		ann, ok := annotations[annotation]
+		if !ok {
+			return
+		}
+		// both the namespace and the workload values of merged annotations
+		// are applied
+		if ns, ok := namespace[annotation]; ok && ann.value != value && ns.value == value {
+			ann = ns
+		}
+		read[annotation] = struct{}{}
+		switch err.(type) {
+		case nil:
+			settings[setting] = applied{ann, annotation}
+		case supersededError:
+			ignore(annotation, ann, err.Error())
+		default:
+			ignore(annotation, ann, fmt.Sprintf("invalid value: %s", err))
+		}
+	}
+	effective, err := overrider(conf)
+	conf.recordOverride = nil
+	if err != nil {
+		return nil, err
+	}
+
+	for k, ann := range annotations {
+		if _, ok := read[k]; !ok && !slices.Contains(knownConfigAnnotations, k) {
+			ignore(k, ann, "unknown annotation")
+		}
+	}
+
+	for _, s := range explainedSettings {
+		setting := ExplainedSetting{
+			Name:  s.name,
+			Value: fmt.Sprint(s.get(effective)),
+		}
+
+		if a, ok := settings[s.name]; ok {
+			setting.Source = a.source
+			setting.Annotation = a.annotation
+			setting.ProxyConfig = a.proxyConfig
+		} else {
+			switch value := fmt.Sprint(s.get(conf.values)); {
+			case value != fmt.Sprint(s.get(config)):
+				setting.Source = SourceFlag
+			case value != fmt.Sprint(s.get(defaults)):
+				setting.Source = SourceConfig
+			default:
+				setting.Source = SourceChartDefault
+			}
+		}
+		explanation.Settings = append(explanation.Settings, setting)
+	}
+
+	sort.Slice(explanation.Ignored, func(i, j int) bool {
+		if explanation.Ignored[i].Annotation != explanation.Ignored[j].Annotation {
+			return explanation.Ignored[i].Annotation < explanation.Ignored[j].Annotation
+		}
+		return explanation.Ignored[i].Source < explanation.Ignored[j].Source
+	})
+	return explanation, nil
+}
+
+func isConfigAnnotation(key string) bool {
+	return key == k8s.ProxyInjectAnnotation ||
+		strings.HasPrefix(key, k8s.ProxyConfigAnnotationsPrefix+"/") ||
+		strings.HasPrefix(key, k8s.ProxyConfigAnnotationsPrefixAlpha+"/") ||
+		strings.HasPrefix(key, k8s.ProxyConfigAnnotationsPrefixBeta+"/")
+}
+
+func envString(v *l5dcharts.Values) string {
+	if len(v.Proxy.AdditionalEnv) == 0 {
+		return ""
+	}
+	env, err := json.Marshal(v.Proxy.AdditionalEnv)
+	if err != nil {
+		return fmt.Sprint(v.Proxy.AdditionalEnv)
+	}
+	return string(env)
+}
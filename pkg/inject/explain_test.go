@@ -0,0 +1,134 @@
+package inject
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/go-test/deep"
+	l5dcharts "github.com/linkerd/linkerd2/pkg/charts/linkerd2"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+)
+
+func TestExplain(t *testing.T) {
+	defaults, err := l5dcharts.NewValues()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	config, err := defaults.DeepCopy()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	config.Proxy.LogFormat = "json"
+	values, err := config.DeepCopy()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	values.Proxy.Resources.Memory.Limit = "250Mi"
+
+	pod := []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  namespace: emojivoto
+  annotations:
+    linkerd.io/inject: enabled
+    config.linkerd.io/proxy-cpu-request: 10m
+    config.linkerd.io/proxy-log-level: debug
+    config.linkerd.io/admin-port: admin
+    config.linkerd.io/proxy-log-levl: trace
+    config.linkerd.io/proxy-enable-native-sidecar: "true"
+    config.alpha.linkerd.io/proxy-enable-native-sidecar: "false"
+spec:
+  containers:
+  - name: web
+    image: buoyantio/emojivoto-web:v11
+`)
+	nsAnnotations := map[string]string{
+		k8s.ProxyLogLevelAnnotation:      "info",
+		k8s.ProxyCPULimitAnnotation:      "1",
+		k8s.ProxyMemoryRequestAnnotation: "64Mi",
+		k8s.ProxyAwait:                   "maybe",
+		k8s.DebugImageAnnotation:         "debug",
+		k8s.ProxyConfigAnnotationsPrefix: "ignored",
+	}
+
+	// Settings of ProxyConfigs are resolved into the namespace annotations
+	nsAnnotationSources := map[string]string{
+		k8s.ProxyLogLevelAnnotation:      "emojivoto/web",
+		k8s.ProxyMemoryRequestAnnotation: "emojivoto/web",
+	}
+
+	conf := NewResourceConfig(values, OriginWebhook, "linkerd").
+		WithNsAnnotations(nsAnnotations).
+		WithNsAnnotationSources(nsAnnotationSources)
+	if _, err := conf.ParseMetaAndYAML(pod); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	AppendNamespaceAnnotations(conf.GetOverrideAnnotations(), conf.GetNsAnnotations(), conf.GetWorkloadAnnotations())
+
+	explanation, err := conf.Explain(GetOverriddenValues, defaults, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	settings := map[string]ExplainedSetting{}
+	for _, s := range explanation.Settings {
+		settings[s.Name] = s
+	}
+	expectedSettings := []ExplainedSetting{
+		{Name: "proxy.resources.cpu.request", Value: "10m", Source: SourceWorkload, Annotation: k8s.ProxyCPURequestAnnotation},
+		{Name: "proxy.resources.cpu.limit", Value: "1", Source: SourceNamespace, Annotation: k8s.ProxyCPULimitAnnotation},
+		{Name: "proxy.logLevel", Value: "debug", Source: SourceWorkload, Annotation: k8s.ProxyLogLevelAnnotation},
+		{Name: "proxy.resources.memory.request", Value: "64Mi", Source: SourceProxyConfig, Annotation: k8s.ProxyMemoryRequestAnnotation, ProxyConfig: "emojivoto/web"},
+		{Name: "proxy.logFormat", Value: "json", Source: SourceConfig},
+		{Name: "proxy.resources.memory.limit", Value: "250Mi", Source: SourceFlag},
+		{Name: "proxy.ports.admin", Value: "4191", Source: SourceChartDefault},
+		{Name: "proxy.await", Value: "true", Source: SourceChartDefault},
+		{Name: "proxy.nativeSidecar", Value: "true", Source: SourceWorkload, Annotation: k8s.ProxyEnableNativeSidecarAnnotation},
+	}
+	for _, expected := range expectedSettings {
+		if diff := deep.Equal(settings[expected.Name], expected); diff != nil {
+			t.Errorf("%s: %+v", expected.Name, diff)
+		}
+	}
+
+	expectedIgnored := []IgnoredAnnotation{
+		{Annotation: k8s.ProxyEnableNativeSidecarAnnotationAlpha, Value: "false", Source: SourceWorkload, Reason: "superseded by " + k8s.ProxyEnableNativeSidecarAnnotation},
+		{Annotation: k8s.ProxyAdminPortAnnotation, Value: "admin", Source: SourceWorkload, Reason: `invalid value: strconv.ParseInt: parsing "admin": invalid syntax`},
+		{Annotation: k8s.DebugImageAnnotation, Value: "debug", Source: SourceNamespace, Reason: "not inherited from the namespace"},
+		{Annotation: k8s.ProxyAwait, Value: "maybe", Source: SourceNamespace, Reason: "invalid value: valid values are: [enabled, disabled]"},
+		{Annotation: k8s.ProxyLogLevelAnnotation, Value: "info", Source: SourceProxyConfig, ProxyConfig: "emojivoto/web", Reason: "overridden by workload annotation"},
+		{Annotation: "config.linkerd.io/proxy-log-levl", Value: "trace", Source: SourceWorkload, Reason: "unknown annotation"},
+	}
+	if diff := deep.Equal(explanation.Ignored, expectedIgnored); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+}
+
+func TestExplainedSettingsCoverOverrides(t *testing.T) {
+	values, err := l5dcharts.NewValues()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	annotations := map[string]string{
+		k8s.ProxyEnableGatewayAnnotation:           "true",
+		k8s.ProxyCPURatioLimitAnnotation:           "0.5",
+		k8s.ProxyEnableNativeSidecarAnnotationBeta: "true",
+		k8s.DebugImageAnnotation:                   "debug",
+		k8s.DebugImageVersionAnnotation:            "v1",
+		k8s.DebugImagePullPolicyAnnotation:         "Always",
+	}
+	for _, ann := range append(ProxyAnnotations, ProxyAlphaConfigAnnotations...) {
+		annotations[ann] = "1"
+	}
+
+	record := func(setting, annotation, _ string, _ error) {
+		if !slices.ContainsFunc(explainedSettings, func(s explainedSetting) bool { return s.name == setting }) {
+			t.Errorf("%s is recorded for unexplained setting %s", annotation, setting)
+		}
+	}
+	applyAnnotationOverrides(values, annotations, nil, nil, record)
+	mergeAdditionalEnv(values, nil, annotations, record)
+}
@@ -80,6 +80,7 @@ var (
 		k8s.ProxyDisableOutboundProtocolDetectTimeout,
 		k8s.ProxyDisableInboundProtocolDetectTimeout,
 		k8s.ProxyEnableNativeSidecarAnnotation,
+		k8s.ProxyEnableJobSupervisorAnnotation,
 		k8s.ProxyAdditionalEnvAnnotation,
 	}
 	// ProxyAlphaConfigAnnotations is the list of all alpha configuration
@@ -128,9 +129,16 @@ type ResourceConfig struct {
 	// These annotations from the resources's namespace are used as a base.
 	// The resources's annotations will be applied on top of these, which
 	// allows the nsAnnotations to act as a default.
-	nsAnnotations  map[string]string
-	ownerRetriever OwnerRetrieverFunc
-	origin         Origin
+	nsAnnotations map[string]string
+	// nsAnnotationSources maps the nsAnnotations coming from ProxyConfig
+	// resources to the namespace/name of the ProxyConfig that set them
+	nsAnnotationSources map[string]string
+	ownerRetriever      OwnerRetrieverFunc
+	origin              Origin
+
+	// recordOverride, when set, is told about the annotations applied by
+	// GetOverriddenValues, to report the provenance of the proxy settings
+	recordOverride overrideRecorder
 
 	workload struct {
 		obj      runtime.Object
@@ -164,6 +172,7 @@ type podPatch struct {
 	AddRootVolumes        bool                      `json:"addRootVolumes"`
 	Labels                map[string]string         `json:"labels"`
 	DebugContainer        *l5dcharts.DebugContainer `json:"debugContainer"`
+	ProxySupervisor       bool                      `json:"proxySupervisor"`
 }
 
 type annotationPatch struct {
@@ -202,8 +211,8 @@ func GetOverriddenValues(rc *ResourceConfig) (*l5dcharts.Values, error) {
 		namedPorts = util.GetNamedPorts(append(rc.pod.spec.InitContainers, rc.pod.spec.Containers...))
 	}
 
-	ApplyAnnotationOverrides(copyValues, rc.GetAnnotationOverrides(), rc.GetLabelOverrides(), namedPorts)
-	MergeAdditionalEnv(copyValues, rc.GetNsAnnotations(), rc.GetWorkloadAnnotations())
+	applyAnnotationOverrides(copyValues, rc.GetAnnotationOverrides(), rc.GetLabelOverrides(), namedPorts, rc.recordOverride)
+	mergeAdditionalEnv(copyValues, rc.GetNsAnnotations(), rc.GetWorkloadAnnotations(), rc.recordOverride)
 	return copyValues, nil
 }
 
@@ -243,6 +252,14 @@ func mergeEnvByName(dst, src []corev1.EnvVar) []corev1.EnvVar {
 // 3. Workload-level proxy-additional-env annotation
 // Higher-precedence layers override entries with the same env var name.
 func MergeAdditionalEnv(values *l5dcharts.Values, nsAnnotations map[string]string, workloadAnnotations map[string]string) {
+	mergeAdditionalEnv(values, nsAnnotations, workloadAnnotations, nil)
+}
+
+func mergeAdditionalEnv(values *l5dcharts.Values, nsAnnotations map[string]string, workloadAnnotations map[string]string, record overrideRecorder) {
+	if record == nil {
+		record = func(string, string, string, error) {}
+	}
+
 	nsEnvStr := nsAnnotations[k8s.ProxyAdditionalEnvAnnotation]
 	if nsEnvStr != "" {
 		nsEnv, err := parseAdditionalEnvAnnotation(nsEnvStr)
@@ -251,6 +268,7 @@ func MergeAdditionalEnv(values *l5dcharts.Values, nsAnnotations map[string]strin
 		} else {
 			values.Proxy.AdditionalEnv = mergeEnvByName(values.Proxy.AdditionalEnv, nsEnv)
 		}
+		record("proxy.additionalEnv", k8s.ProxyAdditionalEnvAnnotation, nsEnvStr, err)
 	}
 
 	wlEnvStr := workloadAnnotations[k8s.ProxyAdditionalEnvAnnotation]
@@ -261,10 +279,34 @@ func MergeAdditionalEnv(values *l5dcharts.Values, nsAnnotations map[string]strin
 		} else {
 			values.Proxy.AdditionalEnv = mergeEnvByName(values.Proxy.AdditionalEnv, wlEnv)
 		}
+		record("proxy.additionalEnv", k8s.ProxyAdditionalEnvAnnotation, wlEnvStr, err)
 	}
 }
 
+// overrideRecorder is told about every annotation read when overriding the
+// values: the setting it applies to, its value and, when it was ignored, the
+// reason why
+type overrideRecorder func(setting, annotation, value string, err error)
+
+// supersededError is recorded for an annotation shadowed by a higher
+// precedence annotation for the same setting
+type supersededError struct {
+	by string
+}
+
+func (e supersededError) Error() string {
+	return fmt.Sprintf("superseded by %s", e.by)
+}
+
 func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]string, labels map[string]string, namedPorts map[string]int32) {
+	applyAnnotationOverrides(values, annotations, labels, namedPorts, nil)
+}
+
+func applyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]string, labels map[string]string, namedPorts map[string]int32, record overrideRecorder) {
+	if record == nil {
+		record = func(string, string, string, error) {}
+	}
+
 	if override, ok := annotations[k8s.ProxyInjectAnnotation]; ok {
 		if override == k8s.ProxyInjectIngress {
 			values.Proxy.IsIngress = true
@@ -273,14 +315,17 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 
 	if override, ok := annotations[k8s.ProxyImageAnnotation]; ok {
 		values.Proxy.Image.Name = override
+		record("proxy.image.name", k8s.ProxyImageAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.ProxyVersionOverrideAnnotation]; ok {
 		values.Proxy.Image.Version = override
+		record("proxy.image.version", k8s.ProxyVersionOverrideAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.ProxyImagePullPolicyAnnotation]; ok {
 		values.Proxy.Image.PullPolicy = override
+		record("proxy.image.pullPolicy", k8s.ProxyImagePullPolicyAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.ProxyControlPortAnnotation]; ok {
@@ -288,6 +333,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		if err == nil {
 			values.Proxy.Ports.Control = int32(controlPort)
 		}
+		record("proxy.ports.control", k8s.ProxyControlPortAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyInboundPortAnnotation]; ok {
@@ -295,6 +341,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		if err == nil {
 			values.Proxy.Ports.Inbound = int32(inboundPort)
 		}
+		record("proxy.ports.inbound", k8s.ProxyInboundPortAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyAdminPortAnnotation]; ok {
@@ -302,6 +349,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		if err == nil {
 			values.Proxy.Ports.Admin = int32(adminPort)
 		}
+		record("proxy.ports.admin", k8s.ProxyAdminPortAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyOutboundPortAnnotation]; ok {
@@ -309,34 +357,42 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		if err == nil {
 			values.Proxy.Ports.Outbound = int32(outboundPort)
 		}
+		record("proxy.ports.outbound", k8s.ProxyOutboundPortAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyPodInboundPortsAnnotation]; ok {
 		values.Proxy.PodInboundPorts = override
+		record("proxy.podInboundPorts", k8s.ProxyPodInboundPortsAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.ProxyAdminShutdownAnnotation]; ok {
 		if override == k8s.Enabled || override == k8s.Disabled {
 			values.Proxy.EnableShutdownEndpoint = override == k8s.Enabled
+			record("proxy.enableShutdownEndpoint", k8s.ProxyAdminShutdownAnnotation, override, nil)
 		} else {
 			log.Warnf("unrecognized value used for the %s annotation, valid values are: [%s, %s]", k8s.ProxyAdminShutdownAnnotation, k8s.Enabled, k8s.Disabled)
+			record("proxy.enableShutdownEndpoint", k8s.ProxyAdminShutdownAnnotation, override, fmt.Errorf("valid values are: [%s, %s]", k8s.Enabled, k8s.Disabled))
 		}
 	}
 
 	if override, ok := annotations[k8s.ProxyLogLevelAnnotation]; ok {
 		values.Proxy.LogLevel = override
+		record("proxy.logLevel", k8s.ProxyLogLevelAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.ProxyLogHTTPHeaders]; ok {
 		values.Proxy.LogHTTPHeaders = override
+		record("proxy.logHTTPHeaders", k8s.ProxyLogHTTPHeaders, override, nil)
 	}
 
 	if override, ok := annotations[k8s.ProxyLogFormatAnnotation]; ok {
 		values.Proxy.LogFormat = override
+		record("proxy.logFormat", k8s.ProxyLogFormatAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.ProxyRequireIdentityOnInboundPortsAnnotation]; ok {
 		values.Proxy.RequireIdentityOnInboundPorts = override
+		record("proxy.requireIdentityOnInboundPorts", k8s.ProxyRequireIdentityOnInboundPortsAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.ProxyEnableHostnameLabels]; ok {
@@ -344,6 +400,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		if err == nil {
 			values.Proxy.Metrics.HostnameLabels = value
 		}
+		record("proxy.metrics.hostnameLabels", k8s.ProxyEnableHostnameLabels, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyOutboundConnectTimeout]; ok {
@@ -353,6 +410,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		} else {
 			values.Proxy.OutboundConnectTimeout = fmt.Sprintf("%dms", int(duration.Seconds()*1000))
 		}
+		record("proxy.outboundConnectTimeout", k8s.ProxyOutboundConnectTimeout, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyInboundConnectTimeout]; ok {
@@ -362,6 +420,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		} else {
 			values.Proxy.InboundConnectTimeout = fmt.Sprintf("%dms", int(duration.Seconds()*1000))
 		}
+		record("proxy.inboundConnectTimeout", k8s.ProxyInboundConnectTimeout, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyOutboundDiscoveryCacheUnusedTimeout]; ok {
@@ -371,6 +430,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		} else {
 			values.Proxy.OutboundDiscoveryCacheUnusedTimeout = fmt.Sprintf("%ds", int(duration.Seconds()))
 		}
+		record("proxy.outboundDiscoveryCacheUnusedTimeout", k8s.ProxyOutboundDiscoveryCacheUnusedTimeout, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyInboundDiscoveryCacheUnusedTimeout]; ok {
@@ -380,6 +440,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		} else {
 			values.Proxy.InboundDiscoveryCacheUnusedTimeout = fmt.Sprintf("%ds", int(duration.Seconds()))
 		}
+		record("proxy.inboundDiscoveryCacheUnusedTimeout", k8s.ProxyInboundDiscoveryCacheUnusedTimeout, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyDisableOutboundProtocolDetectTimeout]; ok {
@@ -389,6 +450,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		} else {
 			log.Warnf("unrecognised value used on pod annotation %s: %s", k8s.ProxyDisableOutboundProtocolDetectTimeout, err.Error())
 		}
+		record("proxy.disableOutboundProtocolDetectTimeout", k8s.ProxyDisableOutboundProtocolDetectTimeout, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyDisableInboundProtocolDetectTimeout]; ok {
@@ -398,6 +460,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		} else {
 			log.Warnf("unrecognised value used on pod annotation %s: %s", k8s.ProxyDisableInboundProtocolDetectTimeout, err.Error())
 		}
+		record("proxy.disableInboundProtocolDetectTimeout", k8s.ProxyDisableInboundProtocolDetectTimeout, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyShutdownGracePeriodAnnotation]; ok {
@@ -407,6 +470,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		} else {
 			values.Proxy.ShutdownGracePeriod = fmt.Sprintf("%dms", int(duration.Seconds()*1000))
 		}
+		record("proxy.shutdownGracePeriod", k8s.ProxyShutdownGracePeriodAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyEnableGatewayAnnotation]; ok {
@@ -414,6 +478,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		if err == nil {
 			values.Proxy.IsGateway = value
 		}
+		record("proxy.isGateway", k8s.ProxyEnableGatewayAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyWaitBeforeExitSecondsAnnotation]; ok {
@@ -424,24 +489,34 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		} else {
 			values.Proxy.WaitBeforeExitSeconds = waitBeforeExitSeconds
 		}
+		record("proxy.waitBeforeExitSeconds", k8s.ProxyWaitBeforeExitSecondsAnnotation, override, err)
 	}
 
 	// ProxyEnableNativeSidecarAnnotation should take precedence over ProxyEnableNativeSidecarAnnotationAlpha and ProxyEnableNativeSidecarAnnotationBeta
-	if override, ok := annotations[k8s.ProxyEnableNativeSidecarAnnotation]; ok {
-		value, err := strconv.ParseBool(override)
-		if err == nil {
-			values.Proxy.NativeSidecar = value
+	nativeSidecar := ""
+	for _, key := range []string{k8s.ProxyEnableNativeSidecarAnnotation, k8s.ProxyEnableNativeSidecarAnnotationBeta, k8s.ProxyEnableNativeSidecarAnnotationAlpha} {
+		override, ok := annotations[key]
+		if !ok {
+			continue
+		}
+		if nativeSidecar != "" {
+			record("proxy.nativeSidecar", key, override, supersededError{nativeSidecar})
+			continue
 		}
-	} else if override, ok := annotations[k8s.ProxyEnableNativeSidecarAnnotationBeta]; ok {
+		nativeSidecar = key
 		value, err := strconv.ParseBool(override)
 		if err == nil {
 			values.Proxy.NativeSidecar = value
 		}
-	} else if override, ok = annotations[k8s.ProxyEnableNativeSidecarAnnotationAlpha]; ok {
+		record("proxy.nativeSidecar", key, override, err)
+	}
+
+	if override, ok := annotations[k8s.ProxyEnableJobSupervisorAnnotation]; ok {
 		value, err := strconv.ParseBool(override)
 		if err == nil {
-			values.Proxy.NativeSidecar = value
+			values.Proxy.EnableJobSupervisor = value
 		}
+		record("proxy.enableJobSupervisor", k8s.ProxyEnableJobSupervisorAnnotation, override, err)
 	}
 
 	// Proxy CPU resources
@@ -459,6 +534,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 			}
 			values.Proxy.Runtime.Workers.Minimum = n
 		}
+		record("proxy.resources.cpu.request", k8s.ProxyCPURequestAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyCPULimitAnnotation]; ok {
@@ -474,6 +550,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 			}
 			values.Proxy.Runtime.Workers.Maximum = n
 		}
+		record("proxy.resources.cpu.limit", k8s.ProxyCPULimitAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyCPURatioLimitAnnotation]; ok {
@@ -483,9 +560,11 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		} else if (ratio <= 0.0) || (ratio >= 1.0) {
 			log.Warnf("invalid value used for the %s annotation, valid values are between 0.0 and 1.0",
 				k8s.ProxyCPURatioLimitAnnotation)
+			err = errors.New("valid values are between 0.0 and 1.0")
 		} else {
 			values.Proxy.Runtime.Workers.MaximumCPURatio = ratio
 		}
+		record("proxy.runtime.workers.maximumCPURatio", k8s.ProxyCPURatioLimitAnnotation, override, err)
 	}
 
 	// Proxy memory resources
@@ -497,6 +576,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		} else {
 			values.Proxy.Resources.Memory.Request = override
 		}
+		record("proxy.resources.memory.request", k8s.ProxyMemoryRequestAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyMemoryLimitAnnotation]; ok {
@@ -506,6 +586,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		} else {
 			values.Proxy.Resources.Memory.Limit = override
 		}
+		record("proxy.resources.memory.limit", k8s.ProxyMemoryLimitAnnotation, override, err)
 	}
 
 	// Proxy ephemeral storage resources
@@ -517,6 +598,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		} else {
 			values.Proxy.Resources.EphemeralStorage.Request = override
 		}
+		record("proxy.resources.ephemeral-storage.request", k8s.ProxyEphemeralStorageRequestAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyEphemeralStorageLimitAnnotation]; ok {
@@ -526,6 +608,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		} else {
 			values.Proxy.Resources.EphemeralStorage.Limit = override
 		}
+		record("proxy.resources.ephemeral-storage.limit", k8s.ProxyEphemeralStorageLimitAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyUIDAnnotation]; ok {
@@ -533,6 +616,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		if err == nil {
 			values.Proxy.UID = v
 		}
+		record("proxy.uid", k8s.ProxyUIDAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyGIDAnnotation]; ok {
@@ -540,6 +624,7 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		if err == nil {
 			values.Proxy.GID = v
 		}
+		record("proxy.gid", k8s.ProxyGIDAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyEnableExternalProfilesAnnotation]; ok {
@@ -547,14 +632,17 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		if err == nil {
 			values.Proxy.EnableExternalProfiles = value
 		}
+		record("proxy.enableExternalProfiles", k8s.ProxyEnableExternalProfilesAnnotation, override, err)
 	}
 
 	if override, ok := annotations[k8s.ProxyIgnoreInboundPortsAnnotation]; ok {
 		values.ProxyInit.IgnoreInboundPorts = override
+		record("proxyInit.ignoreInboundPorts", k8s.ProxyIgnoreInboundPortsAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.ProxyIgnoreOutboundPortsAnnotation]; ok {
 		values.ProxyInit.IgnoreOutboundPorts = override
+		record("proxyInit.ignoreOutboundPorts", k8s.ProxyIgnoreOutboundPortsAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.ProxyOpaquePortsAnnotation]; ok {
@@ -567,42 +655,52 @@ func ApplyAnnotationOverrides(values *l5dcharts.Values, annotations map[string]s
 		}
 
 		values.Proxy.OpaquePorts = opaquePorts.String()
+		record("proxy.opaquePorts", k8s.ProxyOpaquePortsAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.DebugImageAnnotation]; ok {
 		values.DebugContainer.Image.Name = override
+		record("debugContainer.image.name", k8s.DebugImageAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.DebugImageVersionAnnotation]; ok {
 		values.DebugContainer.Image.Version = override
+		record("debugContainer.image.version", k8s.DebugImageVersionAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.DebugImagePullPolicyAnnotation]; ok {
 		values.DebugContainer.Image.PullPolicy = override
+		record("debugContainer.image.pullPolicy", k8s.DebugImagePullPolicyAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.ProxyAwait]; ok {
 		if override == k8s.Enabled || override == k8s.Disabled {
 			values.Proxy.Await = override == k8s.Enabled
+			record("proxy.await", k8s.ProxyAwait, override, nil)
 		} else {
 			log.Warnf("unrecognized value used for the %s annotation, valid values are: [%s, %s]", k8s.ProxyAwait, k8s.Enabled, k8s.Disabled)
+			record("proxy.await", k8s.ProxyAwait, override, fmt.Errorf("valid values are: [%s, %s]", k8s.Enabled, k8s.Disabled))
 		}
 	}
 
 	if override, ok := annotations[k8s.ProxyDefaultInboundPolicyAnnotation]; ok {
 		if override != k8s.AllUnauthenticated && override != k8s.AllAuthenticated && override != k8s.ClusterUnauthenticated && override != k8s.ClusterAuthenticated && override != k8s.Deny && override != k8s.Audit {
 			log.Warnf("unrecognized value used for the %s annotation, valid values are: [%s, %s, %s, %s, %s, %s]", k8s.ProxyDefaultInboundPolicyAnnotation, k8s.AllUnauthenticated, k8s.AllAuthenticated, k8s.ClusterUnauthenticated, k8s.ClusterAuthenticated, k8s.Deny, k8s.Audit)
+			record("proxy.defaultInboundPolicy", k8s.ProxyDefaultInboundPolicyAnnotation, override, fmt.Errorf("valid values are: [%s, %s, %s, %s, %s, %s]", k8s.AllUnauthenticated, k8s.AllAuthenticated, k8s.ClusterUnauthenticated, k8s.ClusterAuthenticated, k8s.Deny, k8s.Audit))
 		} else {
 			values.Proxy.DefaultInboundPolicy = override
+			record("proxy.defaultInboundPolicy", k8s.ProxyDefaultInboundPolicyAnnotation, override, nil)
 		}
 	}
 
 	if override, ok := annotations[k8s.ProxySkipSubnetsAnnotation]; ok {
 		values.ProxyInit.SkipSubnets = override
+		record("proxyInit.skipSubnets", k8s.ProxySkipSubnetsAnnotation, override, nil)
 	}
 
 	if override, ok := annotations[k8s.ProxyAccessLogAnnotation]; ok {
 		values.Proxy.AccessLog = override
+		record("proxy.accessLog", k8s.ProxyAccessLogAnnotation, override, nil)
 	}
 
 	if values.Proxy.Tracing != nil {
@@ -655,6 +753,14 @@ func (conf *ResourceConfig) WithNsAnnotations(m map[string]string) *ResourceConf
 	return conf
 }
 
+// WithNsAnnotationSources records which of the namespace annotations come
+// from ProxyConfig resources, as a map from the annotation to the ProxyConfig's
+// namespace/name, to report their provenance
+func (conf *ResourceConfig) WithNsAnnotationSources(m map[string]string) *ResourceConfig {
+	conf.nsAnnotationSources = m
+	return conf
+}
+
 // WithOwnerRetriever enriches ResourceConfig with a function that allows to retrieve
 // the kind and name of the workload's owner reference
 func (conf *ResourceConfig) WithOwnerRetriever(f OwnerRetrieverFunc) *ResourceConfig {
@@ -683,6 +789,15 @@ func (conf *ResourceConfig) GetWorkloadAnnotations() map[string]string {
 	return conf.workload.Meta.Annotations
 }
 
+// GetPodLabels returns the labels of the pod, or of the workload's pod
+// template, before injection.
+func (conf *ResourceConfig) GetPodLabels() map[string]string {
+	if conf.pod.meta == nil {
+		return nil
+	}
+	return conf.pod.meta.Labels
+}
+
 // AppendPodAnnotations appends the given annotations to the pod spec in conf
 func (conf *ResourceConfig) AppendPodAnnotations(annotations map[string]string) {
 	for annotation, value := range annotations {
@@ -795,7 +910,9 @@ func (conf *ResourceConfig) GetPodPatch(injectProxy bool, overrider ValueOverrid
 		patch.PathPrefix = "/spec/template"
 	}
 
+	var before podSnapshot
 	if conf.pod.spec != nil {
+		before = conf.snapshotPod()
 		conf.injectPodAnnotations(patch)
 		if injectProxy {
 			conf.injectObjectMeta(patch)
@@ -833,6 +950,12 @@ func (conf *ResourceConfig) GetPodPatch(injectProxy bool, overrider ValueOverrid
 	// Get rid of invalid trailing commas
 	res := rTrail.ReplaceAll(buf.Bytes(), []byte("}\n]"))
 
+	// Record what the proxy injection adds, so that it can be uninjected
+	// exactly
+	if conf.pod.spec != nil && injectProxy {
+		return appendInjectionManifest(res, patch.PathPrefix, before)
+	}
+
 	return res, nil
 }
 
@@ -1233,6 +1356,12 @@ func (conf *ResourceConfig) injectPodSpec(values *podPatch) {
 		}
 	}
 
+	if conf.needsProxySupervisor(&values.Values) && conf.processNamespaceShareable() {
+		log.Infof("inject proxy supervisor")
+		values.ProxySupervisor = true
+		values.Proxy.EnableShutdownEndpoint = true
+	}
+
 	conf.injectProxyInit(values)
 	values.AddRootVolumes = len(conf.pod.spec.Volumes) == 0
 }
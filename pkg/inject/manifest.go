@@ -0,0 +1,242 @@
+package inject
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// InjectionManifest records what injection added to a pod, so that Uninject
+// can restore its original spec exactly, keeping the annotations, labels,
+// volumes and containers that were set before injection. It's stored JSON
+// encoded in the k8s.ProxyInjectionManifestAnnotation annotation.
+type InjectionManifest struct {
+	// Annotations and Labels map the keys set by injection to the value they
+	// had before, or nil if they were absent
+	Annotations map[string]*string `json:"annotations,omitempty"`
+	Labels      map[string]*string `json:"labels,omitempty"`
+
+	// Volumes, InitContainers and Containers are the names of those added
+	Volumes        []string `json:"volumes,omitempty"`
+	InitContainers []string `json:"initContainers,omitempty"`
+	Containers     []string `json:"containers,omitempty"`
+
+	// ShareProcessNamespace is set when injection set the pod's
+	// shareProcessNamespace, to its previous value, or "" if it was unset
+	ShareProcessNamespace *string `json:"shareProcessNamespace,omitempty"`
+}
+
+// podSnapshot is the part of a pod spec that injection may overwrite, as it
+// was before injection.
+type podSnapshot struct {
+	annotations           map[string]string
+	labels                map[string]string
+	shareProcessNamespace *bool
+}
+
+func (conf *ResourceConfig) snapshotPod() podSnapshot {
+	s := podSnapshot{
+		annotations: map[string]string{},
+		labels:      map[string]string{},
+	}
+	for k, v := range conf.pod.meta.Annotations {
+		s.annotations[k] = v
+	}
+	for k, v := range conf.pod.meta.Labels {
+		s.labels[k] = v
+	}
+	if conf.pod.spec.ShareProcessNamespace != nil {
+		share := *conf.pod.spec.ShareProcessNamespace
+		s.shareProcessNamespace = &share
+	}
+	return s
+}
+
+// appendInjectionManifest appends to patchJSON an operation adding the
+// manifest of everything patchJSON adds to the pod.
+func appendInjectionManifest(patchJSON []byte, pathPrefix string, before podSnapshot) ([]byte, error) {
+	var ops []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(patchJSON, &ops); err != nil {
+		return nil, fmt.Errorf("failed to parse injection patch: %w", err)
+	}
+
+	manifest := InjectionManifest{}
+	prior := func(m map[string]string, key string) *string {
+		if v, ok := m[key]; ok {
+			return &v
+		}
+		return nil
+	}
+	// name returns the name of an added volume or container
+	name := func(value json.RawMessage) string {
+		var named struct {
+			Name string `json:"name"`
+		}
+		// Values that aren't objects, like the empty lists added when the
+		// pod has no volumes, have no name
+		_ = json.Unmarshal(value, &named)
+		return named.Name
+	}
+	for _, op := range ops {
+		if op.Op != "add" || !strings.HasPrefix(op.Path, pathPrefix) {
+			continue
+		}
+		path := strings.TrimPrefix(op.Path, pathPrefix)
+		switch {
+		case strings.HasPrefix(path, "/metadata/annotations/"):
+			key := unescapePathSegment(strings.TrimPrefix(path, "/metadata/annotations/"))
+			if manifest.Annotations == nil {
+				manifest.Annotations = map[string]*string{}
+			}
+			manifest.Annotations[key] = prior(before.annotations, key)
+		case strings.HasPrefix(path, "/metadata/labels/"):
+			key := unescapePathSegment(strings.TrimPrefix(path, "/metadata/labels/"))
+			if manifest.Labels == nil {
+				manifest.Labels = map[string]*string{}
+			}
+			manifest.Labels[key] = prior(before.labels, key)
+		case strings.HasPrefix(path, "/spec/volumes/"):
+			manifest.Volumes = append(manifest.Volumes, name(op.Value))
+		case strings.HasPrefix(path, "/spec/initContainers/"):
+			manifest.InitContainers = append(manifest.InitContainers, name(op.Value))
+		case strings.HasPrefix(path, "/spec/containers/"):
+			manifest.Containers = append(manifest.Containers, name(op.Value))
+		case path == "/spec/shareProcessNamespace":
+			share := ""
+			if before.shareProcessNamespace != nil {
+				share = strconv.FormatBool(*before.shareProcessNamespace)
+			}
+			manifest.ShareProcessNamespace = &share
+		}
+	}
+
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	op, err := json.Marshal(map[string]string{
+		"op":    "add",
+		"path":  fmt.Sprintf("%s/metadata/annotations/%s", pathPrefix, escapePathSegment(k8s.ProxyInjectionManifestAnnotation)),
+		"value": string(manifestJSON),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	end := bytes.LastIndexByte(patchJSON, ']')
+	if end < 0 {
+		return nil, fmt.Errorf("invalid injection patch: %s", patchJSON)
+	}
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimRight(patchJSON[:end], " \n"))
+	if len(ops) > 0 {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\n  ")
+	buf.Write(op)
+	buf.WriteString("\n]")
+	return buf.Bytes(), nil
+}
+
+// parseInjectionManifest returns the manifest recorded in the pod's
+// annotations, or nil if there's none or it can't be decoded, in which case
+// uninjection falls back to removing everything injection may have added.
+func (conf *ResourceConfig) parseInjectionManifest() *InjectionManifest {
+	value, ok := conf.pod.meta.Annotations[k8s.ProxyInjectionManifestAnnotation]
+	if !ok {
+		return nil
+	}
+	var manifest InjectionManifest
+	if err := json.Unmarshal([]byte(value), &manifest); err != nil {
+		log.Warnf("ignoring invalid %s annotation: %s", k8s.ProxyInjectionManifestAnnotation, err)
+		return nil
+	}
+	return &manifest
+}
+
+// restore removes from the pod of conf everything listed in the manifest,
+// and restores the values injection overwrote.
+func (m *InjectionManifest) restore(conf *ResourceConfig, report *Report) {
+	t := conf.pod.spec
+	added := func(names []string) map[string]struct{} {
+		set := map[string]struct{}{}
+		for _, name := range names {
+			set[name] = struct{}{}
+		}
+		return set
+	}
+
+	initContainers := added(m.InitContainers)
+	if _, ok := initContainers[k8s.InitContainerName]; ok {
+		report.Uninjected.ProxyInit = true
+	}
+	t.InitContainers = removeContainers(t.InitContainers, initContainers)
+	t.Containers = removeContainers(t.Containers, added(m.Containers))
+	report.Uninjected.Proxy = true
+
+	volumes := added(m.Volumes)
+	kept := t.Volumes[:0]
+	for _, v := range t.Volumes {
+		if _, ok := volumes[v.Name]; !ok {
+			kept = append(kept, v)
+		}
+	}
+	t.Volumes = kept
+	if len(t.Volumes) == 0 {
+		t.Volumes = nil
+	}
+
+	if m.ShareProcessNamespace != nil {
+		t.ShareProcessNamespace = nil
+		if share, err := strconv.ParseBool(*m.ShareProcessNamespace); err == nil {
+			t.ShareProcessNamespace = &share
+		}
+	}
+
+	delete(conf.pod.meta.Annotations, k8s.ProxyInjectionManifestAnnotation)
+	restoreMap(conf.pod.meta.Annotations, m.Annotations)
+	restoreMap(conf.pod.meta.Labels, m.Labels)
+}
+
+func removeContainers(containers []corev1.Container, names map[string]struct{}) []corev1.Container {
+	var kept []corev1.Container
+	for _, c := range containers {
+		if _, ok := names[c.Name]; !ok {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+func restoreMap(m map[string]string, prior map[string]*string) {
+	if m == nil {
+		return
+	}
+	for k, v := range prior {
+		if v == nil {
+			delete(m, k)
+		} else {
+			m[k] = *v
+		}
+	}
+}
+
+// escapePathSegment and unescapePathSegment encode a JSON pointer segment,
+// as described in RFC 6901.
+func escapePathSegment(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+func unescapePathSegment(s string) string {
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(s)
+}
@@ -22,6 +22,7 @@ const (
 	invalidInjectAnnotationNamespace     = "invalid_inject_annotation_at_ns"
 	disabledAutomountServiceAccountToken = "disabled_automount_service_account_token_account"
 	udpPortsEnabled                      = "udp_ports_enabled"
+	jobProxyShutdownUnavailable          = "job_proxy_shutdown_unavailable"
 )
 
 var (
@@ -36,6 +37,7 @@ var (
 		invalidInjectAnnotationNamespace:     fmt.Sprintf("invalid value for annotation \"%s\" at namespace", k8s.ProxyInjectAnnotation),
 		disabledAutomountServiceAccountToken: "automountServiceAccountToken set to \"false\", with Values.identity.serviceAccountTokenProjection set to \"false\"",
 		udpPortsEnabled:                      "UDP port(s) configured on pod spec",
+		jobProxyShutdownUnavailable:          "the pod belongs to a Job that enables the proxy supervisor, which requires shareProcessNamespace, but the pod disables it or can't use it with hostPID",
 	}
 
 	// Set of valid inject annotation values
@@ -69,6 +71,11 @@ type Report struct {
 	Annotated                    bool
 	AutomountServiceAccountToken bool
 
+	// JobProxyShutdownUnavailable is true for the pods of Jobs whose proxy
+	// couldn't be shut down once their containers have terminated, which
+	// would keep the Job from completing
+	JobProxyShutdownUnavailable bool
+
 	// Uninjected consists of two boolean flags to indicate if a proxy and
 	// proxy-init containers have been uninjected in this report
 	Uninjected struct {
@@ -78,6 +85,14 @@ type Report struct {
 		// ProxyInit is true if a proxy-init container has been uninjected
 		ProxyInit bool
 	}
+
+	// Explanation is the provenance of the effective proxy settings. It's
+	// only populated when explicitly requested.
+	Explanation *Explanation
+
+	// PortFindings are the port and protocol misconfigurations found by
+	// AnalyzePorts. They don't prevent injection.
+	PortFindings []PortFinding
 }
 
 // newReport returns a new Report struct, initialized with the Kind and Name
@@ -104,6 +119,7 @@ func newReport(conf *ResourceConfig) *Report {
 		report.HostNetwork = conf.pod.spec.HostNetwork
 		report.Sidecar = healthcheck.HasExistingSidecars(conf.pod.spec)
 		report.UDP = checkUDPPorts(conf.pod.spec)
+		report.JobProxyShutdownUnavailable = conf.jobProxyShutdownUnavailable()
 		if conf.pod.spec.AutomountServiceAccountToken != nil &&
 			(conf.values != nil && !conf.values.Identity.ServiceAccountTokenProjection) {
 			report.AutomountServiceAccountToken = *conf.pod.spec.AutomountServiceAccountToken
@@ -133,7 +149,8 @@ func (r *Report) ResName() string {
 }
 
 // Injectable returns false if the report flags indicate that the workload is on a host network
-// or there is already a sidecar or the resource is not supported or inject is explicitly disabled.
+// or there is already a sidecar or the resource is not supported or inject is explicitly disabled,
+// or if the proxy of a Job's pod couldn't be shut down.
 // If false, the second returned value describes the reason.
 func (r *Report) Injectable() (bool, []string) {
 	var reasons []string
@@ -154,6 +171,10 @@ func (r *Report) Injectable() (bool, []string) {
 		reasons = append(reasons, disabledAutomountServiceAccountToken)
 	}
 
+	if r.JobProxyShutdownUnavailable {
+		reasons = append(reasons, jobProxyShutdownUnavailable)
+	}
+
 	if len(reasons) > 0 {
 		return false, reasons
 	}
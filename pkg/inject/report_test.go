@@ -2,8 +2,10 @@ package inject
 
 import (
 	"fmt"
+	"slices"
 	"testing"
 
+	l5dcharts "github.com/linkerd/linkerd2/pkg/charts/linkerd2"
 	"github.com/linkerd/linkerd2/pkg/k8s"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -423,3 +425,82 @@ func TestDisableByAnnotation(t *testing.T) {
 		}
 	})
 }
+
+func TestJobProxyShutdownUnavailable(t *testing.T) {
+	values, err := l5dcharts.NewValues()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	values.Proxy.NativeSidecar = false
+	values.Proxy.EnableJobSupervisor = true
+	shareProcessNamespace := false
+
+	testCases := []struct {
+		desc          string
+		kind          string
+		podSpec       *corev1.PodSpec
+		annotations   map[string]string
+		nsAnnotations map[string]string
+		unavailable   bool
+	}{
+		{
+			desc:    "job with a shareable process namespace",
+			kind:    "Job",
+			podSpec: &corev1.PodSpec{},
+		},
+		{
+			desc:        "job with hostPID",
+			kind:        "Job",
+			podSpec:     &corev1.PodSpec{HostPID: true},
+			unavailable: true,
+		},
+		{
+			desc:        "cronjob with shareProcessNamespace disabled",
+			kind:        "CronJob",
+			podSpec:     &corev1.PodSpec{ShareProcessNamespace: &shareProcessNamespace},
+			unavailable: true,
+		},
+		{
+			desc:        "job with native sidecars enabled",
+			kind:        "Job",
+			podSpec:     &corev1.PodSpec{HostPID: true},
+			annotations: map[string]string{k8s.ProxyEnableNativeSidecarAnnotation: "true"},
+		},
+		{
+			desc:          "job in a namespace enabling the shutdown endpoint",
+			kind:          "Job",
+			podSpec:       &corev1.PodSpec{HostPID: true},
+			nsAnnotations: map[string]string{k8s.ProxyAdminShutdownAnnotation: k8s.Enabled},
+		},
+		{
+			desc:        "job disabling the supervisor",
+			kind:        "Job",
+			podSpec:     &corev1.PodSpec{HostPID: true},
+			annotations: map[string]string{k8s.ProxyEnableJobSupervisorAnnotation: "false"},
+		},
+		{
+			desc:    "deployment with hostPID",
+			kind:    "Deployment",
+			podSpec: &corev1.PodSpec{HostPID: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			conf := NewResourceConfig(values, OriginWebhook, "linkerd").WithNsAnnotations(tc.nsAnnotations)
+			conf.workload.metaType.Kind = tc.kind
+			conf.pod.spec = tc.podSpec
+			conf.pod.meta = &metav1.ObjectMeta{Annotations: tc.annotations}
+
+			report := newReport(conf)
+			if report.JobProxyShutdownUnavailable != tc.unavailable {
+				t.Fatalf("Expected JobProxyShutdownUnavailable to be %t", tc.unavailable)
+			}
+			_, reasons := report.Injectable()
+			if slices.Contains(reasons, jobProxyShutdownUnavailable) != tc.unavailable {
+				t.Fatalf("Unexpected reasons: %v", reasons)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package inject
+
+import (
+	"strings"
+
+	l5dcharts "github.com/linkerd/linkerd2/pkg/charts/linkerd2"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+)
+
+// isJobPod returns true if the pods are created by a Job, either directly or
+// through a CronJob.
+func (conf *ResourceConfig) isJobPod() bool {
+	isJob := func(kind string) bool {
+		kind = strings.ToLower(kind)
+		return kind == k8s.Job || kind == k8s.CronJob
+	}
+
+	if !conf.IsPod() {
+		return isJob(conf.workload.metaType.Kind)
+	}
+	if ref := conf.workload.ownerRef; ref != nil {
+		return isJob(ref.Kind)
+	}
+	for _, ref := range conf.pod.meta.OwnerReferences {
+		if isJob(ref.Kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsProxySupervisor returns true if the supervisor is enabled and the proxy
+// of a Job's pod would keep running once the Job's containers have
+// terminated. Native sidecars are stopped by the kubelet, and an enabled
+// shutdown endpoint means that the workload shuts the proxy down itself.
+func (conf *ResourceConfig) needsProxySupervisor(values *l5dcharts.Values) bool {
+	return values.Proxy.EnableJobSupervisor && conf.isJobPod() &&
+		!values.Proxy.NativeSidecar && !values.Proxy.EnableShutdownEndpoint
+}
+
+// processNamespaceShareable returns true if the pod's process namespace can be
+// shared, which the proxy supervisor needs to see the workload's processes.
+func (conf *ResourceConfig) processNamespaceShareable() bool {
+	spec := conf.pod.spec
+	if spec.HostPID {
+		return false
+	}
+	return spec.ShareProcessNamespace == nil || *spec.ShareProcessNamespace
+}
+
+// jobProxyShutdownUnavailable returns true if the pod enables the proxy
+// supervisor but can't have one. The settings are resolved from the
+// workload's and namespace's annotations, as injection would.
+func (conf *ResourceConfig) jobProxyShutdownUnavailable() bool {
+	if conf.values == nil || !conf.isJobPod() || conf.processNamespaceShareable() {
+		return false
+	}
+
+	values, err := conf.values.DeepCopy()
+	if err != nil {
+		log.Warnf("failed to copy values: %s", err)
+		return false
+	}
+	annotations := map[string]string{}
+	AppendNamespaceAnnotations(annotations, conf.nsAnnotations, conf.pod.meta.Annotations)
+	for k, v := range conf.pod.meta.Annotations {
+		annotations[k] = v
+	}
+	ApplyAnnotationOverrides(values, annotations, nil, nil)
+
+	return conf.needsProxySupervisor(values)
+}
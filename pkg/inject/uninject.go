@@ -20,6 +20,14 @@ func (conf *ResourceConfig) Uninject(report *Report) ([]byte, error) {
 		return nil, nil
 	}
 
+	// Pods injected with a manifest of what was added get their original
+	// spec back. Injection doesn't change the workload's own metadata, so
+	// it's left as is.
+	if manifest := conf.parseInjectionManifest(); manifest != nil {
+		manifest.restore(conf, report)
+		return conf.YamlMarshalObj()
+	}
+
 	conf.uninjectPodSpec(report)
 
 	if conf.workload.Meta != nil {
@@ -49,7 +57,7 @@ func (conf *ResourceConfig) uninjectPodSpec(report *Report) {
 
 	containers := []v1.Container{}
 	for _, container := range t.Containers {
-		if container.Name != k8s.ProxyContainerName {
+		if container.Name != k8s.ProxyContainerName && container.Name != k8s.ProxySupervisorContainerName {
 			containers = append(containers, container)
 		} else {
 			report.Uninjected.Proxy = true
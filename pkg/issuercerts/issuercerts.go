@@ -19,7 +19,10 @@ import (
 )
 
 const keyMissingError = "key %s containing the %s needs to exist in secret %s if --identity-external-issuer=%v"
-const expirationWarningThresholdInDays = 60
+
+// ExpirationWarningThresholdInDays is the default number of days before
+// their expiration after which certificates are considered expiring soon
+const ExpirationWarningThresholdInDays = 60
 
 // IssuerCertData holds the trust anchors cert data used by the CA
 type IssuerCertData struct {
@@ -128,7 +131,13 @@ func CheckCertValidityPeriod(cert *x509.Certificate) error {
 
 // CheckExpiringSoon returns an error if a certificate is expiring soon
 func CheckExpiringSoon(cert *x509.Certificate) error {
-	if time.Now().AddDate(0, 0, expirationWarningThresholdInDays).After(cert.NotAfter) {
+	return CheckExpiringWithin(cert, ExpirationWarningThresholdInDays)
+}
+
+// CheckExpiringWithin returns an error if a certificate expires within the
+// given number of days
+func CheckExpiringWithin(cert *x509.Certificate, days int) error {
+	if time.Now().AddDate(0, 0, days).After(cert.NotAfter) {
 		return fmt.Errorf("will expire on %s", cert.NotAfter.Format(time.RFC3339))
 	}
 	return nil
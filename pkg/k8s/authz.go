@@ -150,6 +150,42 @@ func ExtWorkloadAccess(ctx context.Context, k8sClient kubernetes.Interface) erro
 	return errors.New("ExternalWorkload CRD not found")
 }
 
+// ProxyConfigAccess checks whether the ProxyConfig CRD is installed on the
+// cluster and the client is authorized to access ProxyConfigs
+func ProxyConfigAccess(ctx context.Context, k8sClient kubernetes.Interface) error {
+	groupVersion := fmt.Sprintf("%s/%s", ProxyConfigAPIGroup, ProxyConfigAPIVersion)
+	res, err := k8sClient.Discovery().ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return err
+	}
+	if res.GroupVersion == groupVersion {
+		for _, apiRes := range res.APIResources {
+			if apiRes.Kind == ProxyConfigKind {
+				return ResourceAuthz(ctx, k8sClient, "", "list", ProxyConfigAPIGroup, "", "proxyconfigs", "")
+			}
+		}
+	}
+	return errors.New("ProxyConfig CRD not found")
+}
+
+// ProxyRolloutAccess checks whether the ProxyRollout CRD is installed on the
+// cluster and the client is authorized to access ProxyRollouts
+func ProxyRolloutAccess(ctx context.Context, k8sClient kubernetes.Interface) error {
+	groupVersion := fmt.Sprintf("%s/%s", ProxyConfigAPIGroup, ProxyConfigAPIVersion)
+	res, err := k8sClient.Discovery().ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return err
+	}
+	if res.GroupVersion == groupVersion {
+		for _, apiRes := range res.APIResources {
+			if apiRes.Kind == ProxyRolloutKind {
+				return ResourceAuthz(ctx, k8sClient, "", "list", ProxyConfigAPIGroup, "", "proxyrollouts", "")
+			}
+		}
+	}
+	return errors.New("ProxyRollout CRD not found")
+}
+
 // LinksAccess checks whether the Links CRD is installed on the
 // cluster and the client is authorized to access Links
 func LinksAccess(ctx context.Context, k8sClient kubernetes.Interface) error {
@@ -46,7 +46,7 @@ func init() {
 
 // NewFakeAPI provides a mock KubernetesAPI backed by hard-coded resources
 func NewFakeAPI(configs ...string) (*KubernetesAPI, error) {
-	client, apiextClient, apiregClient, _, _, err := NewFakeClientSets(configs...)
+	client, apiextClient, apiregClient, _, dynamicClient, err := NewFakeClientSets(configs...)
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +56,7 @@ func NewFakeAPI(configs ...string) (*KubernetesAPI, error) {
 		Interface:       client,
 		Apiextensions:   apiextClient,
 		Apiregistration: apiregClient,
+		DynamicClient:   dynamicClient,
 	}, nil
 }
 
@@ -107,6 +108,10 @@ func NewFakeClientSets(configs ...string) (
 			spObjs = append(spObjs, obj)
 		case ExtWorkload:
 			spObjs = append(spObjs, obj)
+		case ProxyConfig:
+			spObjs = append(spObjs, obj)
+		case ProxyRollout:
+			spObjs = append(spObjs, obj)
 		default:
 			objs = append(objs, obj)
 		}
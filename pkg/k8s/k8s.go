@@ -26,10 +26,13 @@ const (
 	Namespace             = "namespace"
 	NetworkAuthentication = "networkauthentication"
 	Pod                   = "pod"
+	ProxyConfig           = "proxyconfig"
+	ProxyRollout          = "proxyrollout"
 	ReplicationController = "replicationcontroller"
 	ReplicaSet            = "replicaset"
 	Secret                = "secret"
 	Service               = "service"
+	ServiceAccount        = "serviceaccount"
 	ServiceProfile        = "serviceprofile"
 	StatefulSet           = "statefulset"
 	Node                  = "node"
@@ -60,6 +63,11 @@ const (
 	WorkloadAPIGroup   = "workload.linkerd.io"
 	WorkloadAPIVersion = "v1alpha1"
 
+	ProxyConfigAPIGroup   = "config.linkerd.io"
+	ProxyConfigAPIVersion = "v1alpha1"
+	ProxyConfigKind       = "ProxyConfig"
+	ProxyRolloutKind      = "ProxyRollout"
+
 	// special case k8s job label, to not conflict with Prometheus' job label
 	l5dJob = "k8s_job"
 )
@@ -96,6 +96,14 @@ const (
 	// (e.g. v0.1.3).
 	ProxyVersionAnnotation = Prefix + "/proxy-version"
 
+	// ProxyInjectionManifestAnnotation records what the proxy injection
+	// added to the pod, so that it can be uninjected exactly.
+	ProxyInjectionManifestAnnotation = Prefix + "/inject-manifest"
+
+	// ProxyRolloutAnnotation indicates the ProxyRollout whose canary version
+	// the injected data plane runs.
+	ProxyRolloutAnnotation = Prefix + "/proxy-rollout"
+
 	// ProxyInjectAnnotation controls whether or not a pod should be injected
 	// when set on a pod spec. When set on a namespace spec, it applies to all
 	// pods in the namespace. Supported values are Enabled or Disabled
@@ -113,6 +121,12 @@ const (
 	// disable injection for a pod or namespace.
 	ProxyInjectDisabled = Disabled
 
+	// EnforceMeshingLabel, when set to "true" on a namespace, has the proxy
+	// injector deny the admission of its pods that wouldn't be meshed, or that
+	// override the proxy's configuration in forbidden ways. When enforcement is
+	// enabled cluster-wide, setting it to "false" exempts a namespace.
+	EnforceMeshingLabel = ProxyConfigAnnotationsPrefix + "/enforce-meshing"
+
 	// ProxyTrustRootSHA indicates the cert bundle configured on the injected
 	// workload.
 	ProxyTrustRootSHA = Prefix + "/trust-root-sha256"
@@ -290,6 +304,11 @@ const (
 	// ProxyEnableNativeSidecarAnnotation enables the native initContainer sidecar
 	ProxyEnableNativeSidecarAnnotation = ProxyConfigAnnotationsPrefix + "/proxy-enable-native-sidecar"
 
+	// ProxyEnableJobSupervisorAnnotation adds the proxy supervisor to the pods
+	// of Jobs that don't use native sidecars. It shares the pod's process
+	// namespace.
+	ProxyEnableJobSupervisorAnnotation = ProxyConfigAnnotationsPrefix + "/proxy-enable-job-supervisor"
+
 	// ProxyAwait can be used to force the application to wait for the proxy
 	// to be ready.
 	ProxyAwait = ProxyConfigAnnotationsPrefix + "/proxy-await"
@@ -364,6 +383,10 @@ const (
 	// ProxyContainerName is the name assigned to the injected proxy container.
 	ProxyContainerName = "linkerd-proxy"
 
+	// ProxySupervisorContainerName is the name assigned to the container
+	// that shuts the proxy down once a Job's containers have terminated.
+	ProxySupervisorContainerName = "linkerd-proxy-supervisor"
+
 	// IdentityEndEntityVolumeName is the name assigned the temporary end-entity
 	// volume mounted into each proxy to store identity credentials.
 	IdentityEndEntityVolumeName = "linkerd-identity-end-entity"
@@ -371,6 +394,30 @@ const (
 	// IdentityIssuerSecretName is the name of the Secret that stores issuer credentials.
 	IdentityIssuerSecretName = "linkerd-identity-issuer"
 
+	// IdentityAttestationSubjectAnnotation binds an ExternalWorkload to the
+	// subject of a signed attestation document, allowing the workload to
+	// obtain a certificate for its spec.meshTLS.identity without a
+	// ServiceAccount token.
+	IdentityAttestationSubjectAnnotation = "identity." + Prefix + "/attestation-subject"
+
+	// IdentityIssuanceLifetimeAnnotation can be set on a Namespace or
+	// ServiceAccount to override the lifetime of the certificates issued to
+	// its workloads. The value is clamped by the identity controller's
+	// configured minimum and maximum lifetimes.
+	IdentityIssuanceLifetimeAnnotation = "identity." + Prefix + "/issuance-lifetime"
+
+	// IdentityIssuerStatusLeasePrefix is the name prefix of the Leases through
+	// which each identity controller replica publishes its active issuer.
+	IdentityIssuerStatusLeasePrefix = "linkerd-identity-issuer-"
+
+	// IdentityIssuerFingerprintAnnotation is set on issuer status Leases to the
+	// SHA-256 fingerprint of the issuer certificate a replica signs with.
+	IdentityIssuerFingerprintAnnotation = "identity." + Prefix + "/issuer-fingerprint"
+
+	// IdentityIssuerNotBeforeAnnotation is set on issuer status Leases to the
+	// NotBefore of the issuer certificate a replica signs with, in RFC 3339.
+	IdentityIssuerNotBeforeAnnotation = "identity." + Prefix + "/issuer-not-before"
+
 	// IdentityIssuerSchemeLinkerd is the issuer secret scheme used by linkerd
 	IdentityIssuerSchemeLinkerd = "linkerd.io/tls"
 
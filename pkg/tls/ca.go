@@ -204,12 +204,25 @@ func (ca *CA) GenerateEndEntityCred(dnsName string) (*Cred, error) {
 // IssueEndEntityCrt creates a new certificate that is valid for the
 // given DNS name, generating a new keypair for it.
 func (ca *CA) IssueEndEntityCrt(csr *x509.CertificateRequest) (Crt, error) {
+	return ca.issueEndEntityCrt(csr, ca.Validity)
+}
+
+// IssueEndEntityCrtWithLifetime behaves like IssueEndEntityCrt, but the issued
+// certificate is valid for the provided lifetime rather than the CA's
+// configured one.
+func (ca *CA) IssueEndEntityCrtWithLifetime(csr *x509.CertificateRequest, lifetime time.Duration) (Crt, error) {
+	validity := ca.Validity
+	validity.Lifetime = lifetime
+	return ca.issueEndEntityCrt(csr, validity)
+}
+
+func (ca *CA) issueEndEntityCrt(csr *x509.CertificateRequest, validity Validity) (Crt, error) {
 	pubkey, ok := csr.PublicKey.(*ecdsa.PublicKey)
 	if !ok {
 		return Crt{}, fmt.Errorf("CSR must contain an ECDSA public key: %+v", csr.PublicKey)
 	}
 
-	t := ca.createTemplate(pubkey)
+	t := ca.createTemplateWithValidity(pubkey, validity)
 	t.Issuer = ca.Cred.Crt.Certificate.Subject
 	t.Subject = csr.Subject
 	t.Extensions = csr.Extensions
@@ -226,7 +239,11 @@ func (ca *CA) IssueEndEntityCrt(csr *x509.CertificateRequest) (Crt, error) {
 // no subject name, no subjectAltNames. The t can then be modified into
 // a (root) CA t or an end-entity t by the caller.
 func (ca *CA) createTemplate(pubkey *ecdsa.PublicKey) *x509.Certificate {
-	c := createTemplate(ca.nextSerialNumber, pubkey, ca.Validity)
+	return ca.createTemplateWithValidity(pubkey, ca.Validity)
+}
+
+func (ca *CA) createTemplateWithValidity(pubkey *ecdsa.PublicKey, validity Validity) *x509.Certificate {
+	c := createTemplate(ca.nextSerialNumber, pubkey, validity)
 	ca.nextSerialNumber++
 	// if our trust chain contains a certificate that expires
 	// sooner than the one we intend to issue, we clamp the
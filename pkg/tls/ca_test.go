@@ -1,6 +1,8 @@
 package tls
 
 import (
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"testing"
 	"time"
 )
@@ -65,3 +67,33 @@ func TestCaIssuesCertsWithCorrectExpiration(t *testing.T) {
 	}
 
 }
+
+func TestCaIssuesCertsWithLifetimeOverride(t *testing.T) {
+	validFrom := time.Now().UTC().Round(time.Second)
+	ca, err := getCa(validFrom, time.Hour*48, time.Hour*24)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	csr := x509.CertificateRequest{
+		Subject:   pkix.Name{CommonName: "fake-name"},
+		DNSNames:  []string{"fake-name"},
+		PublicKey: &key.PublicKey,
+	}
+
+	crt, err := ca.IssueEndEntityCrtWithLifetime(&csr, time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := validFrom.Add(time.Hour).Add(DefaultClockSkewAllowance)
+	if crt.Certificate.NotAfter != expected {
+		t.Fatalf("Expected cert expiration %v but got %v", expected, crt.Certificate.NotAfter)
+	}
+	if ca.Validity.Lifetime != time.Hour*24 {
+		t.Fatalf("Expected the CA's validity to be unchanged, got %v", ca.Validity.Lifetime)
+	}
+}
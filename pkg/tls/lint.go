@@ -0,0 +1,327 @@
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"time"
+)
+
+type (
+	// LintSeverity describes how serious a LintFinding is.
+	LintSeverity string
+
+	// LintFinding is the result of a single check performed by LintChain.
+	LintFinding struct {
+		// Check identifies the check that produced the finding.
+		Check string `json:"check"`
+		// Severity is LintOK when the check passed.
+		Severity LintSeverity `json:"severity"`
+		// Subject is the subject of the certificate the finding applies to,
+		// or empty when it applies to the chain as a whole.
+		Subject string `json:"subject,omitempty"`
+		// Message describes the finding.
+		Message string `json:"message"`
+	}
+
+	// LintOptions configures LintChain.
+	LintOptions struct {
+		// TrustAnchors are the roots the chain must verify against. Chain
+		// completeness is not checked when nil.
+		TrustAnchors *x509.CertPool
+
+		// ExpectedIdentity is the DNS-form identity the leaf certificate must
+		// be valid for. SAN checks are skipped when empty.
+		ExpectedIdentity string
+
+		// MinRemainingLifetime is the remaining validity below which a
+		// certificate is reported as expiring soon.
+		MinRemainingLifetime time.Duration
+
+		// ClockSkewAllowance is the tolerated difference between the local
+		// clock and a certificate's NotBefore.
+		ClockSkewAllowance time.Duration
+
+		// Now overrides the time at which the chain is evaluated.
+		Now time.Time
+	}
+)
+
+// These constants enumerate the possible LintSeverity values.
+const (
+	LintOK      LintSeverity = "ok"
+	LintWarning LintSeverity = "warning"
+	LintError   LintSeverity = "error"
+)
+
+// These constants identify the checks performed by LintChain.
+const (
+	LintCheckChain              = "chain"
+	LintCheckSAN                = "san"
+	LintCheckKeyUsage           = "key-usage"
+	LintCheckPathLength         = "path-length"
+	LintCheckClockSkew          = "clock-skew"
+	LintCheckLifetime           = "lifetime"
+	LintCheckSignatureAlgorithm = "signature-algorithm"
+	LintCheckPublicKey          = "public-key"
+)
+
+// LintChain inspects a certificate chain, ordered from leaf to root as
+// presented in a TLS handshake, and reports on every property that commonly
+// breaks mTLS between proxies. The first certificate is treated as the
+// end-entity certificate, unless it is a CA, in which case the chain is
+// linted as an issuer chain.
+func LintChain(chain []*x509.Certificate, opts LintOptions) []LintFinding {
+	if len(chain) == 0 {
+		return []LintFinding{{Check: LintCheckChain, Severity: LintError, Message: "no certificates found"}}
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.ClockSkewAllowance == 0 {
+		opts.ClockSkewAllowance = DefaultClockSkewAllowance
+	}
+
+	findings := []LintFinding{lintChainCompleteness(chain, opts)}
+	leaf := chain[0]
+	if !leaf.IsCA {
+		findings = append(findings, lintSAN(leaf, opts.ExpectedIdentity))
+	}
+	for i, c := range chain {
+		isLeaf := i == 0 && !c.IsCA
+		findings = append(findings,
+			lintKeyUsage(c, isLeaf),
+			lintClockSkew(c, opts),
+			lintLifetime(c, opts),
+			lintSignatureAlgorithm(c),
+			lintPublicKey(c),
+		)
+	}
+	return append(findings, lintPathLength(chain)...)
+}
+
+// LintWorstSeverity returns the most serious severity among the findings.
+func LintWorstSeverity(findings []LintFinding) LintSeverity {
+	worst := LintOK
+	for _, f := range findings {
+		switch f.Severity {
+		case LintError:
+			return LintError
+		case LintWarning:
+			worst = LintWarning
+		}
+	}
+	return worst
+}
+
+func lintChainCompleteness(chain []*x509.Certificate, opts LintOptions) LintFinding {
+	finding := LintFinding{Check: LintCheckChain}
+	for i := 1; i < len(chain); i++ {
+		if err := chain[i-1].CheckSignatureFrom(chain[i]); err != nil {
+			finding.Severity = LintError
+			finding.Subject = chain[i-1].Subject.String()
+			finding.Message = fmt.Sprintf("not signed by the next certificate in the chain (%s): %s", chain[i].Subject, err)
+			return finding
+		}
+	}
+
+	if opts.TrustAnchors == nil {
+		finding.Severity = LintWarning
+		finding.Message = "no trust anchors provided; chain completeness was not verified"
+		return finding
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+	// Verify at a time at which the chain is known to be valid, so that
+	// expiry is reported by the lifetime check rather than here.
+	verifyAt := chain[0].NotBefore.Add(time.Second)
+	for _, c := range chain {
+		if c.NotBefore.After(verifyAt) {
+			verifyAt = c.NotBefore.Add(time.Second)
+		}
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         opts.TrustAnchors,
+		Intermediates: intermediates,
+		CurrentTime:   verifyAt,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		finding.Severity = LintError
+		finding.Message = fmt.Sprintf("chain does not verify against the trust anchors: %s", err)
+		return finding
+	}
+
+	finding.Severity = LintOK
+	finding.Message = "chain verifies against the trust anchors"
+	return finding
+}
+
+func lintSAN(leaf *x509.Certificate, expected string) LintFinding {
+	finding := LintFinding{Check: LintCheckSAN, Subject: leaf.Subject.String(), Severity: LintError}
+	switch {
+	case len(leaf.DNSNames) != 1:
+		finding.Message = fmt.Sprintf("expected exactly one DNS SAN, found %d: %v", len(leaf.DNSNames), leaf.DNSNames)
+	case expected != "" && leaf.DNSNames[0] != expected:
+		finding.Message = fmt.Sprintf("DNS SAN %s does not match the expected identity %s", leaf.DNSNames[0], expected)
+	case len(leaf.IPAddresses) > 0 || len(leaf.EmailAddresses) > 0:
+		finding.Severity = LintWarning
+		finding.Message = "certificate carries IP or email SANs that proxies do not use"
+	default:
+		for _, u := range leaf.URIs {
+			if u.Scheme != "spiffe" {
+				finding.Severity = LintWarning
+				finding.Message = fmt.Sprintf("unexpected non-SPIFFE URI SAN: %s", u)
+				return finding
+			}
+		}
+		finding.Severity = LintOK
+		finding.Message = fmt.Sprintf("DNS SAN is %s", leaf.DNSNames[0])
+	}
+	return finding
+}
+
+func lintKeyUsage(c *x509.Certificate, isLeaf bool) LintFinding {
+	finding := LintFinding{Check: LintCheckKeyUsage, Subject: c.Subject.String(), Severity: LintError}
+	if isLeaf {
+		if c.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+			finding.Message = "end-entity certificate lacks the digitalSignature key usage"
+			return finding
+		}
+		var server, client bool
+		for _, eku := range c.ExtKeyUsage {
+			switch eku {
+			case x509.ExtKeyUsageServerAuth:
+				server = true
+			case x509.ExtKeyUsageClientAuth:
+				client = true
+			case x509.ExtKeyUsageAny:
+				server, client = true, true
+			}
+		}
+		if len(c.ExtKeyUsage) > 0 && (!server || !client) {
+			finding.Message = "end-entity certificate must allow both serverAuth and clientAuth extended key usages"
+			return finding
+		}
+		finding.Severity = LintOK
+		finding.Message = "end-entity key usages are valid"
+		return finding
+	}
+
+	if !c.IsCA || !c.BasicConstraintsValid {
+		finding.Message = "issuing certificate is not marked as a CA"
+		return finding
+	}
+	if c.KeyUsage != 0 && c.KeyUsage&x509.KeyUsageCertSign == 0 {
+		finding.Message = "CA certificate lacks the keyCertSign key usage"
+		return finding
+	}
+	finding.Severity = LintOK
+	finding.Message = "CA key usages are valid"
+	return finding
+}
+
+func lintPathLength(chain []*x509.Certificate) []LintFinding {
+	var findings []LintFinding
+	for i, c := range chain {
+		if !c.IsCA || !c.BasicConstraintsValid {
+			continue
+		}
+		if c.MaxPathLen < 0 || (c.MaxPathLen == 0 && !c.MaxPathLenZero) {
+			continue
+		}
+		// Count the CA certificates issued (directly or not) by c.
+		below := 0
+		for _, d := range chain[:i] {
+			if d.IsCA {
+				below++
+			}
+		}
+		if below > c.MaxPathLen {
+			findings = append(findings, LintFinding{
+				Check:    LintCheckPathLength,
+				Severity: LintError,
+				Subject:  c.Subject.String(),
+				Message:  fmt.Sprintf("path length constraint of %d is exceeded by %d intermediate CA(s)", c.MaxPathLen, below),
+			})
+		}
+	}
+	if len(findings) == 0 {
+		findings = append(findings, LintFinding{Check: LintCheckPathLength, Severity: LintOK, Message: "path length constraints are satisfied"})
+	}
+	return findings
+}
+
+func lintClockSkew(c *x509.Certificate, opts LintOptions) LintFinding {
+	finding := LintFinding{Check: LintCheckClockSkew, Subject: c.Subject.String()}
+	ahead := c.NotBefore.Sub(opts.Now)
+	switch {
+	case ahead > opts.ClockSkewAllowance:
+		finding.Severity = LintError
+		finding.Message = fmt.Sprintf("certificate is not valid for another %s, which exceeds the clock skew allowance of %s", ahead.Round(time.Second), opts.ClockSkewAllowance)
+	case ahead > 0:
+		finding.Severity = LintWarning
+		finding.Message = fmt.Sprintf("certificate becomes valid in %s, which is within the clock skew allowance", ahead.Round(time.Second))
+	default:
+		finding.Severity = LintOK
+		finding.Message = fmt.Sprintf("valid since %s", c.NotBefore.UTC().Format(time.RFC3339))
+	}
+	return finding
+}
+
+func lintLifetime(c *x509.Certificate, opts LintOptions) LintFinding {
+	finding := LintFinding{Check: LintCheckLifetime, Subject: c.Subject.String()}
+	remaining := c.NotAfter.Sub(opts.Now)
+	switch {
+	case remaining <= 0:
+		finding.Severity = LintError
+		finding.Message = fmt.Sprintf("expired at %s", c.NotAfter.UTC().Format(time.RFC3339))
+	case remaining < opts.MinRemainingLifetime:
+		finding.Severity = LintWarning
+		finding.Message = fmt.Sprintf("expires in %s, at %s", remaining.Round(time.Second), c.NotAfter.UTC().Format(time.RFC3339))
+	default:
+		finding.Severity = LintOK
+		finding.Message = fmt.Sprintf("valid until %s", c.NotAfter.UTC().Format(time.RFC3339))
+	}
+	return finding
+}
+
+func lintSignatureAlgorithm(c *x509.Certificate) LintFinding {
+	finding := LintFinding{Check: LintCheckSignatureAlgorithm, Subject: c.Subject.String()}
+	switch c.SignatureAlgorithm {
+	case x509.ECDSAWithSHA256, x509.ECDSAWithSHA384, x509.ECDSAWithSHA512,
+		x509.SHA256WithRSA, x509.SHA384WithRSA, x509.SHA512WithRSA,
+		x509.SHA256WithRSAPSS, x509.SHA384WithRSAPSS, x509.SHA512WithRSAPSS,
+		x509.PureEd25519:
+		finding.Severity = LintOK
+	default:
+		finding.Severity = LintError
+	}
+	finding.Message = fmt.Sprintf("signed with %s", c.SignatureAlgorithm)
+	return finding
+}
+
+func lintPublicKey(c *x509.Certificate) LintFinding {
+	finding := LintFinding{Check: LintCheckPublicKey, Subject: c.Subject.String(), Severity: LintOK}
+	switch pub := c.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		finding.Message = fmt.Sprintf("ECDSA %s key", pub.Curve.Params().Name)
+	case *rsa.PublicKey:
+		finding.Message = fmt.Sprintf("RSA %d-bit key", pub.N.BitLen())
+		if pub.N.BitLen() < 2048 {
+			finding.Severity = LintError
+		}
+		if !c.IsCA && finding.Severity == LintOK {
+			// Proxies only generate ECDSA keys.
+			finding.Severity = LintWarning
+			finding.Message += "; proxies use ECDSA P-256 keys"
+		}
+	default:
+		finding.Message = fmt.Sprintf("%T key", pub)
+	}
+	return finding
+}
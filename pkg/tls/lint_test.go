@@ -0,0 +1,148 @@
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestLintChain(t *testing.T) {
+	validFrom := time.Now().Add(-time.Minute)
+	root, err := CreateRootCA("root.linkerd.cluster.local", mustGenerateKey(t), Validity{ValidFrom: &validFrom, Lifetime: time.Hour * 24 * 365})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	issuer, err := root.GenerateCA("identity.linkerd.cluster.local", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	issuer.Validity = Validity{Lifetime: time.Hour}
+
+	const id = "default.emojivoto.serviceaccount.identity.linkerd.cluster.local"
+	leaf, err := issuer.GenerateEndEntityCred(id)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	chain := []*x509.Certificate{leaf.Certificate, issuer.Cred.Certificate}
+
+	otherRoot, err := GenerateRootCAWithDefaults("other")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	testCases := []struct {
+		desc     string
+		opts     LintOptions
+		expected map[string]LintSeverity
+	}{
+		{
+			desc: "valid chain",
+			opts: LintOptions{TrustAnchors: root.Cred.CertPool(), ExpectedIdentity: id, MinRemainingLifetime: time.Minute},
+			expected: map[string]LintSeverity{
+				LintCheckChain:    LintOK,
+				LintCheckSAN:      LintOK,
+				LintCheckLifetime: LintOK,
+			},
+		},
+		{
+			desc: "wrong trust anchors",
+			opts: LintOptions{TrustAnchors: otherRoot.Cred.CertPool(), ExpectedIdentity: id},
+			expected: map[string]LintSeverity{
+				LintCheckChain: LintError,
+			},
+		},
+		{
+			desc: "identity mismatch",
+			opts: LintOptions{TrustAnchors: root.Cred.CertPool(), ExpectedIdentity: "web.emojivoto.serviceaccount.identity.linkerd.cluster.local"},
+			expected: map[string]LintSeverity{
+				LintCheckSAN: LintError,
+			},
+		},
+		{
+			desc: "expiring soon",
+			opts: LintOptions{TrustAnchors: root.Cred.CertPool(), MinRemainingLifetime: 2 * time.Hour},
+			expected: map[string]LintSeverity{
+				LintCheckLifetime: LintWarning,
+			},
+		},
+		{
+			desc: "expired",
+			opts: LintOptions{TrustAnchors: root.Cred.CertPool(), Now: time.Now().Add(2 * time.Hour)},
+			expected: map[string]LintSeverity{
+				LintCheckChain:    LintOK,
+				LintCheckLifetime: LintError,
+			},
+		},
+		{
+			desc: "local clock behind",
+			opts: LintOptions{TrustAnchors: root.Cred.CertPool(), Now: validFrom.Add(-time.Hour)},
+			expected: map[string]LintSeverity{
+				LintCheckClockSkew: LintError,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			findings := LintChain(chain, tc.opts)
+			for check, severity := range tc.expected {
+				if got := worstFor(findings, check); got != severity {
+					t.Fatalf("Expected %s check to be %s, got %s: %+v", check, severity, got, findings)
+				}
+			}
+		})
+	}
+}
+
+func TestLintChainPathLength(t *testing.T) {
+	root, err := GenerateRootCAWithDefaults("root")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	issuer, err := root.GenerateCA("issuer", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	sub, err := issuer.GenerateCA("sub-issuer", -1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	findings := LintChain([]*x509.Certificate{sub.Cred.Certificate, issuer.Cred.Certificate}, LintOptions{TrustAnchors: root.Cred.CertPool()})
+	if got := worstFor(findings, LintCheckPathLength); got != LintError {
+		t.Fatalf("Expected path length error, got %s: %+v", got, findings)
+	}
+	if LintWorstSeverity(findings) != LintError {
+		t.Fatalf("Expected worst severity to be %s", LintError)
+	}
+}
+
+func TestLintChainEmpty(t *testing.T) {
+	findings := LintChain(nil, LintOptions{})
+	if len(findings) != 1 || findings[0].Severity != LintError {
+		t.Fatalf("Expected a single error, got %+v", findings)
+	}
+}
+
+func worstFor(findings []LintFinding, check string) LintSeverity {
+	var matching []LintFinding
+	for _, f := range findings {
+		if f.Check == check {
+			matching = append(matching, f)
+		}
+	}
+	if len(matching) == 0 {
+		return ""
+	}
+	return LintWorstSeverity(matching)
+}
+
+func mustGenerateKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return key
+}
@@ -0,0 +1,176 @@
+// proxy-supervisor shuts the proxy down once all the other processes of its
+// pod have terminated. When enabled, it's injected into the pods of Jobs that
+// can't use native sidecars, so that the proxy doesn't keep them from
+// completing. It ships in the controller image.
+//
+// The pod's process namespace must be shared, so that the supervisor can see
+// the processes of every container.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	envAdminPort    = "LINKERD2_PROXY_SUPERVISOR_ADMIN_PORT"
+	envIgnore       = "LINKERD2_PROXY_SUPERVISOR_IGNORE"
+	envPollInterval = "LINKERD2_PROXY_SUPERVISOR_POLL_INTERVAL"
+
+	defaultAdminPort    = 4191
+	defaultPollInterval = time.Second
+	shutdownAttempts    = 30
+)
+
+// infraProcesses are the processes that aren't part of the workload: the
+// pod's sandbox and the proxy. The proxy's comm is its executable's name,
+// truncated to 15 characters by the kernel.
+var infraProcesses = []string{"pause", "linkerd2-proxy", "linkerd2-proxy-"}
+
+func main() {
+	adminPort := defaultAdminPort
+	if v := os.Getenv(envAdminPort); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatalf("Invalid %s: %s", envAdminPort, err)
+		}
+		adminPort = port
+	}
+
+	interval := defaultPollInterval
+	if v := os.Getenv(envPollInterval); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid %s: %s", envPollInterval, err)
+		}
+		interval = d
+	}
+
+	ignore := map[string]struct{}{}
+	for _, comm := range infraProcesses {
+		ignore[comm] = struct{}{}
+	}
+	for _, comm := range strings.Split(os.Getenv(envIgnore), ",") {
+		if comm = strings.TrimSpace(comm); comm != "" {
+			ignore[comm] = struct{}{}
+		}
+	}
+
+	s := &supervisor{procDir: "/proc", self: os.Getpid(), ignore: ignore}
+	s.waitForWorkload(interval)
+	log.Info("All workload processes have terminated, shutting down the proxy")
+
+	if err := shutdown(fmt.Sprintf("http://127.0.0.1:%d/shutdown", adminPort), interval); err != nil {
+		log.Fatalf("Failed to shut down the proxy: %s", err)
+	}
+}
+
+type supervisor struct {
+	procDir string
+	self    int
+	ignore  map[string]struct{}
+}
+
+// waitForWorkload returns once no workload process is running. The supervisor
+// is the last container of the pod, so the kubelet has started every workload
+// container by the time it runs: when no workload process is found on the
+// first poll, the workload has already terminated.
+func (s *supervisor) waitForWorkload(interval time.Duration) {
+	first := true
+	for {
+		running, err := s.workloadProcesses()
+		if err != nil {
+			log.Warnf("Failed to list processes: %s", err)
+		} else if running == 0 {
+			return
+		} else if first {
+			log.Infof("Supervising %d workload processes", running)
+			first = false
+		}
+		time.Sleep(interval)
+	}
+}
+
+// workloadProcesses returns the number of running processes that belong to
+// the workload, i.e. that are neither the supervisor nor ignored.
+func (s *supervisor) workloadProcesses() (int, error) {
+	entries, err := os.ReadDir(s.procDir)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == 1 || pid == s.self {
+			continue
+		}
+
+		comm, err := os.ReadFile(filepath.Join(s.procDir, entry.Name(), "comm"))
+		if err != nil {
+			// The process has terminated since the directory was listed
+			continue
+		}
+		if _, ok := s.ignore[strings.TrimSpace(string(comm))]; ok {
+			continue
+		}
+
+		stat, err := os.ReadFile(filepath.Join(s.procDir, entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		if state, err := processState(string(stat)); err != nil || state == "Z" || state == "X" {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// processState extracts the state field from the contents of /proc/<pid>/stat.
+// It follows the command name, which is parenthesized and may contain spaces.
+func processState(stat string) (string, error) {
+	i := strings.LastIndex(stat, ")")
+	if i < 0 {
+		return "", errors.New("malformed stat")
+	}
+	fields := strings.Fields(stat[i+1:])
+	if len(fields) == 0 {
+		return "", errors.New("malformed stat")
+	}
+	return fields[0], nil
+}
+
+// shutdown asks the proxy to shut down through its admin server, retrying
+// while the proxy isn't reachable.
+func shutdown(url string, interval time.Duration) error {
+	client := &http.Client{Timeout: 5 * time.Second}
+	var err error
+	for i := 0; i < shutdownAttempts; i++ {
+		var req *http.Request
+		req, err = http.NewRequest(http.MethodPost, url, nil)
+		if err != nil {
+			return err
+		}
+		var resp *http.Response
+		resp, err = client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+			err = fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+		log.Debugf("Proxy shutdown failed: %s", err)
+		time.Sleep(interval)
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWorkloadProcesses(t *testing.T) {
+	procDir := t.TempDir()
+	processes := []struct {
+		pid   int
+		comm  string
+		state string
+	}{
+		{1, "pause", "S"},
+		{7, "linkerd2-proxy", "S"},
+		{12, "linkerd2-proxy-", "S"},
+		{20, "tshark", "S"},
+		{31, "job", "R"},
+		{32, "sh", "S"},
+		{33, "defunct", "Z"},
+	}
+	for _, p := range processes {
+		writeProcess(t, procDir, p.pid, p.comm, p.state)
+	}
+	// The supervisor itself, and entries that aren't processes
+	writeProcess(t, procDir, 40, "linkerd2-proxy-", "R")
+	if err := os.Mkdir(filepath.Join(procDir, "self"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &supervisor{
+		procDir: procDir,
+		self:    40,
+		ignore:  map[string]struct{}{"pause": {}, "linkerd2-proxy": {}, "linkerd2-proxy-": {}, "tshark": {}},
+	}
+	count, err := s.workloadProcesses()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if count != 2 {
+		t.Fatalf("Expected 2 workload processes, got %d", count)
+	}
+
+	for _, pid := range []string{"31", "32"} {
+		if err := os.RemoveAll(filepath.Join(procDir, pid)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	count, err = s.workloadProcesses()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if count != 0 {
+		t.Fatalf("Expected no workload processes, got %d", count)
+	}
+}
+
+func TestWaitForWorkload(t *testing.T) {
+	procDir := t.TempDir()
+	writeProcess(t, procDir, 1, "pause", "S")
+	writeProcess(t, procDir, 7, "linkerd2-proxy", "S")
+	s := &supervisor{
+		procDir: procDir,
+		self:    40,
+		ignore:  map[string]struct{}{"pause": {}, "linkerd2-proxy": {}},
+	}
+
+	wait := func() {
+		t.Helper()
+		done := make(chan struct{})
+		go func() {
+			s.waitForWorkload(time.Millisecond)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the workload to terminate")
+		}
+	}
+
+	// A workload that terminated before the supervisor started
+	wait()
+
+	writeProcess(t, procDir, 31, "job", "R")
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		writeProcess(t, procDir, 31, "job", "Z")
+	}()
+	wait()
+}
+
+func writeProcess(t *testing.T, procDir string, pid int, comm, state string) {
+	t.Helper()
+	dir := filepath.Join(procDir, strconv.Itoa(pid))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Error(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "comm"), []byte(comm+"\n"), 0o600); err != nil {
+		t.Error(err)
+	}
+	stat := strconv.Itoa(pid) + " (" + comm + " x) " + state + " 1 1 1"
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o600); err != nil {
+		t.Error(err)
+	}
+}
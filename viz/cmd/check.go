@@ -13,10 +13,11 @@ import (
 )
 
 type checkOptions struct {
-	proxy     bool
-	wait      time.Duration
-	namespace string
-	output    string
+	proxy       bool
+	wait        time.Duration
+	namespace   string
+	output      string
+	checkConfig string
 }
 
 func newCheckOptions() *checkOptions {
@@ -27,8 +28,8 @@ func newCheckOptions() *checkOptions {
 }
 
 func (options *checkOptions) validate() error {
-	if options.output != healthcheck.TableOutput && options.output != healthcheck.JSONOutput && options.output != healthcheck.ShortOutput {
-		return fmt.Errorf("Invalid output type '%s'. Supported output types are: %s, %s, %s", options.output, healthcheck.JSONOutput, healthcheck.TableOutput, healthcheck.ShortOutput)
+	if options.output != healthcheck.TableOutput && options.output != healthcheck.JSONOutput && options.output != healthcheck.ShortOutput && !healthcheck.IsReportOutput(options.output) {
+		return fmt.Errorf("Invalid output type '%s'. Supported output types are: %s, %s, %s, %s, %s", options.output, healthcheck.JSONOutput, healthcheck.TableOutput, healthcheck.ShortOutput, healthcheck.JUnitOutput, healthcheck.SARIFOutput)
 	}
 	return nil
 }
@@ -54,10 +55,11 @@ code.`,
 		},
 	}
 
-	cmd.Flags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of: table, json, short")
+	cmd.Flags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of: table, json, short, junit, sarif")
 	cmd.Flags().BoolVar(&options.proxy, "proxy", options.proxy, "Also run data-plane checks, to determine if the data plane is healthy")
 	cmd.Flags().DurationVar(&options.wait, "wait", options.wait, "Maximum allowed time for all tests to pass")
 	cmd.Flags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace to use for --proxy checks (default: all namespaces)")
+	cmd.Flags().StringVar(&options.checkConfig, "check-config", options.checkConfig, fmt.Sprintf("Path to a YAML file enabling or disabling categories and checks, overriding their thresholds, and turning warnings into failures (default: $%s)", healthcheck.CheckConfigEnvVar))
 
 	pkgcmd.ConfigureNamespaceFlagCompletion(
 		cmd, []string{"namespace"},
@@ -71,6 +73,11 @@ func configureAndRunChecks(wout io.Writer, werr io.Writer, options *checkOptions
 		return fmt.Errorf("validation error when executing check command: %w", err)
 	}
 
+	checkConfig, err := healthcheck.LoadCheckConfigOrEnv(options.checkConfig)
+	if err != nil {
+		return err
+	}
+
 	hc := vizHealthCheck.NewHealthChecker([]healthcheck.CategoryID{}, &vizHealthCheck.VizOptions{
 		Options: &healthcheck.Options{
 			ControlPlaneNamespace: controlPlaneNamespace,
@@ -81,6 +88,7 @@ func configureAndRunChecks(wout io.Writer, werr io.Writer, options *checkOptions
 			APIAddr:               apiAddr,
 			RetryDeadline:         time.Now().Add(options.wait),
 			DataPlaneNamespace:    options.namespace,
+			CheckConfig:           checkConfig,
 		},
 		VizNamespaceOverride: vizNamespace,
 	})
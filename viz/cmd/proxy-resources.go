@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/linkerd/linkerd2/cli/table"
+	pkgcmd "github.com/linkerd/linkerd2/pkg/cmd"
+	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/viz/pkg/api"
+	hc "github.com/linkerd/linkerd2/viz/pkg/healthcheck"
+	"github.com/linkerd/linkerd2/viz/pkg/recommender"
+	pkgUtil "github.com/linkerd/linkerd2/viz/pkg/util"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+type proxyResourcesOptions struct {
+	namespace     string
+	outputFormat  string
+	timeWindow    time.Duration
+	prometheusURL string
+	recommender.Options
+}
+
+func newProxyResourcesOptions() *proxyResourcesOptions {
+	return &proxyResourcesOptions{
+		outputFormat: tableOutput,
+		timeWindow:   24 * time.Hour,
+		Options:      *recommender.NewOptions(),
+	}
+}
+
+func (o *proxyResourcesOptions) validate() error {
+	switch o.outputFormat {
+	case tableOutput, wideOutput, jsonOutput, yamlOutput:
+	default:
+		return fmt.Errorf("--output currently only supports %s, %s, %s and %s", tableOutput, wideOutput, jsonOutput, yamlOutput)
+	}
+	if o.timeWindow <= 0 {
+		return errors.New("--time-window must be positive")
+	}
+	if o.Margin < 0 {
+		return errors.New("--margin must not be negative")
+	}
+	return nil
+}
+
+// newCmdProxyResources creates a new cobra command `proxy-resources` that
+// recommends proxy resource requests and limits from their observed usage
+func newCmdProxyResources() *cobra.Command {
+	options := newProxyResourcesOptions()
+	var minCPU, minMemory string
+
+	cmd := &cobra.Command{
+		Use:   "proxy-resources [flags] (RESOURCE)",
+		Short: "Recommend proxy resource requests and limits from their observed usage",
+		Long: `Recommend proxy resource requests and limits from their observed usage.
+
+  The proxies' CPU and memory usage is read from Prometheus over the time
+  window. Requests cover the 95th percentile of the usage and limits cover its
+  maximum, both with the given margin on top.
+
+  The RESOURCE argument specifies the workloads to size, as TYPE or TYPE/NAME.
+
+  Valid resource types include:
+  * cronjobs
+  * daemonsets
+  * deployments
+  * jobs
+  * replicasets
+  * replicationcontrollers
+  * statefulsets
+
+  With "-o yaml", the recommendations are output as ProxyConfig resources
+  selecting each workload's pods. Once applied, the proxy injector uses them
+  to size the proxies of new pods, unless the pods' own annotations override
+  them.`,
+		Example: `  # Recommend resources for the proxies of all the deployments in the emojivoto namespace.
+  linkerd viz proxy-resources -n emojivoto deploy
+
+  # Size the web deployment's proxies from the last week of usage.
+  linkerd viz proxy-resources -n emojivoto deploy/web --time-window 168h -o yaml | kubectl apply -f -`,
+		Args: cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			k8sAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup, 0)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+
+			if options.namespace == "" {
+				options.namespace = pkgcmd.GetDefaultNamespace(kubeconfigPath, kubeContext)
+			}
+
+			cc := k8s.NewCommandCompletion(k8sAPI, options.namespace)
+
+			results, err := cc.Complete(args, toComplete)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+
+			return results, cobra.ShellCompDirectiveDefault
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if options.MinCPU, err = resource.ParseQuantity(minCPU); err != nil {
+				return fmt.Errorf("invalid --min-cpu: %w", err)
+			}
+			if options.MinMemory, err = resource.ParseQuantity(minMemory); err != nil {
+				return fmt.Errorf("invalid --min-memory: %w", err)
+			}
+			if err := options.validate(); err != nil {
+				return err
+			}
+
+			if options.namespace == "" {
+				options.namespace = pkgcmd.GetDefaultNamespace(kubeconfigPath, kubeContext)
+			}
+
+			res, err := pkgUtil.BuildResource(options.namespace, args[0])
+			if err != nil {
+				return err
+			}
+			if err := recommender.ValidateResource(res); err != nil {
+				return err
+			}
+
+			promAPI, err := api.NewPrometheusClient(cmd.Context(), hc.VizOptions{
+				Options: &healthcheck.Options{
+					ControlPlaneNamespace: controlPlaneNamespace,
+					KubeConfig:            kubeconfigPath,
+					Impersonate:           impersonate,
+					ImpersonateGroup:      impersonateGroup,
+					KubeContext:           kubeContext,
+					APIAddr:               apiAddr,
+				},
+				VizNamespaceOverride: vizNamespace,
+			}, options.prometheusURL)
+			if err != nil {
+				return err
+			}
+
+			usage, err := recommender.ObservedUsage(cmd.Context(), promAPI, res, controlPlaneNamespace, options.timeWindow)
+			if err != nil {
+				return err
+			}
+			return renderProxyResources(recommender.Recommend(usage, &options.Options), options.outputFormat, stdout)
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace of the specified resource")
+	cmd.PersistentFlags().StringVarP(&options.outputFormat, "output", "o", options.outputFormat, "Output format; one of: \"table\", \"wide\", \"json\" or \"yaml\"")
+	cmd.PersistentFlags().DurationVarP(&options.timeWindow, "time-window", "t", options.timeWindow, "Window of observed usage the recommendations are based on")
+	cmd.PersistentFlags().Float64Var(&options.Margin, "margin", options.Margin, "Fraction of the observed usage added on top of it")
+	cmd.PersistentFlags().StringVar(&minCPU, "min-cpu", options.MinCPU.String(), "Lowest CPU request and limit recommended")
+	cmd.PersistentFlags().StringVar(&minMemory, "min-memory", options.MinMemory.String(), "Lowest memory request and limit recommended")
+	cmd.PersistentFlags().StringVar(&options.prometheusURL, "prometheusURL", options.prometheusURL, "Address of Prometheus instance to query")
+
+	return cmd
+}
+
+func renderProxyResources(recommendations []recommender.Recommendation, outputFormat string, w io.Writer) error {
+	switch outputFormat {
+	case jsonOutput:
+		out, err := json.MarshalIndent(recommendations, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+
+	case yamlOutput:
+		for _, r := range recommendations {
+			out, err := yaml.Marshal(r.ProxyConfig())
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "---\n%s", out); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(recommendations) == 0 {
+		fmt.Fprintln(stderr, "No proxy usage found.")
+		return nil
+	}
+
+	columns := []table.Column{
+		table.NewColumn("NAMESPACE").WithLeftAlign(),
+		table.NewColumn("NAME").WithLeftAlign(),
+	}
+	if outputFormat == wideOutput {
+		columns = append(columns,
+			table.NewColumn("CPU_P95"),
+			table.NewColumn("CPU_MAX"),
+			table.NewColumn("MEM_P95"),
+			table.NewColumn("MEM_MAX"),
+		)
+	}
+	columns = append(columns,
+		table.NewColumn("CPU_REQUEST"),
+		table.NewColumn("CPU_LIMIT"),
+		table.NewColumn("MEM_REQUEST"),
+		table.NewColumn("MEM_LIMIT"),
+	)
+
+	rows := make([][]string, 0, len(recommendations))
+	for _, r := range recommendations {
+		row := []string{r.Namespace, fmt.Sprintf("%s/%s", r.Kind, r.Name)}
+		if outputFormat == wideOutput {
+			row = append(row,
+				fmt.Sprintf("%.0fm", r.Usage.CPUP95*1000),
+				fmt.Sprintf("%.0fm", r.Usage.CPUMax*1000),
+				fmt.Sprintf("%.1fMi", r.Usage.MemoryP95/(1024*1024)),
+				fmt.Sprintf("%.1fMi", r.Usage.MemoryMax/(1024*1024)),
+			)
+		}
+		row = append(row,
+			r.CPURequest.String(),
+			r.CPULimit.String(),
+			r.MemoryRequest.String(),
+			r.MemoryLimit.String(),
+		)
+		rows = append(rows, row)
+	}
+
+	t := table.NewTable(columns, rows)
+	t.Render(w)
+	return nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/viz/pkg/recommender"
+)
+
+func TestRenderProxyResources(t *testing.T) {
+	usage := map[recommender.Workload]*recommender.Usage{
+		{Namespace: "emojivoto", Kind: k8s.Deployment, Name: "web"}: {
+			CPUP95:    0.1,
+			CPUMax:    0.3,
+			MemoryP95: 40 * 1024 * 1024,
+			MemoryMax: 50 * 1024 * 1024,
+		},
+		{Namespace: "emojivoto", Kind: k8s.Deployment, Name: "emoji"}: {
+			CPUP95:    0.001,
+			CPUMax:    0.002,
+			MemoryP95: 5 * 1024 * 1024,
+			MemoryMax: 6 * 1024 * 1024,
+		},
+	}
+	recommendations := recommender.Recommend(usage, recommender.NewOptions())
+
+	testCases := []struct {
+		outputFormat string
+		file         string
+	}{
+		{tableOutput, "proxy_resources_output.golden"},
+		{wideOutput, "proxy_resources_output_wide.golden"},
+		{yamlOutput, "proxy_resources_output_yaml.golden"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.outputFormat, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := renderProxyResources(recommendations, tc.outputFormat, &buf); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			testDataDiffer.DiffTestdata(t, tc.file, buf.String())
+		})
+	}
+}
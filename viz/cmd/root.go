@@ -27,6 +27,7 @@ const (
 	tableOutput    = healthcheck.TableOutput
 	wideOutput     = healthcheck.WideOutput
 	jsonPathOutput = "jsonpath"
+	yamlOutput     = "yaml"
 )
 
 var (
@@ -88,6 +89,7 @@ func NewCmdViz() *cobra.Command {
 	vizCmd.AddCommand(newCmdInstall())
 	vizCmd.AddCommand(newCmdList())
 	vizCmd.AddCommand(newCmdProfile())
+	vizCmd.AddCommand(newCmdProxyResources())
 	vizCmd.AddCommand(NewCmdRoutes())
 	vizCmd.AddCommand(NewCmdStat())
 	vizCmd.AddCommand(NewCmdStatInbound())
@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/golang/protobuf/ptypes/duration"
 	netPb "github.com/linkerd/linkerd2/controller/gen/common/net"
@@ -43,6 +45,13 @@ type tapOptions struct {
 	path          string
 	output        string
 	labelSelector string
+	record        string
+	otlpEndpoint  string
+
+	// startTime, if set, is the start time of the exported requests, which
+	// defaults to the start of the recording when replaying one. Otherwise,
+	// requests start when they're received.
+	startTime time.Time
 }
 
 type endpoint struct {
@@ -122,6 +131,7 @@ func newTapOptions() *tapOptions {
 		path:          "",
 		output:        "",
 		labelSelector: "",
+		otlpEndpoint:  defaultOTLPEndpoint,
 	}
 }
 
@@ -138,13 +148,20 @@ func WithJsonPath(jsonPath string) renderOptions {
 }
 
 func (o *tapOptions) validate() error {
-	if o.output == "" || o.output == wideOutput || o.output == jsonOutput || strings.HasPrefix(o.output, jsonPathOutput) {
+	if o.output == "" || o.output == wideOutput || o.output == jsonOutput || strings.HasPrefix(o.output, jsonPathOutput) || isExportOutput(o.output) {
 		return nil
 	}
 
 	return fmt.Errorf("output format \"%s\" not recognized", o.output)
 }
 
+// extract returns whether the headers of the tapped requests are requested,
+// for the outputs rendering them and for recordings, which may be replayed
+// with any output.
+func (o *tapOptions) extract() bool {
+	return o.output == jsonOutput || isExportOutput(o.output) || o.record != ""
+}
+
 // NewCmdTap creates a new cobra command `tap` for tap functionality
 func NewCmdTap() *cobra.Command {
 	options := newTapOptions()
@@ -188,7 +205,16 @@ func NewCmdTap() *cobra.Command {
   linkerd viz tap pod/web-dlbvj
 
   # tap the test namespace, filter by request to prod namespace
-  linkerd viz tap ns/test --to ns/prod`,
+  linkerd viz tap ns/test --to ns/prod
+
+  # tap the web deployment, and record its events to replay them later
+  linkerd viz tap deploy/web --record web.tap
+
+  # tap the web deployment until interrupted, and save its requests as a HAR file
+  linkerd viz tap deploy/web -o har > web.har
+
+  # tap the web deployment, and send its requests as spans to a local OpenTelemetry collector
+  linkerd viz tap deploy/web -o otlp --otlp-endpoint http://localhost:4318/v1/traces`,
 		Args: cobra.RangeArgs(1, 2),
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			// This command requires at most two arguments if we already have
@@ -243,7 +269,7 @@ func NewCmdTap() *cobra.Command {
 				Method:        options.method,
 				Authority:     options.authority,
 				Path:          options.path,
-				Extract:       options.output == jsonOutput,
+				Extract:       options.extract(),
 				LabelSelector: options.labelSelector,
 			}
 
@@ -264,7 +290,15 @@ func NewCmdTap() *cobra.Command {
 				os.Exit(1)
 			}
 
-			err = requestTapByResourceFromAPI(cmd.Context(), os.Stdout, k8sAPI, req, options)
+			ctx := cmd.Context()
+			if isExportOutput(options.output) {
+				// Requests are exported until the tap is interrupted
+				var stop context.CancelFunc
+				ctx, stop = signal.NotifyContext(ctx, os.Interrupt)
+				defer stop()
+			}
+
+			err = requestTapByResourceFromAPI(ctx, os.Stdout, k8sAPI, req, options)
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err.Error())
 				os.Exit(1)
@@ -291,9 +325,15 @@ func NewCmdTap() *cobra.Command {
 	cmd.PersistentFlags().StringVar(&options.path, "path", options.path,
 		"Display requests with paths that start with this prefix")
 	cmd.PersistentFlags().StringVarP(&options.output, "output", "o", options.output,
-		fmt.Sprintf("Output format. One of: \"%s\", \"%s\", \"%s\"", wideOutput, jsonOutput, jsonPathOutput))
+		fmt.Sprintf("Output format. One of: \"%s\", \"%s\", \"%s\", \"%s\", \"%s\"", wideOutput, jsonOutput, jsonPathOutput, harOutput, otlpOutput))
 	cmd.PersistentFlags().StringVarP(&options.labelSelector, "selector", "l", options.labelSelector,
 		"Selector (label query) to filter on, supports '=', '==', and '!='")
+	cmd.PersistentFlags().StringVar(&options.otlpEndpoint, "otlp-endpoint", options.otlpEndpoint,
+		"OTLP/HTTP endpoint to send the requests to as spans, with the \"otlp\" output format")
+	cmd.Flags().StringVar(&options.record, "record", options.record,
+		"Record the tap events to this file, to replay them with \"linkerd viz tap replay\"")
+
+	cmd.AddCommand(newCmdTapReplay(options))
 
 	pkgcmd.ConfigureNamespaceFlagCompletion(
 		cmd, []string{"namespace", "to-namespace"},
@@ -308,10 +348,23 @@ func requestTapByResourceFromAPI(ctx context.Context, w io.Writer, k8sAPI *k8s.K
 	}
 	defer body.Close()
 
-	return writeTapEventsToBuffer(w, reader, options)
+	if options.record != "" {
+		file, err := os.Create(options.record)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		reader, err = pkg.Record(file, req, time.Now(), reader)
+		if err != nil {
+			return fmt.Errorf("failed to record the tap events: %w", err)
+		}
+	}
+
+	return writeTapEventsToBuffer(ctx, w, reader, options)
 }
 
-func writeTapEventsToBuffer(w io.Writer, tapByteStream *bufio.Reader, options *tapOptions) error {
+func writeTapEventsToBuffer(ctx context.Context, w io.Writer, tapByteStream *bufio.Reader, options *tapOptions) error {
 	output := options.output
 
 	switch {
@@ -327,6 +380,10 @@ func writeTapEventsToBuffer(w io.Writer, tapByteStream *bufio.Reader, options *t
 			return err
 		}
 		return renderTapEvents(tapByteStream, w, renderTapEventJSON, WithJsonPath(jPathFilter))
+	case output == harOutput:
+		return writeTapEventsHAR(ctx, tapByteStream, w, options.startTime)
+	case output == otlpOutput:
+		return exportTapEventsOTLP(ctx, tapByteStream, w, options.otlpEndpoint, options.startTime)
 	default:
 		return fmt.Errorf("unknown output format: %q", options.output)
 	}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/addr"
+	"github.com/linkerd/linkerd2/pkg/protohttp"
+	metricsPb "github.com/linkerd/linkerd2/viz/metrics-api/gen/viz"
+	tapPb "github.com/linkerd/linkerd2/viz/tap/gen/tap"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	harOutput  = "har"
+	otlpOutput = "otlp"
+)
+
+// tapRequest is an HTTP request assembled from its tap events, to be exported
+type tapRequest struct {
+	// startTime is when the RequestInit event was received or, when
+	// replaying a recording, when the recording started
+	startTime time.Time
+	event     *tapPb.TapEvent
+	reqInit   *tapPb.TapEvent_Http_RequestInit
+	rspInit   *tapPb.TapEvent_Http_ResponseInit
+	rspEnd    *tapPb.TapEvent_Http_ResponseEnd
+}
+
+// isExportOutput returns whether output exports whole requests, which are
+// assembled from their events, with their headers.
+func isExportOutput(output string) bool {
+	return output == harOutput || output == otlpOutput
+}
+
+// exportTapRequests reads the events of tapByteStream, and calls export with
+// each request whose ResponseEnd event is received. The requests start at
+// startTime if it's set, or when their RequestInit event is received. The
+// stream ends at EOF, or when ctx is done, e.g. when interrupted.
+func exportTapRequests(ctx context.Context, tapByteStream *bufio.Reader, startTime time.Time, export func(*tapRequest) error) error {
+	outstanding := make(map[topRequestID]*tapRequest)
+	for {
+		event := &tapPb.TapEvent{}
+		err := protohttp.FromByteStreamToProtocolBuffers(tapByteStream, event)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || ctx.Err() != nil {
+				return nil
+			}
+			fmt.Fprintln(os.Stderr, err)
+			return nil
+		}
+
+		id := topRequestID{
+			src: addr.PublicAddressToString(event.GetSource()),
+			dst: addr.PublicAddressToString(event.GetDestination()),
+		}
+		switch ev := event.GetHttp().GetEvent().(type) {
+		case *tapPb.TapEvent_Http_RequestInit_:
+			id.stream = ev.RequestInit.GetId().GetStream()
+			start := startTime
+			if start.IsZero() {
+				start = time.Now()
+			}
+			outstanding[id] = &tapRequest{
+				startTime: start,
+				event:     event,
+				reqInit:   ev.RequestInit,
+			}
+
+		case *tapPb.TapEvent_Http_ResponseInit_:
+			id.stream = ev.ResponseInit.GetId().GetStream()
+			if req, ok := outstanding[id]; ok {
+				req.rspInit = ev.ResponseInit
+			} else {
+				log.Debugf("Got ResponseInit for unknown stream: %s", id)
+			}
+
+		case *tapPb.TapEvent_Http_ResponseEnd_:
+			id.stream = ev.ResponseEnd.GetId().GetStream()
+			req, ok := outstanding[id]
+			if !ok {
+				log.Debugf("Got ResponseEnd for unknown stream: %s", id)
+				continue
+			}
+			delete(outstanding, id)
+			req.rspEnd = ev.ResponseEnd
+			if err := export(req); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// url returns the URL of the request.
+func (r *tapRequest) url() string {
+	scheme := strings.ToLower(formatScheme(r.reqInit.GetScheme()))
+	if scheme == "" {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, r.reqInit.GetAuthority(), r.reqInit.GetPath())
+}
+
+// duration returns the time between the RequestInit and ResponseEnd events.
+func (r *tapRequest) duration() time.Duration {
+	return r.rspEnd.GetSinceRequestInit().AsDuration()
+}
+
+// failed returns whether the request failed, with an HTTP server error, a
+// gRPC error or a reset stream.
+func (r *tapRequest) failed() bool {
+	if r.rspInit.GetHttpStatus() >= 500 {
+		return true
+	}
+	switch eos := r.rspEnd.GetEos().GetEnd().(type) {
+	case *metricsPb.Eos_GrpcStatusCode:
+		return eos.GrpcStatusCode != 0
+	case *metricsPb.Eos_ResetErrorCode:
+		return true
+	}
+	return false
+}
+
+// headerValues returns the headers of hs as strings, with their binary values
+// unquoted.
+func headerValues(hs *metricsPb.Headers) [][2]string {
+	var values [][2]string
+	for _, h := range hs.GetHeaders() {
+		switch v := h.GetValue().(type) {
+		case *metricsPb.Headers_Header_ValueStr:
+			values = append(values, [2]string{h.GetName(), v.ValueStr})
+		case *metricsPb.Headers_Header_ValueBin:
+			values = append(values, [2]string{h.GetName(), string(v.ValueBin)})
+		}
+	}
+	return values
+}
+
+// headerValue returns the value of the first header of hs named name.
+func headerValue(hs *metricsPb.Headers, name string) string {
+	for _, h := range headerValues(hs) {
+		if strings.EqualFold(h[0], name) {
+			return h[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/addr"
+	"github.com/linkerd/linkerd2/pkg/version"
+	metricsPb "github.com/linkerd/linkerd2/viz/metrics-api/gen/viz"
+)
+
+// harVersion is the version of the HAR format, as specified by
+// http://www.softwareishard.com/blog/har-12-spec/
+const harVersion = "1.2"
+
+// The HAR types only hold the fields known from tap events. The sizes which are
+// unknown are set to -1, as required by the spec.
+type (
+	har struct {
+		Log harLog `json:"log"`
+	}
+
+	harLog struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	}
+
+	harCreator struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	harEntry struct {
+		StartedDateTime string      `json:"startedDateTime"`
+		Time            float64     `json:"time"`
+		Request         harRequest  `json:"request"`
+		Response        harResponse `json:"response"`
+		Cache           struct{}    `json:"cache"`
+		Timings         harTimings  `json:"timings"`
+		ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+		Comment         string      `json:"comment,omitempty"`
+	}
+
+	harRequest struct {
+		Method      string         `json:"method"`
+		URL         string         `json:"url"`
+		HTTPVersion string         `json:"httpVersion"`
+		Cookies     []harNameValue `json:"cookies"`
+		Headers     []harNameValue `json:"headers"`
+		QueryString []harNameValue `json:"queryString"`
+		HeadersSize int            `json:"headersSize"`
+		BodySize    int            `json:"bodySize"`
+	}
+
+	harResponse struct {
+		Status      uint32         `json:"status"`
+		StatusText  string         `json:"statusText"`
+		HTTPVersion string         `json:"httpVersion"`
+		Cookies     []harNameValue `json:"cookies"`
+		Headers     []harNameValue `json:"headers"`
+		Content     harContent     `json:"content"`
+		RedirectURL string         `json:"redirectURL"`
+		HeadersSize int            `json:"headersSize"`
+		BodySize    int64          `json:"bodySize"`
+	}
+
+	harNameValue struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	harContent struct {
+		Size     int64  `json:"size"`
+		MimeType string `json:"mimeType"`
+	}
+
+	harTimings struct {
+		Send    float64 `json:"send"`
+		Wait    float64 `json:"wait"`
+		Receive float64 `json:"receive"`
+	}
+)
+
+// writeTapEventsHAR writes the HTTP requests of tapByteStream into w, as a
+// HAR document, once the stream ends.
+func writeTapEventsHAR(ctx context.Context, tapByteStream *bufio.Reader, w io.Writer, startTime time.Time) error {
+	doc := har{Log: harLog{
+		Version: harVersion,
+		Creator: harCreator{Name: "linkerd viz tap", Version: version.Version},
+		Entries: []harEntry{},
+	}}
+	err := exportTapRequests(ctx, tapByteStream, startTime, func(req *tapRequest) error {
+		doc.Log.Entries = append(doc.Log.Entries, toHAREntry(req))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+func toHAREntry(req *tapRequest) harEntry {
+	// The wait is the time to the first byte of the response, and the rest of
+	// the request is spent receiving it
+	wait := req.rspInit.GetSinceRequestInit().AsDuration()
+	receive := req.rspEnd.GetSinceResponseInit().AsDuration()
+	if req.rspInit == nil {
+		wait = req.duration()
+		receive = 0
+	}
+
+	status := req.rspInit.GetHttpStatus()
+	return harEntry{
+		StartedDateTime: req.startTime.UTC().Format(time.RFC3339Nano),
+		Time:            milliseconds(req.duration()),
+		Request: harRequest{
+			Method:      formatMethod(req.reqInit.GetMethod()),
+			URL:         req.url(),
+			HTTPVersion: "",
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(req.reqInit.GetHeaders()),
+			QueryString: harQueryString(req.reqInit.GetPath()),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Response: harResponse{
+			Status:      status,
+			StatusText:  http.StatusText(int(status)),
+			HTTPVersion: "",
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(req.rspInit.GetHeaders()),
+			Content: harContent{
+				Size:     int64(req.rspEnd.GetResponseBytes()),
+				MimeType: headerValue(req.rspInit.GetHeaders(), "content-type"),
+			},
+			HeadersSize: -1,
+			BodySize:    int64(req.rspEnd.GetResponseBytes()),
+		},
+		Timings: harTimings{
+			Send:    0,
+			Wait:    milliseconds(wait),
+			Receive: milliseconds(receive),
+		},
+		ServerIPAddress: addr.PublicIPToString(req.event.GetDestination().GetIp()),
+		Comment:         req.event.GetProxyDirection().String() + " " + addr.PublicAddressToString(req.event.GetSource()) + " -> " + addr.PublicAddressToString(req.event.GetDestination()),
+	}
+}
+
+func harHeaders(hs *metricsPb.Headers) []harNameValue {
+	headers := []harNameValue{}
+	for _, h := range headerValues(hs) {
+		headers = append(headers, harNameValue{Name: h[0], Value: h[1]})
+	}
+	return headers
+}
+
+func harQueryString(path string) []harNameValue {
+	query := []harNameValue{}
+	u, err := url.Parse(path)
+	if err != nil {
+		return query
+	}
+	return append(query, splitQuery(u.RawQuery)...)
+}
+
+// splitQuery returns the parameters of query in order, unlike url.ParseQuery.
+func splitQuery(query string) []harNameValue {
+	var params []harNameValue
+	for query != "" {
+		var param string
+		param, query, _ = strings.Cut(query, "&")
+		if param == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+		params = append(params, harNameValue{Name: name, Value: value})
+	}
+	return params
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
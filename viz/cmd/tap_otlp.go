@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/addr"
+	"github.com/linkerd/linkerd2/pkg/version"
+	metricsPb "github.com/linkerd/linkerd2/viz/metrics-api/gen/viz"
+	tapPb "github.com/linkerd/linkerd2/viz/tap/gen/tap"
+)
+
+const (
+	// defaultOTLPEndpoint is the OTLP/HTTP traces endpoint of a local
+	// OpenTelemetry collector
+	defaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+
+	// otlpBatchSize is the maximum number of spans sent at once
+	otlpBatchSize = 100
+
+	// otlpFlushInterval is the maximum time spans are buffered for, as long
+	// as events are received
+	otlpFlushInterval = time.Second
+
+	otlpServiceName = "linkerd-tap"
+
+	// The kinds and status codes of the spans
+	otlpSpanKindServer  = 2
+	otlpSpanKindClient  = 3
+	otlpStatusCodeOk    = 1
+	otlpStatusCodeError = 2
+)
+
+// The OTLP types hold the spans sent to a collector, with the JSON encoding of
+// the OTLP/HTTP protocol, as specified by
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type (
+	otlpTraces struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes"`
+		Status            otlpStatus      `json:"status"`
+	}
+
+	otlpAttribute struct {
+		Key   string             `json:"key"`
+		Value otlpAttributeValue `json:"value"`
+	}
+
+	// otlpAttributeValue holds either a string or an integer, which are
+	// encoded as strings in JSON
+	otlpAttributeValue struct {
+		StringValue *string `json:"stringValue,omitempty"`
+		IntValue    *string `json:"intValue,omitempty"`
+	}
+
+	otlpStatus struct {
+		Code int `json:"code,omitempty"`
+	}
+
+	// otlpExporter sends spans in batches to an OTLP/HTTP endpoint
+	otlpExporter struct {
+		ctx       context.Context
+		client    *http.Client
+		endpoint  string
+		spans     map[string][]otlpSpan
+		buffered  int
+		exported  int
+		lastFlush time.Time
+	}
+)
+
+// exportTapEventsOTLP sends the HTTP requests of tapByteStream as spans to the
+// OTLP/HTTP endpoint, and writes a summary of the export into w once the
+// stream ends.
+func exportTapEventsOTLP(ctx context.Context, tapByteStream *bufio.Reader, w io.Writer, endpoint string, startTime time.Time) error {
+	exporter := &otlpExporter{
+		ctx:       ctx,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		endpoint:  endpoint,
+		spans:     map[string][]otlpSpan{},
+		lastFlush: time.Now(),
+	}
+	err := exportTapRequests(ctx, tapByteStream, startTime, exporter.export)
+	if err != nil {
+		return err
+	}
+	// The events stream may have ended because ctx is done
+	exporter.ctx = context.Background()
+	if err := exporter.flush(); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "Exported %d spans to %s\n", exporter.exported, endpoint)
+	return err
+}
+
+func (e *otlpExporter) export(req *tapRequest) error {
+	service, span := toOTLPSpan(req)
+	e.spans[service] = append(e.spans[service], span)
+	e.buffered++
+	if e.buffered >= otlpBatchSize || time.Since(e.lastFlush) >= otlpFlushInterval {
+		return e.flush()
+	}
+	return nil
+}
+
+// flush sends the buffered spans, grouped by service.
+func (e *otlpExporter) flush() error {
+	e.lastFlush = time.Now()
+	if e.buffered == 0 {
+		return nil
+	}
+
+	services := make([]string, 0, len(e.spans))
+	for service := range e.spans {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	traces := otlpTraces{}
+	for _, service := range services {
+		traces.ResourceSpans = append(traces.ResourceSpans, otlpResourceSpans{
+			Resource: otlpResource{Attributes: []otlpAttribute{stringAttribute("service.name", service)}},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "linkerd viz tap", Version: version.Version},
+				Spans: e.spans[service],
+			}},
+		})
+	}
+	body, err := json.Marshal(traces)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(e.ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export spans: %w", err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to export spans: %s returned %s", e.endpoint, rsp.Status)
+	}
+
+	e.exported += e.buffered
+	e.buffered = 0
+	e.spans = map[string][]otlpSpan{}
+	return nil
+}
+
+// toOTLPSpan converts req to a span of the tapped service, which is the source
+// of outbound requests and the destination of inbound ones. The span is part
+// of the trace propagated with the W3C traceparent header, if any.
+func toOTLPSpan(req *tapRequest) (string, otlpSpan) {
+	event := req.event
+	kind := otlpSpanKindClient
+	labels := event.GetSourceMeta().GetLabels()
+	if event.GetProxyDirection() == tapPb.TapEvent_INBOUND {
+		kind = otlpSpanKindServer
+		labels = event.GetDestinationMeta().GetLabels()
+	}
+
+	traceID, parentSpanID := parseTraceparent(headerValue(req.reqInit.GetHeaders(), "traceparent"))
+	if traceID == "" {
+		traceID = randomID(16)
+	}
+
+	method := formatMethod(req.reqInit.GetMethod())
+	name := method
+	if route := event.GetRouteMeta().GetLabels()["route"]; route != "" {
+		name += " " + route
+	}
+
+	attributes := []otlpAttribute{
+		stringAttribute("http.request.method", method),
+		stringAttribute("url.full", req.url()),
+		stringAttribute("url.path", req.reqInit.GetPath()),
+		stringAttribute("server.address", req.reqInit.GetAuthority()),
+		stringAttribute("client.address", addr.PublicAddressToString(event.GetSource())),
+		stringAttribute("network.peer.address", addr.PublicAddressToString(event.GetDestination())),
+		stringAttribute("linkerd.proxy_direction", event.GetProxyDirection().String()),
+		intAttribute("http.response.body.size", int64(req.rspEnd.GetResponseBytes())),
+	}
+	if req.rspInit != nil {
+		attributes = append(attributes, intAttribute("http.response.status_code", int64(req.rspInit.GetHttpStatus())))
+	}
+	switch eos := req.rspEnd.GetEos().GetEnd().(type) {
+	case *metricsPb.Eos_GrpcStatusCode:
+		attributes = append(attributes, intAttribute("rpc.grpc.status_code", int64(eos.GrpcStatusCode)))
+	case *metricsPb.Eos_ResetErrorCode:
+		attributes = append(attributes, intAttribute("linkerd.reset_error_code", int64(eos.ResetErrorCode)))
+	}
+	for _, meta := range []struct {
+		prefix string
+		labels map[string]string
+	}{
+		{"linkerd.src.", event.GetSourceMeta().GetLabels()},
+		{"linkerd.dst.", event.GetDestinationMeta().GetLabels()},
+	} {
+		keys := make([]string, 0, len(meta.labels))
+		for k := range meta.labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			attributes = append(attributes, stringAttribute(meta.prefix+k, meta.labels[k]))
+		}
+	}
+
+	status := otlpStatus{Code: otlpStatusCodeOk}
+	if req.failed() {
+		status.Code = otlpStatusCodeError
+	}
+
+	return workloadName(labels), otlpSpan{
+		TraceID:           traceID,
+		SpanID:            randomID(8),
+		ParentSpanID:      parentSpanID,
+		Name:              name,
+		Kind:              kind,
+		StartTimeUnixNano: strconv.FormatInt(req.startTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(req.startTime.Add(req.duration()).UnixNano(), 10),
+		Attributes:        attributes,
+		Status:            status,
+	}
+}
+
+// workloadName returns the name of the workload of a tapped endpoint, as
+// <name>.<namespace>, from the labels of its tap metadata.
+func workloadName(labels map[string]string) string {
+	for _, kind := range []string{"deployment", "statefulset", "daemonset", "job", "cronjob", "replicationcontroller", "replicaset", "pod"} {
+		if name := labels[kind]; name != "" {
+			if ns := labels["namespace"]; ns != "" {
+				return name + "." + ns
+			}
+			return name
+		}
+	}
+	return otlpServiceName
+}
+
+// parseTraceparent returns the trace ID and the parent span ID of a W3C
+// traceparent header, or empty strings if it's invalid.
+func parseTraceparent(traceparent string) (string, string) {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", ""
+	}
+	if _, err := hex.DecodeString(parts[1] + parts[2]); err != nil {
+		return "", ""
+	}
+	return parts[1], parts[2]
+}
+
+func randomID(size int) string {
+	id := make([]byte, size)
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpAttributeValue{StringValue: &value}}
+}
+
+func intAttribute(key string, value int64) otlpAttribute {
+	s := strconv.FormatInt(value, 10)
+	return otlpAttribute{Key: key, Value: otlpAttributeValue{IntValue: &s}}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	tapPb "github.com/linkerd/linkerd2/viz/tap/gen/tap"
+	"github.com/linkerd/linkerd2/viz/tap/pkg"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newCmdTapReplay creates a new cobra command `tap replay`, which renders the
+// events of a recording with the output format and filters of options, shared
+// with the `tap` command
+func newCmdTapReplay(options *tapOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay [flags] FILE",
+		Short: "Replay a recorded traffic stream",
+		Long: `Replay a recorded traffic stream.
+
+  The FILE argument is a recording made with "linkerd viz tap --record". Its
+  events are rendered with the same output formats as the tap command, and the
+  --to, --to-namespace, --scheme, --method, --authority and --path filters are
+  applied to them.
+
+  The recordings don't hold the time of each event, so the requests exported
+  with the "har" and "otlp" output formats all start when the recording
+  started.`,
+		Example: `  # replay the events recorded from the web deployment
+  linkerd viz tap replay web.tap
+
+  # replay the GET requests of a recording, in JSON
+  linkerd viz tap replay web.tap --method GET -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := options.validate()
+			if err != nil {
+				return fmt.Errorf("validation error when executing tap replay command: %w", err)
+			}
+
+			return replayTapEvents(cmd.Context(), os.Stdout, args[0], options)
+		},
+	}
+
+	return cmd
+}
+
+// replayTapEvents renders the events of the recording at path into w.
+func replayTapEvents(ctx context.Context, w io.Writer, path string, options *tapOptions) error {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	recording, err := pkg.ReadRecording(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	log.Debugf("Replaying the tap events recorded since %s", recording.StartTime)
+
+	match, err := buildReplayMatch(recording, pkg.TapRequestParams{
+		ToResource:  options.toResource,
+		ToNamespace: options.toNamespace,
+		Scheme:      options.scheme,
+		Method:      options.method,
+		Authority:   options.authority,
+		Path:        options.path,
+	})
+	if err != nil {
+		return err
+	}
+
+	replayOptions := *options
+	if replayOptions.startTime.IsZero() {
+		replayOptions.startTime = recording.StartTime
+	}
+	return writeTapEventsToBuffer(ctx, w, pkg.FilterEvents(recording.Events, match), &replayOptions)
+}
+
+// buildReplayMatch builds the match of the filters of params, to be applied to
+// the events of recording. The --to resource defaults to the namespace of the
+// recorded resource.
+func buildReplayMatch(recording *pkg.Recording, params pkg.TapRequestParams) (*tapPb.TapByResourceRequest_Match, error) {
+	target := recording.Request.GetTarget().GetResource()
+	params.Resource = target.GetType()
+	if target.GetName() != "" {
+		params.Resource += "/" + target.GetName()
+	}
+	params.Namespace = target.GetNamespace()
+	if params.ToNamespace == "" {
+		params.ToNamespace = target.GetNamespace()
+	}
+
+	req, err := pkg.BuildTapByResourceRequest(params)
+	if err != nil {
+		return nil, err
+	}
+	return req.GetMatch(), nil
+}
@@ -1,14 +1,20 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-test/deep"
 	"github.com/golang/protobuf/ptypes/duration"
 	netPb "github.com/linkerd/linkerd2/controller/gen/common/net"
 	"github.com/linkerd/linkerd2/pkg/addr"
@@ -18,6 +24,7 @@ import (
 	tapPb "github.com/linkerd/linkerd2/viz/tap/gen/tap"
 	"github.com/linkerd/linkerd2/viz/tap/pkg"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
 )
 
 const targetName = "pod-666"
@@ -133,6 +140,8 @@ func busyTest(t *testing.T, output string) {
 
 	options := newTapOptions()
 	options.output = output
+	options.record = filepath.Join(t.TempDir(), "busy.tap")
+	options.startTime = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
 
 	writer := bytes.NewBufferString("")
 	err = requestTapByResourceFromAPI(context.Background(), writer, kubeAPI, req, options)
@@ -148,6 +157,8 @@ func busyTest(t *testing.T, output string) {
 		goldenFilePath = "testdata/tap_busy_output_json.golden"
 	case strings.HasPrefix(options.output, jsonPathOutput):
 		goldenFilePath = "testdata/tap_busy_output_jsonpath.golden"
+	case options.output == harOutput:
+		goldenFilePath = "testdata/tap_busy_output_har.golden"
 	default:
 		goldenFilePath = "testdata/tap_busy_output.golden"
 	}
@@ -161,6 +172,37 @@ func busyTest(t *testing.T, output string) {
 	if expectedContent != actual {
 		t.Fatalf("Expected function to render:\n%s\bbut got:\n%s", expectedContent, actual)
 	}
+
+	// Replaying the recording with the filters of the request renders the
+	// same events
+	options.scheme = params.Scheme
+	options.method = params.Method
+	options.authority = params.Authority
+	options.path = params.Path
+	writer.Reset()
+	err = replayTapEvents(context.Background(), writer, options.record, options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if actual := writer.String(); expectedContent != actual {
+		t.Fatalf("Expected replay to render:\n%s\bbut got:\n%s", expectedContent, actual)
+	}
+
+	// Filtering out the request also filters out its response
+	options.path = "/other/path"
+	writer.Reset()
+	err = replayTapEvents(context.Background(), writer, options.record, options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	empty := bytes.NewBufferString("")
+	err = writeTapEventsToBuffer(context.Background(), empty, bufio.NewReader(empty), options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if actual := writer.String(); actual != empty.String() {
+		t.Fatalf("Expected replay to render:\n%s\bbut got:\n%s", empty.String(), actual)
+	}
 }
 
 func TestRequestTapByResourceFromAPI(t *testing.T) {
@@ -182,6 +224,10 @@ func TestRequestTapByResourceFromAPI(t *testing.T) {
 		busyTest(t, "jsonpath={.source}")
 	})
 
+	t.Run("Should render HAR busy response if everything went well", func(t *testing.T) {
+		busyTest(t, "har")
+	})
+
 	t.Run("Should render empty response if no events returned", func(t *testing.T) {
 		resourceType := k8s.Pod
 		params := pkg.TapRequestParams{
@@ -255,6 +301,31 @@ func TestRequestTapByResourceFromAPI(t *testing.T) {
 	})
 }
 
+func TestTapOptionsExtract(t *testing.T) {
+	testCases := []struct {
+		output   string
+		record   string
+		expected bool
+	}{
+		{output: "", expected: false},
+		{output: wideOutput, expected: false},
+		{output: jsonOutput, expected: true},
+		{output: harOutput, expected: true},
+		{output: otlpOutput, expected: true},
+		{output: "", record: "web.tap", expected: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // pin
+		options := newTapOptions()
+		options.output = tc.output
+		options.record = tc.record
+		if extract := options.extract(); extract != tc.expected {
+			t.Errorf("Expected extract to be %t with output %q and record %q, got %t", tc.expected, tc.output, tc.record, extract)
+		}
+	}
+}
+
 func TestEventToString(t *testing.T) {
 	toTapEvent := func(httpEvent *tapPb.TapEvent_Http) *tapPb.TapEvent {
 		streamID := &tapPb.TapEvent_Http_StreamId{
@@ -420,3 +491,124 @@ func TestEventToString(t *testing.T) {
 		}
 	})
 }
+
+func TestExportTapEventsOTLP(t *testing.T) {
+	labels := map[string]string{"deployment": "web", "namespace": "emojivoto"}
+	streamID := &tapPb.TapEvent_Http_StreamId{Base: 1, Stream: 2}
+	events := []*tapPb.TapEvent{
+		pkg.CreateTapEvent(&tapPb.TapEvent_Http{
+			Event: &tapPb.TapEvent_Http_RequestInit_{
+				RequestInit: &tapPb.TapEvent_Http_RequestInit{
+					Id: streamID,
+					Method: &metricsPb.HttpMethod{
+						Type: &metricsPb.HttpMethod_Registered_{Registered: metricsPb.HttpMethod_POST},
+					},
+					Authority: "web.emojivoto.svc.cluster.local",
+					Path:      "/api/vote",
+					Headers: &metricsPb.Headers{Headers: []*metricsPb.Headers_Header{{
+						Name:  "traceparent",
+						Value: &metricsPb.Headers_Header_ValueStr{ValueStr: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+					}}},
+				},
+			},
+		}, labels, tapPb.TapEvent_INBOUND),
+		pkg.CreateTapEvent(&tapPb.TapEvent_Http{
+			Event: &tapPb.TapEvent_Http_ResponseInit_{
+				ResponseInit: &tapPb.TapEvent_Http_ResponseInit{
+					Id:               streamID,
+					SinceRequestInit: &duration.Duration{Nanos: 1000000},
+					HttpStatus:       500,
+				},
+			},
+		}, labels, tapPb.TapEvent_INBOUND),
+		pkg.CreateTapEvent(&tapPb.TapEvent_Http{
+			Event: &tapPb.TapEvent_Http_ResponseEnd_{
+				ResponseEnd: &tapPb.TapEvent_Http_ResponseEnd{
+					Id:               streamID,
+					SinceRequestInit: &duration.Duration{Nanos: 3000000},
+					ResponseBytes:    42,
+				},
+			},
+		}, labels, tapPb.TapEvent_INBOUND),
+	}
+	var stream []byte
+	for _, event := range events {
+		data, err := proto.Marshal(event)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		stream = append(stream, protohttp.SerializeAsPayload(data)...)
+	}
+
+	// A stand-in for the OTLP/HTTP receiver of a collector
+	var received []otlpTraces
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var traces otlpTraces
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&traces); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, traces)
+	}))
+	defer collector.Close()
+
+	endpoint := collector.URL + "/v1/traces"
+	startTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var out bytes.Buffer
+	err := exportTapEventsOTLP(context.Background(), bufio.NewReader(bytes.NewReader(stream)), &out, endpoint, startTime)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "Exported 1 spans to " + endpoint + "\n"; out.String() != expected {
+		t.Errorf("Expected output %q, got %q", expected, out.String())
+	}
+
+	if len(received) != 1 || len(received[0].ResourceSpans) != 1 {
+		t.Fatalf("Expected one batch of spans of one service, got %+v", received)
+	}
+	resourceSpans := received[0].ResourceSpans[0]
+	if service := *resourceSpans.Resource.Attributes[0].Value.StringValue; service != "web.emojivoto" {
+		t.Errorf("Expected service web.emojivoto, got %s", service)
+	}
+	span := resourceSpans.ScopeSpans[0].Spans[0]
+	expected := otlpSpan{
+		TraceID:           "0af7651916cd43dd8448eb211c80319c",
+		SpanID:            span.SpanID,
+		ParentSpanID:      "b7ad6b7169203331",
+		Name:              "POST",
+		Kind:              otlpSpanKindServer,
+		StartTimeUnixNano: strconv.FormatInt(startTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(startTime.Add(3*time.Millisecond).UnixNano(), 10),
+		Attributes:        span.Attributes,
+		Status:            otlpStatus{Code: otlpStatusCodeError},
+	}
+	if diff := deep.Equal(span, expected); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+	if len(span.SpanID) != 16 {
+		t.Errorf("Expected a 16 hex digits span ID, got %s", span.SpanID)
+	}
+	attributes := map[string]string{}
+	for _, attribute := range span.Attributes {
+		if attribute.Value.IntValue != nil {
+			attributes[attribute.Key] = *attribute.Value.IntValue
+		} else {
+			attributes[attribute.Key] = *attribute.Value.StringValue
+		}
+	}
+	for key, value := range map[string]string{
+		"http.request.method":       "POST",
+		"url.full":                  "http://web.emojivoto.svc.cluster.local/api/vote",
+		"http.response.status_code": "500",
+		"http.response.body.size":   "42",
+		"linkerd.dst.deployment":    "web",
+	} {
+		if attributes[key] != value {
+			t.Errorf("Expected attribute %s to be %q, got %q", key, value, attributes[key])
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -42,6 +44,7 @@ type topOptions struct {
 	hideSources   bool
 	routes        bool
 	labelSelector string
+	fromFile      string
 }
 
 type topRequest struct {
@@ -275,6 +278,7 @@ func newTopOptions() *topOptions {
 		hideSources:   false,
 		routes:        false,
 		labelSelector: "",
+		fromFile:      "",
 	}
 }
 
@@ -320,8 +324,16 @@ func NewCmdTop() *cobra.Command {
   linkerd viz top deploy/web
 
   # display traffic for the web-dlbvj pod in the default namespace
-  linkerd viz top pod/web-dlbvj`,
-		Args: cobra.RangeArgs(1, 2),
+  linkerd viz top pod/web-dlbvj
+
+  # display the traffic recorded with "linkerd viz tap --record"
+  linkerd viz top --from-file web.tap`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if options.fromFile != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.RangeArgs(1, 2)(cmd, args)
+		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			// This command requires at most two arguments if we already have
 			// two after requesting autocompletion i.e. [tab][tab]
@@ -353,17 +365,19 @@ func NewCmdTop() *cobra.Command {
 				options.namespace = pkgcmd.GetDefaultNamespace(kubeconfigPath, kubeContext)
 			}
 
-			api.CheckClientOrExit(hc.VizOptions{
-				Options: &healthcheck.Options{
-					ControlPlaneNamespace: controlPlaneNamespace,
-					KubeConfig:            kubeconfigPath,
-					Impersonate:           impersonate,
-					ImpersonateGroup:      impersonateGroup,
-					KubeContext:           kubeContext,
-					APIAddr:               apiAddr,
-				},
-				VizNamespaceOverride: vizNamespace,
-			})
+			if options.fromFile == "" {
+				api.CheckClientOrExit(hc.VizOptions{
+					Options: &healthcheck.Options{
+						ControlPlaneNamespace: controlPlaneNamespace,
+						KubeConfig:            kubeconfigPath,
+						Impersonate:           impersonate,
+						ImpersonateGroup:      impersonateGroup,
+						KubeContext:           kubeContext,
+						APIAddr:               apiAddr,
+					},
+					VizNamespaceOverride: vizNamespace,
+				})
+			}
 
 			requestParams := pkg.TapRequestParams{
 				Resource:      strings.Join(args, "/"),
@@ -392,6 +406,10 @@ func NewCmdTop() *cobra.Command {
 				table.columns[routeColumn].display = true
 			}
 
+			if options.fromFile != "" {
+				return getTrafficFromFile(options.fromFile, requestParams, table)
+			}
+
 			req, err := pkg.BuildTapByResourceRequest(requestParams)
 			if err != nil {
 				return err
@@ -425,6 +443,7 @@ func NewCmdTop() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(&options.hideSources, "hide-sources", options.hideSources, "Hide the source column")
 	cmd.PersistentFlags().BoolVar(&options.routes, "routes", options.routes, "Display data per route instead of per path")
 	cmd.PersistentFlags().StringVarP(&options.labelSelector, "selector", "l", options.labelSelector, "Selector (label query) to filter on, supports '=', '==', and '!='")
+	cmd.PersistentFlags().StringVar(&options.fromFile, "from-file", options.fromFile, "Display the traffic recorded in this file with \"linkerd viz tap --record\", instead of live traffic")
 
 	pkgcmd.ConfigureNamespaceFlagCompletion(
 		cmd, []string{"namespace", "to-namespace"},
@@ -439,7 +458,32 @@ func getTrafficByResourceFromAPI(ctx context.Context, k8sAPI *k8s.KubernetesAPI,
 	}
 	defer body.Close()
 
-	err = termbox.Init()
+	return renderTraffic(reader, table)
+}
+
+// getTrafficFromFile renders the traffic recorded in the file at path, with
+// the filters of params applied.
+func getTrafficFromFile(path string, params pkg.TapRequestParams, table *topTable) error {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	recording, err := pkg.ReadRecording(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	match, err := buildReplayMatch(recording, params)
+	if err != nil {
+		return err
+	}
+
+	return renderTraffic(pkg.FilterEvents(recording.Events, match), table)
+}
+
+func renderTraffic(reader *bufio.Reader, table *topTable) error {
+	err := termbox.Init()
 	if err != nil {
 		return err
 	}
@@ -95,6 +95,7 @@ func (hc *HealthChecker) VizCategory(fullCheck bool) *healthcheck.Category {
 
 	checks := []healthcheck.Checker{
 		*healthcheck.NewChecker("linkerd-viz Namespace exists").
+			WithID("l5d-viz-ns-exists").
 			WithHintAnchor("l5d-viz-ns-exists").
 			Fatal().
 			WithCheck(func(ctx context.Context) error {
@@ -124,6 +125,7 @@ func (hc *HealthChecker) VizCategory(fullCheck bool) *healthcheck.Category {
 
 			}),
 		*healthcheck.NewChecker("can initialize the client").
+			WithID("l5d-viz-existence-client").
 			WithHintAnchor("l5d-viz-existence-client").
 			Fatal().
 			WithRetryDeadline(hc.RetryDeadline).
@@ -142,18 +144,21 @@ func (hc *HealthChecker) VizCategory(fullCheck bool) *healthcheck.Category {
 
 	checks = append(checks,
 		*healthcheck.NewChecker("linkerd-viz ClusterRoles exist").
+			WithID("l5d-viz-cr-exists").
 			WithHintAnchor("l5d-viz-cr-exists").
 			Fatal().
 			WithCheck(func(ctx context.Context) error {
 				return healthcheck.CheckClusterRoles(ctx, hc.KubeAPIClient(), true, []string{fmt.Sprintf("linkerd-%s-tap", hc.vizNamespace), fmt.Sprintf("linkerd-%s-metrics-api", hc.vizNamespace), fmt.Sprintf("linkerd-%s-tap-admin", hc.vizNamespace), "linkerd-tap-injector"}, "")
 			}),
 		*healthcheck.NewChecker("linkerd-viz ClusterRoleBindings exist").
+			WithID("l5d-viz-crb-exists").
 			WithHintAnchor("l5d-viz-crb-exists").
 			Fatal().
 			WithCheck(func(ctx context.Context) error {
 				return healthcheck.CheckClusterRoleBindings(ctx, hc.KubeAPIClient(), true, []string{fmt.Sprintf("linkerd-%s-tap", hc.vizNamespace), fmt.Sprintf("linkerd-%s-metrics-api", hc.vizNamespace), fmt.Sprintf("linkerd-%s-tap-auth-delegator", hc.vizNamespace), "linkerd-tap-injector"}, "")
 			}),
 		*healthcheck.NewChecker("tap API server has valid cert").
+			WithID("l5d-tap-cert-valid").
 			WithHintAnchor("l5d-tap-cert-valid").
 			Fatal().
 			WithCheck(func(ctx context.Context) error {
@@ -172,7 +177,8 @@ func (hc *HealthChecker) VizCategory(fullCheck bool) *healthcheck.Category {
 				identityName := fmt.Sprintf("tap.%s.svc", hc.vizNamespace)
 				return hc.CheckCertAndAnchors(cert, anchors, identityName)
 			}),
-		*healthcheck.NewChecker("tap API server cert is valid for at least 60 days").
+		*healthcheck.NewChecker(fmt.Sprintf("tap API server cert is valid for at least %d days", hc.CertExpiryWarningDays())).
+			WithID("l5d-tap-cert-not-expiring-soon").
 			WithHintAnchor("l5d-tap-cert-not-expiring-soon").
 			Warning().
 			WithCheck(func(ctx context.Context) error {
@@ -186,6 +192,7 @@ func (hc *HealthChecker) VizCategory(fullCheck bool) *healthcheck.Category {
 				return hc.CheckCertAndAnchorsExpiringSoon(cert)
 			}),
 		*healthcheck.NewChecker("tap API service is running").
+			WithID("l5d-tap-api").
 			WithHintAnchor("l5d-tap-api").
 			Warning().
 			WithRetryDeadline(hc.RetryDeadline).
@@ -193,6 +200,7 @@ func (hc *HealthChecker) VizCategory(fullCheck bool) *healthcheck.Category {
 				return hc.CheckAPIService(ctx, linkerdTapAPIServiceName)
 			}),
 		*healthcheck.NewChecker("linkerd-viz pods are injected").
+			WithID("l5d-viz-pods-injection").
 			WithHintAnchor("l5d-viz-pods-injection").
 			Warning().
 			WithCheck(func(ctx context.Context) error {
@@ -203,6 +211,7 @@ func (hc *HealthChecker) VizCategory(fullCheck bool) *healthcheck.Category {
 				return healthcheck.CheckIfDataPlanePodsExist(pods)
 			}),
 		*healthcheck.NewChecker("viz extension pods are running").
+			WithID("l5d-viz-pods-running").
 			WithHintAnchor("l5d-viz-pods-running").
 			Warning().
 			WithRetryDeadline(hc.RetryDeadline).
@@ -224,12 +233,14 @@ func (hc *HealthChecker) VizCategory(fullCheck bool) *healthcheck.Category {
 				return healthcheck.CheckPodsRunning(podList.Items, hc.vizNamespace)
 			}),
 		*healthcheck.NewChecker("viz extension proxies are healthy").
+			WithID("l5d-viz-proxy-healthy").
 			WithHintAnchor("l5d-viz-proxy-healthy").
 			Warning().
 			WithCheck(func(ctx context.Context) (err error) {
 				return hc.CheckProxyHealth(ctx, hc.ControlPlaneNamespace, hc.vizNamespace)
 			}),
 		*healthcheck.NewChecker("viz extension proxies are up-to-date").
+			WithID("l5d-viz-proxy-cp-version").
 			WithHintAnchor("l5d-viz-proxy-cp-version").
 			Warning().
 			WithCheck(func(ctx context.Context) error {
@@ -255,6 +266,7 @@ func (hc *HealthChecker) VizCategory(fullCheck bool) *healthcheck.Category {
 				return hc.CheckProxyVersionsUpToDate(pods)
 			}),
 		*healthcheck.NewChecker("viz extension proxies and cli versions match").
+			WithID("l5d-viz-proxy-cli-version").
 			WithHintAnchor("l5d-viz-proxy-cli-version").
 			Warning().
 			WithCheck(func(ctx context.Context) error {
@@ -266,6 +278,7 @@ func (hc *HealthChecker) VizCategory(fullCheck bool) *healthcheck.Category {
 				return healthcheck.CheckIfProxyVersionsMatchWithCLI(pods)
 			}),
 		*healthcheck.NewChecker("prometheus is installed and configured correctly").
+			WithID("l5d-viz-prometheus").
 			WithHintAnchor("l5d-viz-prometheus").
 			Warning().
 			WithCheck(func(ctx context.Context) error {
@@ -302,6 +315,7 @@ func (hc *HealthChecker) VizCategory(fullCheck bool) *healthcheck.Category {
 				return healthcheck.CheckForPods(podList.Items, []string{"prometheus"})
 			}),
 		*healthcheck.NewChecker("viz extension self-check").
+			WithID("l5d-viz-metrics-api").
 			WithHintAnchor("l5d-viz-metrics-api").
 			Fatal().
 			// to avoid confusing users with a prometheus readiness error, we only show
@@ -342,6 +356,7 @@ func (hc *HealthChecker) VizDataPlaneCategory() *healthcheck.Category {
 
 	return healthcheck.NewCategory(LinkerdVizExtensionDataPlaneCheck, []healthcheck.Checker{
 		*healthcheck.NewChecker("data plane namespace exists").
+			WithID("l5d-viz-data-plane-exists").
 			WithHintAnchor("l5d-data-plane-exists").
 			Fatal().
 			WithCheck(func(ctx context.Context) error {
@@ -352,12 +367,14 @@ func (hc *HealthChecker) VizDataPlaneCategory() *healthcheck.Category {
 				return hc.CheckNamespace(ctx, hc.DataPlaneNamespace, true)
 			}),
 		*healthcheck.NewChecker("prometheus is authorized to scrape data plane pods").
+			WithID("l5d-viz-data-plane-prom-authz").
 			WithHintAnchor("l5d-viz-data-plane-prom-authz").
 			Warning().
 			WithCheck(func(ctx context.Context) error {
 				return hc.checkPromAuthorized(ctx)
 			}),
 		*healthcheck.NewChecker("data plane proxy metrics are present in Prometheus").
+			WithID("l5d-data-plane-prom").
 			WithHintAnchor("l5d-data-plane-prom").
 			Warning().
 			WithRetryDeadline(hc.RetryDeadline).
@@ -0,0 +1,247 @@
+package recommender
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	pcv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/proxyconfig/v1alpha1"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	pb "github.com/linkerd/linkerd2/viz/metrics-api/gen/viz"
+	"github.com/linkerd/linkerd2/viz/pkg/prometheus"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// The proxies report their own process metrics, which are scraped by the
+	// linkerd-proxy job along with their workload labels
+	cpuMetric    = "process_cpu_seconds_total"
+	memoryMetric = "process_resident_memory_bytes"
+
+	controlPlaneNsLabel = model.LabelName("control_plane_ns")
+
+	rateWindow = "5m"
+	resolution = "1m"
+
+	mebibyte = 1024 * 1024
+)
+
+// proxyLabels maps the workload kinds whose proxies can be sized to the label
+// the proxy injector sets on their pods
+var proxyLabels = map[string]string{
+	k8s.CronJob:               k8s.ProxyCronJobLabel,
+	k8s.DaemonSet:             k8s.ProxyDaemonSetLabel,
+	k8s.Deployment:            k8s.ProxyDeploymentLabel,
+	k8s.Job:                   k8s.ProxyJobLabel,
+	k8s.ReplicaSet:            k8s.ProxyReplicaSetLabel,
+	k8s.ReplicationController: k8s.ProxyReplicationControllerLabel,
+	k8s.StatefulSet:           k8s.ProxyStatefulSetLabel,
+}
+
+// Options tunes how recommendations are derived from the observed usage.
+type Options struct {
+	// Margin is the fraction added on top of the observed usage, e.g. 0.15
+	// for 15%
+	Margin float64
+	// MinCPU is the lowest CPU request and limit recommended
+	MinCPU resource.Quantity
+	// MinMemory is the lowest memory request and limit recommended
+	MinMemory resource.Quantity
+}
+
+// NewOptions returns the default recommender Options.
+func NewOptions() *Options {
+	return &Options{
+		Margin:    0.15,
+		MinCPU:    resource.MustParse("10m"),
+		MinMemory: resource.MustParse("20Mi"),
+	}
+}
+
+// Workload identifies the workload whose proxies are sized.
+type Workload struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+}
+
+// Usage is the resource usage observed across a workload's proxies. Each
+// value is the highest among the workload's pods.
+type Usage struct {
+	// CPUP95 is the 95th percentile of CPU usage, in cores
+	CPUP95 float64 `json:"cpuP95"`
+	// CPUMax is the maximum CPU usage, in cores
+	CPUMax float64 `json:"cpuMax"`
+	// MemoryP95 is the 95th percentile of resident memory, in bytes
+	MemoryP95 float64 `json:"memoryP95"`
+	// MemoryMax is the maximum resident memory, in bytes
+	MemoryMax float64 `json:"memoryMax"`
+}
+
+// Recommendation holds the proxy resources recommended for a workload.
+type Recommendation struct {
+	Workload
+	Usage         Usage             `json:"usage"`
+	CPURequest    resource.Quantity `json:"cpuRequest"`
+	CPULimit      resource.Quantity `json:"cpuLimit"`
+	MemoryRequest resource.Quantity `json:"memoryRequest"`
+	MemoryLimit   resource.Quantity `json:"memoryLimit"`
+}
+
+// ValidateResource returns an error if the proxies of res can't be sized.
+func ValidateResource(res *pb.Resource) error {
+	if _, ok := proxyLabels[res.GetType()]; !ok {
+		return fmt.Errorf("proxy resources can't be recommended for %s resources", res.GetType())
+	}
+	return nil
+}
+
+// ObservedUsage queries Prometheus for the resource usage of the proxies of
+// the workloads matching res, over the given window.
+func ObservedUsage(ctx context.Context, promAPI promv1.API, res *pb.Resource, controlPlaneNamespace string, window time.Duration) (map[Workload]*Usage, error) {
+	if err := ValidateResource(res); err != nil {
+		return nil, err
+	}
+
+	labels := prometheus.QueryLabels(res)
+	labels[controlPlaneNsLabel] = model.LabelValue(controlPlaneNamespace)
+	groupBy := prometheus.GroupByLabelNames(res)
+	w := model.Duration(window).String()
+
+	queries := []struct {
+		query string
+		set   func(*Usage, float64)
+	}{
+		{
+			fmt.Sprintf("max(quantile_over_time(0.95, rate(%s%s[%s])[%s:%s])) by (%s)", cpuMetric, labels, rateWindow, w, resolution, groupBy),
+			func(u *Usage, v float64) { u.CPUP95 = v },
+		},
+		{
+			fmt.Sprintf("max(max_over_time(rate(%s%s[%s])[%s:%s])) by (%s)", cpuMetric, labels, rateWindow, w, resolution, groupBy),
+			func(u *Usage, v float64) { u.CPUMax = v },
+		},
+		{
+			fmt.Sprintf("max(quantile_over_time(0.95, %s%s[%s])) by (%s)", memoryMetric, labels, w, groupBy),
+			func(u *Usage, v float64) { u.MemoryP95 = v },
+		},
+		{
+			fmt.Sprintf("max(max_over_time(%s%s[%s])) by (%s)", memoryMetric, labels, w, groupBy),
+			func(u *Usage, v float64) { u.MemoryMax = v },
+		},
+	}
+
+	kindLabel := prometheus.ResourceType(res)
+	usage := map[Workload]*Usage{}
+	for _, q := range queries {
+		log.Debug(q.query)
+		val, warn, err := promAPI.Query(ctx, q.query, time.Time{})
+		if warn != nil {
+			log.Warnf("%v", warn)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query Prometheus: %w", err)
+		}
+		vector, ok := val.(model.Vector)
+		if !ok {
+			return nil, fmt.Errorf("unexpected Prometheus result type: %s", val.Type())
+		}
+		for _, sample := range vector {
+			v := float64(sample.Value)
+			if math.IsNaN(v) {
+				continue
+			}
+			w := Workload{
+				Namespace: string(sample.Metric[prometheus.NamespaceLabel]),
+				Kind:      res.GetType(),
+				Name:      string(sample.Metric[kindLabel]),
+			}
+			if w.Name == "" {
+				continue
+			}
+			if usage[w] == nil {
+				usage[w] = &Usage{}
+			}
+			q.set(usage[w], v)
+		}
+	}
+	return usage, nil
+}
+
+// Recommend derives the proxy resources of each workload from its observed
+// usage. Requests cover the 95th percentile of usage and limits cover the
+// maximum, both with the configured margin. The recommendations are sorted by
+// namespace and name.
+func Recommend(usage map[Workload]*Usage, opts *Options) []Recommendation {
+	recommendations := make([]Recommendation, 0, len(usage))
+	for w, u := range usage {
+		cpuRequest := atLeast(cpuQuantity(u.CPUP95*(1+opts.Margin)), opts.MinCPU)
+		memoryRequest := atLeast(memoryQuantity(u.MemoryP95*(1+opts.Margin)), opts.MinMemory)
+		recommendations = append(recommendations, Recommendation{
+			Workload:      w,
+			Usage:         *u,
+			CPURequest:    cpuRequest,
+			CPULimit:      atLeast(cpuQuantity(u.CPUMax*(1+opts.Margin)), cpuRequest),
+			MemoryRequest: memoryRequest,
+			MemoryLimit:   atLeast(memoryQuantity(u.MemoryMax*(1+opts.Margin)), memoryRequest),
+		})
+	}
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].Namespace != recommendations[j].Namespace {
+			return recommendations[i].Namespace < recommendations[j].Namespace
+		}
+		return recommendations[i].Name < recommendations[j].Name
+	})
+	return recommendations
+}
+
+// ProxyConfig returns a ProxyConfig applying the recommendation to the
+// workload's pods. Once applied to the cluster, the proxy injector uses it
+// to size the proxies of new pods.
+func (r *Recommendation) ProxyConfig() *pcv1alpha1.ProxyConfig {
+	cpuRequest, cpuLimit := r.CPURequest.DeepCopy(), r.CPULimit.DeepCopy()
+	memoryRequest, memoryLimit := r.MemoryRequest.DeepCopy(), r.MemoryLimit.DeepCopy()
+	return &pcv1alpha1.ProxyConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: pcv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "ProxyConfig",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s-proxy-resources", r.Kind, r.Name),
+			Namespace: r.Namespace,
+		},
+		Spec: pcv1alpha1.ProxyConfigSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{proxyLabels[r.Kind]: r.Name},
+			},
+			Proxy: pcv1alpha1.ProxySettings{
+				Resources: &pcv1alpha1.Resources{
+					CPU:    &pcv1alpha1.ResourceBounds{Request: &cpuRequest, Limit: &cpuLimit},
+					Memory: &pcv1alpha1.ResourceBounds{Request: &memoryRequest, Limit: &memoryLimit},
+				},
+			},
+		},
+	}
+}
+
+// cpuQuantity rounds cores up to the millicore
+func cpuQuantity(cores float64) resource.Quantity {
+	return *resource.NewMilliQuantity(int64(math.Ceil(cores*1000)), resource.DecimalSI)
+}
+
+// memoryQuantity rounds bytes up to the mebibyte
+func memoryQuantity(bytes float64) resource.Quantity {
+	return *resource.NewQuantity(int64(math.Ceil(bytes/mebibyte))*mebibyte, resource.BinarySI)
+}
+
+func atLeast(q, min resource.Quantity) resource.Quantity {
+	if q.Cmp(min) < 0 {
+		return min.DeepCopy()
+	}
+	return q
+}
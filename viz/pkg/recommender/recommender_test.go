@@ -0,0 +1,99 @@
+package recommender
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/prometheus"
+	pb "github.com/linkerd/linkerd2/viz/metrics-api/gen/viz"
+	"github.com/prometheus/common/model"
+)
+
+func TestObservedUsage(t *testing.T) {
+	promAPI := &prometheus.MockProm{
+		Res: model.Vector{
+			&model.Sample{
+				Metric: model.Metric{"namespace": "emojivoto", "deployment": "web"},
+				Value:  0.02,
+			},
+		},
+	}
+	res := &pb.Resource{Namespace: "emojivoto", Type: k8s.Deployment}
+
+	usage, err := ObservedUsage(context.Background(), promAPI, res, "linkerd", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expectedQueries := []string{
+		`max(quantile_over_time(0.95, rate(process_cpu_seconds_total{control_plane_ns="linkerd", namespace="emojivoto"}[5m])[1d:1m])) by (namespace, deployment)`,
+		`max(max_over_time(rate(process_cpu_seconds_total{control_plane_ns="linkerd", namespace="emojivoto"}[5m])[1d:1m])) by (namespace, deployment)`,
+		`max(quantile_over_time(0.95, process_resident_memory_bytes{control_plane_ns="linkerd", namespace="emojivoto"}[1d])) by (namespace, deployment)`,
+		`max(max_over_time(process_resident_memory_bytes{control_plane_ns="linkerd", namespace="emojivoto"}[1d])) by (namespace, deployment)`,
+	}
+	if diff := deep.Equal(promAPI.QueriesExecuted, expectedQueries); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+
+	expectedUsage := map[Workload]*Usage{
+		{Namespace: "emojivoto", Kind: k8s.Deployment, Name: "web"}: {CPUP95: 0.02, CPUMax: 0.02, MemoryP95: 0.02, MemoryMax: 0.02},
+	}
+	if diff := deep.Equal(usage, expectedUsage); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+
+	if _, err := ObservedUsage(context.Background(), promAPI, &pb.Resource{Type: k8s.Namespace, Name: "emojivoto"}, "linkerd", time.Hour); err == nil {
+		t.Error("Expected namespaces to be rejected")
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	usage := map[Workload]*Usage{
+		{Namespace: "emojivoto", Kind: k8s.Deployment, Name: "web"}: {
+			CPUP95:    0.1,
+			CPUMax:    0.3,
+			MemoryP95: 40 * mebibyte,
+			MemoryMax: 50*mebibyte + 1,
+		},
+		{Namespace: "emojivoto", Kind: k8s.Deployment, Name: "emoji"}: {
+			CPUP95:    0.001,
+			CPUMax:    0.002,
+			MemoryP95: 5 * mebibyte,
+			MemoryMax: 6 * mebibyte,
+		},
+	}
+
+	recommendations := Recommend(usage, NewOptions())
+
+	expected := []struct {
+		name                                             string
+		cpuRequest, cpuLimit, memoryRequest, memoryLimit string
+	}{
+		{"emoji", "10m", "10m", "20Mi", "20Mi"},
+		{"web", "115m", "345m", "46Mi", "58Mi"},
+	}
+	if len(recommendations) != len(expected) {
+		t.Fatalf("Expected %d recommendations, got %d", len(expected), len(recommendations))
+	}
+	for i, exp := range expected {
+		r := recommendations[i]
+		actual := []string{r.Name, r.CPURequest.String(), r.CPULimit.String(), r.MemoryRequest.String(), r.MemoryLimit.String()}
+		if diff := deep.Equal(actual, []string{exp.name, exp.cpuRequest, exp.cpuLimit, exp.memoryRequest, exp.memoryLimit}); diff != nil {
+			t.Errorf("%+v", diff)
+		}
+	}
+
+	pc := recommendations[1].ProxyConfig()
+	if pc.Name != "deployment-web-proxy-resources" || pc.Namespace != "emojivoto" {
+		t.Errorf("Unexpected ProxyConfig name %s/%s", pc.Namespace, pc.Name)
+	}
+	if diff := deep.Equal(pc.Spec.Selector.MatchLabels, map[string]string{k8s.ProxyDeploymentLabel: "web"}); diff != nil {
+		t.Errorf("%+v", diff)
+	}
+	if pc.Spec.Proxy.Resources.Memory.Limit.String() != "58Mi" {
+		t.Errorf("Unexpected memory limit %s", pc.Spec.Proxy.Resources.Memory.Limit)
+	}
+}
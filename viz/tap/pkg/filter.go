@@ -0,0 +1,153 @@
+package pkg
+
+import (
+	"strings"
+
+	"github.com/linkerd/linkerd2/pkg/addr"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	metricsPb "github.com/linkerd/linkerd2/viz/metrics-api/gen/viz"
+	"github.com/linkerd/linkerd2/viz/pkg/util"
+	tapPb "github.com/linkerd/linkerd2/viz/tap/gen/tap"
+)
+
+// EventFilter matches TapEvents offline, the way the proxies match the
+// requests to tap. HTTP requests are matched on their RequestInit event, and
+// their ResponseInit and ResponseEnd events follow that match.
+type EventFilter struct {
+	match   *tapPb.TapByResourceRequest_Match
+	streams map[eventStreamID]bool
+}
+
+type eventStreamID struct {
+	src, dst string
+	base     uint32
+	stream   uint64
+}
+
+// NewEventFilter returns an EventFilter matching the events matched by match.
+// A nil match matches all the events.
+func NewEventFilter(match *tapPb.TapByResourceRequest_Match) *EventFilter {
+	return &EventFilter{
+		match:   match,
+		streams: map[eventStreamID]bool{},
+	}
+}
+
+// Matches returns whether event is matched by the filter.
+func (f *EventFilter) Matches(event *tapPb.TapEvent) bool {
+	http := event.GetHttp()
+	var streamID *tapPb.TapEvent_Http_StreamId
+	switch ev := http.GetEvent().(type) {
+	case *tapPb.TapEvent_Http_RequestInit_:
+		matches := matchEvent(f.match, event)
+		f.streams[newEventStreamID(event, ev.RequestInit.GetId())] = matches
+		return matches
+	case *tapPb.TapEvent_Http_ResponseInit_:
+		streamID = ev.ResponseInit.GetId()
+	case *tapPb.TapEvent_Http_ResponseEnd_:
+		streamID = ev.ResponseEnd.GetId()
+	default:
+		return matchEvent(f.match, event)
+	}
+
+	id := newEventStreamID(event, streamID)
+	matches, ok := f.streams[id]
+	if !ok {
+		// The request started before the events being filtered
+		return matchEvent(f.match, event)
+	}
+	if http.GetResponseEnd() != nil {
+		delete(f.streams, id)
+	}
+	return matches
+}
+
+func newEventStreamID(event *tapPb.TapEvent, id *tapPb.TapEvent_Http_StreamId) eventStreamID {
+	return eventStreamID{
+		src:    addr.PublicAddressToString(event.GetSource()),
+		dst:    addr.PublicAddressToString(event.GetDestination()),
+		base:   id.GetBase(),
+		stream: id.GetStream(),
+	}
+}
+
+// matchEvent returns whether match matches event. The HTTP matches only match
+// RequestInit events.
+func matchEvent(match *tapPb.TapByResourceRequest_Match, event *tapPb.TapEvent) bool {
+	if match == nil || match.GetMatch() == nil {
+		return true
+	}
+
+	switch typed := match.GetMatch().(type) {
+	case *tapPb.TapByResourceRequest_Match_All:
+		for _, m := range typed.All.GetMatches() {
+			if !matchEvent(m, event) {
+				return false
+			}
+		}
+		return true
+	case *tapPb.TapByResourceRequest_Match_Any:
+		for _, m := range typed.Any.GetMatches() {
+			if matchEvent(m, event) {
+				return true
+			}
+		}
+		return false
+	case *tapPb.TapByResourceRequest_Match_Not:
+		return !matchEvent(typed.Not, event)
+	case *tapPb.TapByResourceRequest_Match_Destinations:
+		labels := event.GetDestinationMeta().GetLabels()
+		for k, v := range destinationLabels(typed.Destinations.GetResource()) {
+			if labels[k] != v {
+				return false
+			}
+		}
+		return true
+	case *tapPb.TapByResourceRequest_Match_Http_:
+		return matchHTTP(typed.Http, event.GetHttp().GetRequestInit())
+	default:
+		return false
+	}
+}
+
+func matchHTTP(match *tapPb.TapByResourceRequest_Match_Http, req *tapPb.TapEvent_Http_RequestInit) bool {
+	if req == nil {
+		return false
+	}
+	switch typed := match.GetMatch().(type) {
+	case *tapPb.TapByResourceRequest_Match_Http_Scheme:
+		return strings.EqualFold(schemeToString(req.GetScheme()), typed.Scheme)
+	case *tapPb.TapByResourceRequest_Match_Http_Method:
+		return strings.EqualFold(util.HTTPMethodToString(req.GetMethod()), typed.Method)
+	case *tapPb.TapByResourceRequest_Match_Http_Authority:
+		return req.GetAuthority() == typed.Authority
+	case *tapPb.TapByResourceRequest_Match_Http_Path:
+		return strings.HasPrefix(req.GetPath(), typed.Path)
+	default:
+		return false
+	}
+}
+
+// destinationLabels returns the labels of the destinations of resource, like
+// the tap APIService does when matching them.
+func destinationLabels(resource *metricsPb.Resource) map[string]string {
+	dstLabels := map[string]string{}
+	if resource.GetName() != "" {
+		dstLabels[k8s.KindToL5DLabel(resource.GetType())] = resource.GetName()
+	}
+	if resource.GetType() != k8s.Namespace && resource.GetNamespace() != "" {
+		dstLabels["namespace"] = resource.GetNamespace()
+	}
+	return dstLabels
+}
+
+func schemeToString(scheme *metricsPb.Scheme) string {
+	switch typed := scheme.GetType().(type) {
+	case *metricsPb.Scheme_Registered_:
+		return typed.Registered.String()
+	case *metricsPb.Scheme_Unregistered:
+		return typed.Unregistered
+	default:
+		return ""
+	}
+}
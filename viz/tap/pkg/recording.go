@@ -0,0 +1,135 @@
+package pkg
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/protohttp"
+	tapPb "github.com/linkerd/linkerd2/viz/tap/gen/tap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// recordingMagic starts every tap recording
+	recordingMagic = "linkerd-tap\n"
+
+	// recordingVersion is the version of the format of the recordings
+	recordingVersion = 1
+)
+
+// Recording is a tap recording, made of a header line in JSON followed by the
+// raw stream of TapEvents, as sent by the tap APIService. Each TapEvent is
+// prefixed by its length, like in the HTTP responses of the APIService.
+type Recording struct {
+	// StartTime is when the recording started
+	StartTime time.Time
+	// Request is the tap request whose events were recorded, with the
+	// tapped resource and its match
+	Request *tapPb.TapByResourceRequest
+	// Events is the stream of recorded TapEvents
+	Events *bufio.Reader
+}
+
+// recordingHeader is the header of a recording, stored in JSON
+type recordingHeader struct {
+	Version   int             `json:"version"`
+	StartTime time.Time       `json:"startTime"`
+	Request   json.RawMessage `json:"request"`
+}
+
+// Record writes the header of a recording of the events of req into w, and
+// returns a reader of events which copies the events it reads into w.
+func Record(w io.Writer, req *tapPb.TapByResourceRequest, startTime time.Time, events *bufio.Reader) (*bufio.Reader, error) {
+	request, err := protojson.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	header, err := json.Marshal(recordingHeader{
+		Version:   recordingVersion,
+		StartTime: startTime.UTC(),
+		Request:   request,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.WriteString(w, recordingMagic); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(header, '\n')); err != nil {
+		return nil, err
+	}
+	return bufio.NewReader(io.TeeReader(events, w)), nil
+}
+
+// ReadRecording reads the header of the recording from r, and returns the
+// recording, whose Events are read from r.
+func ReadRecording(r io.Reader) (*Recording, error) {
+	reader := bufio.NewReader(r)
+	magic := make([]byte, len(recordingMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || string(magic) != recordingMagic {
+		return nil, errors.New("not a tap recording")
+	}
+
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("invalid tap recording header: %w", err)
+	}
+	var header recordingHeader
+	if err := json.Unmarshal(line, &header); err != nil {
+		return nil, fmt.Errorf("invalid tap recording header: %w", err)
+	}
+	if header.Version != recordingVersion {
+		return nil, fmt.Errorf("unsupported tap recording version: %d", header.Version)
+	}
+	req := &tapPb.TapByResourceRequest{}
+	if err := protojson.Unmarshal(header.Request, req); err != nil {
+		return nil, fmt.Errorf("invalid tap recording request: %w", err)
+	}
+
+	return &Recording{
+		StartTime: header.StartTime,
+		Request:   req,
+		Events:    reader,
+	}, nil
+}
+
+// FilterEvents returns a stream of the events of the events stream matched by
+// match, in the same format. The events of a request are matched along with
+// its RequestInit event. The stream ends with the events stream, including
+// when its last event is truncated, like in an interrupted recording.
+func FilterEvents(events *bufio.Reader, match *tapPb.TapByResourceRequest_Match) *bufio.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		filter := NewEventFilter(match)
+		for {
+			event := &tapPb.TapEvent{}
+			err := protohttp.FromByteStreamToProtocolBuffers(events, event)
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				pw.Close()
+				return
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if !filter.Matches(event) {
+				continue
+			}
+			data, err := proto.Marshal(event)
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := pw.Write(protohttp.SerializeAsPayload(data)); err != nil {
+				return
+			}
+		}
+	}()
+	return bufio.NewReader(pr)
+}
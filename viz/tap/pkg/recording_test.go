@@ -0,0 +1,184 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/protohttp"
+	metricsPb "github.com/linkerd/linkerd2/viz/metrics-api/gen/viz"
+	tapPb "github.com/linkerd/linkerd2/viz/tap/gen/tap"
+	"google.golang.org/protobuf/proto"
+)
+
+func requestInit(stream uint64, method metricsPb.HttpMethod_Registered, path string) *tapPb.TapEvent {
+	return CreateTapEvent(&tapPb.TapEvent_Http{
+		Event: &tapPb.TapEvent_Http_RequestInit_{
+			RequestInit: &tapPb.TapEvent_Http_RequestInit{
+				Id: &tapPb.TapEvent_Http_StreamId{Base: 1, Stream: stream},
+				Method: &metricsPb.HttpMethod{
+					Type: &metricsPb.HttpMethod_Registered_{Registered: method},
+				},
+				Scheme: &metricsPb.Scheme{
+					Type: &metricsPb.Scheme_Registered_{Registered: metricsPb.Scheme_HTTP},
+				},
+				Authority: "web.emojivoto.svc.cluster.local",
+				Path:      path,
+			},
+		},
+	}, map[string]string{"namespace": "emojivoto", "deployment": "web"}, tapPb.TapEvent_OUTBOUND)
+}
+
+func responseEnd(stream uint64) *tapPb.TapEvent {
+	return CreateTapEvent(&tapPb.TapEvent_Http{
+		Event: &tapPb.TapEvent_Http_ResponseEnd_{
+			ResponseEnd: &tapPb.TapEvent_Http_ResponseEnd{
+				Id: &tapPb.TapEvent_Http_StreamId{Base: 1, Stream: stream},
+			},
+		},
+	}, map[string]string{"namespace": "emojivoto", "deployment": "web"}, tapPb.TapEvent_OUTBOUND)
+}
+
+func eventStream(t *testing.T, events ...*tapPb.TapEvent) []byte {
+	t.Helper()
+	var stream []byte
+	for _, event := range events {
+		data, err := proto.Marshal(event)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		stream = append(stream, protohttp.SerializeAsPayload(data)...)
+	}
+	return stream
+}
+
+func readEvents(t *testing.T, reader *bufio.Reader) []*tapPb.TapEvent {
+	t.Helper()
+	var events []*tapPb.TapEvent
+	for {
+		event := &tapPb.TapEvent{}
+		err := protohttp.FromByteStreamToProtocolBuffers(reader, event)
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		events = append(events, event)
+	}
+}
+
+func TestRecording(t *testing.T) {
+	req, err := BuildTapByResourceRequest(TapRequestParams{
+		Resource:  "deploy/web",
+		Namespace: "emojivoto",
+		Path:      "/api",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	startTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	events := []*tapPb.TapEvent{requestInit(1, metricsPb.HttpMethod_GET, "/api/list"), responseEnd(1)}
+	stream := eventStream(t, events...)
+
+	var file bytes.Buffer
+	reader, err := Record(&file, req, startTime, bufio.NewReader(bytes.NewReader(stream)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if read := readEvents(t, reader); len(read) != len(events) {
+		t.Fatalf("Expected %d events, got %d", len(events), len(read))
+	}
+
+	// A recording interrupted in the middle of an event is replayed up to
+	// that event
+	truncated := file.Bytes()[:file.Len()-1]
+	recording, err := ReadRecording(bytes.NewReader(truncated))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !recording.StartTime.Equal(startTime) {
+		t.Errorf("Expected start time %s, got %s", startTime, recording.StartTime)
+	}
+	if !proto.Equal(recording.Request, req) {
+		t.Errorf("Expected request %v, got %v", req, recording.Request)
+	}
+	replayed := readEvents(t, FilterEvents(recording.Events, nil))
+	if len(replayed) != 1 || !proto.Equal(replayed[0], events[0]) {
+		t.Errorf("Expected the first event to be replayed, got %v", replayed)
+	}
+
+	_, err = ReadRecording(strings.NewReader("not a recording"))
+	if err == nil || err.Error() != "not a tap recording" {
+		t.Errorf("Expected an invalid recording error, got %v", err)
+	}
+}
+
+func TestEventFilter(t *testing.T) {
+	events := []*tapPb.TapEvent{
+		requestInit(1, metricsPb.HttpMethod_GET, "/api/list"),
+		requestInit(2, metricsPb.HttpMethod_POST, "/api/vote"),
+		requestInit(3, metricsPb.HttpMethod_GET, "/index.html"),
+		responseEnd(2),
+		responseEnd(1),
+		responseEnd(3),
+		// A response to a request from before the recording started
+		responseEnd(4),
+	}
+
+	testCases := []struct {
+		description string
+		params      TapRequestParams
+		expected    []int
+	}{
+		{
+			description: "no filter",
+			expected:    []int{0, 1, 2, 3, 4, 5, 6},
+		},
+		{
+			description: "method and path",
+			params:      TapRequestParams{Method: "get", Path: "/api"},
+			expected:    []int{0, 4},
+		},
+		{
+			description: "matching destination",
+			params:      TapRequestParams{ToResource: "deploy/web", ToNamespace: "emojivoto", Path: "/api/vote"},
+			expected:    []int{1, 3},
+		},
+		{
+			description: "other destination",
+			params:      TapRequestParams{ToResource: "deploy/voting", ToNamespace: "emojivoto"},
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.description, func(t *testing.T) {
+			tc.params.Resource = "deploy/web"
+			req, err := BuildTapByResourceRequest(tc.params)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			filter := NewEventFilter(req.GetMatch())
+			var matched []int
+			for i, event := range events {
+				if filter.Matches(event) {
+					matched = append(matched, i)
+				}
+			}
+			if len(matched) != len(tc.expected) {
+				t.Fatalf("Expected events %v to match, got %v", tc.expected, matched)
+			}
+			for i := range matched {
+				if matched[i] != tc.expected[i] {
+					t.Fatalf("Expected events %v to match, got %v", tc.expected, matched)
+				}
+			}
+		})
+	}
+}